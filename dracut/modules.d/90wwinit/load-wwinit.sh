@@ -3,17 +3,26 @@
 get_stage() {
     stage="${1}"
     info "warewulf: loading stage: ${stage}"
-    # Load runtime overlay from a static privledged port.
+    # Load the system and runtime overlays from a static privledged port.
     # Others use default settings.
+    # The runtime overlay also requires the node token from the system overlay.
     localport=""
-    if [ "${stage}" = "runtime" ]; then
+    token_header=()
+    if [ "${stage}" = "system" ]; then
         localport="--local-port 1-1023"
+    elif [ "${stage}" = "runtime" ]; then
+        localport="--local-port 1-1023"
+        if [ -f "${NEWROOT}/warewulf/token" ]; then
+            token_header=(--header "Authorization: Bearer $(cat "${NEWROOT}/warewulf/token")")
+        fi
     fi
     (
-        curl --location --silent --get ${localport} \
+        curl --location --silent --get ${localport} "${token_header[@]}" \
             --retry 60 --retry-connrefused --retry-delay 1 \
             --data-urlencode "assetkey=${wwinit_assetkey}" \
             --data-urlencode "uuid=${wwinit_uuid}" \
+            --data-urlencode "serial=${wwinit_serial}" \
+            --data-urlencode "switchport=${wwinit_switchport}" \
             --data-urlencode "stage=${stage}" \
             --data-urlencode "compress=gz" \
             "${wwinit_uri}" \
@@ -22,6 +31,29 @@ get_stage() {
     ) || die "Unable to load stage: ${stage}"
 }
 
+get_switchport() {
+    # Report the switch port from LLDP, for discovery rules, if
+    # wwinit.lldp sets how many seconds to wait for a neighbor and the
+    # initramfs includes lldpd.
+    if [ -z "${wwinit_lldp}" ] || ! command -v lldpd >/dev/null || ! command -v lldpcli >/dev/null; then
+        return
+    fi
+    info "warewulf: waiting ${wwinit_lldp}s for an LLDP neighbor"
+    lldpd || return
+    sleep "${wwinit_lldp}"
+    local neighbors switch port
+    neighbors="$(lldpcli -f keyvalue show neighbors)"
+    switch="$(echo "${neighbors}" | sed -n 's/^lldp\.[^.]*\.chassis\.name=//p' | head -n 1)"
+    port="$(echo "${neighbors}" | sed -n 's/^lldp\.[^.]*\.port\.ifname=//p' | head -n 1)"
+    [ -f /run/lldpd.pid ] && kill "$(cat /run/lldpd.pid)"
+    if [ -n "${switch}" ] && [ -n "${port}" ]; then
+        export wwinit_switchport="${switch}:${port}"
+        info "warewulf: switch port: ${wwinit_switchport}"
+    fi
+}
+
+get_switchport
+
 mkdir /tmp/wwinit
 (
     # fetch the system overlay into /tmp/wwinit
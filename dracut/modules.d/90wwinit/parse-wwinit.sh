@@ -17,6 +17,8 @@ wwinit|wwinit:*)
 
     export wwinit_uuid=$(dmidecode -s system-uuid)
     export wwinit_assetkey=$(dmidecode -s chassis-asset-tag)
+    export wwinit_serial=$(dmidecode -s system-serial-number)
+    export wwinit_lldp="$(getarg wwinit.lldp)"
 
     wwinit_tmpfs_size="$(getarg wwinit.tmpfs.size)"
     if [ -n "$wwinit_tmpfs_size" ]; then
@@ -1,6 +1,10 @@
 package wwclient
 
 import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
@@ -19,6 +23,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/talos-systems/go-smbios/smbios"
 	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/inventory"
 	"github.com/warewulf/warewulf/internal/pkg/pidfile"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
@@ -38,6 +43,10 @@ var (
 	WarewulfConfArg string
 )
 
+// TLSCAFile is where the wwinit overlay places the CA certificate
+// configured as "tls ca" in warewulf.conf.
+const TLSCAFile = "/etc/warewulf/tls-ca.pem"
+
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&DebugFlag, "debug", "d", false, "Run with debugging messages enabled.")
 	rootCmd.PersistentFlags().StringVarP(&PIDFile, "pidfile", "p", "/var/run/wwclient.pid", "PIDFile to use")
@@ -103,20 +112,27 @@ func CobraRunE(cmd *cobra.Command, args []string) (err error) {
 		wwlog.Info("Running from trusted port: %d", localTCPAddr.Port)
 	}
 
-	Webclient = &http.Client{
-		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			DialContext: (&net.Dialer{
-				LocalAddr: &localTCPAddr,
-				Timeout:   30 * time.Second,
-				KeepAlive: 30 * time.Second,
-			}).DialContext,
-			MaxIdleConns:          100,
-			IdleConnTimeout:       2 * time.Duration(conf.Warewulf.UpdateInterval) * time.Second,
-			TLSHandshakeTimeout:   10 * time.Second,
-			ExpectContinueTimeout: 1 * time.Second,
-		},
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			LocalAddr: &localTCPAddr,
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       2 * time.Duration(conf.Warewulf.UpdateInterval) * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	scheme, port := "http", conf.Warewulf.Port
+	if conf.Warewulf.TLS() {
+		transport.TLSClientConfig, err = tlsConfig(conf.Warewulf.TLSCA != "")
+		if err != nil {
+			return err
+		}
+		scheme, port = "https", conf.Warewulf.TLSPort
 	}
+	Webclient = &http.Client{Transport: transport}
 	var localUUID uuid.UUID
 	var tag string
 	smbiosDump, smbiosErr := smbios.New()
@@ -197,8 +213,15 @@ func CobraRunE(cmd *cobra.Command, args []string) (err error) {
 	if ipaddr == "" {
 		ipaddr = conf.Ipaddr
 	}
+	var sentInventory *inventory.Inventory
 	for {
-		updateSystem(ipaddr, conf.Warewulf.Port, wwid, tag, localUUID)
+		updateSystem(scheme, ipaddr, port, wwid, tag, localUUID, path.Join(conf.Paths.WWClientdir, "token"))
+		// the inventory is only sent again when the hardware changes
+		if inv := inventory.CollectLocal(); sentInventory == nil || len(inventory.Diff(sentInventory, inv)) > 0 {
+			if sendInventory(scheme, ipaddr, port, wwid, tag, localUUID, path.Join(conf.Paths.WWClientdir, "token"), inv) {
+				sentInventory = inv
+			}
+		}
 		if !finishedInitialSync {
 			// ignore error and status here, as this wouldn't change anything
 			_, _ = daemon.SdNotify(false, daemon.SdNotifyReady)
@@ -210,7 +233,26 @@ func CobraRunE(cmd *cobra.Command, args []string) (err error) {
 	}
 }
 
-func updateSystem(ipaddr string, port int, wwid string, tag string, localUUID uuid.UUID) {
+// tlsConfig returns the TLS configuration used to verify warewulfd. If
+// useCA is set, the server certificate must be signed by the CA in
+// TLSCAFile; otherwise the system roots are used.
+func tlsConfig(useCA bool) (*tls.Config, error) {
+	conf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if !useCA {
+		return conf, nil
+	}
+	caPEM, err := os.ReadFile(TLSCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read CA certificate: %w", err)
+	}
+	conf.RootCAs = x509.NewCertPool()
+	if !conf.RootCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no CA certificates found in %s", TLSCAFile)
+	}
+	return conf, nil
+}
+
+func updateSystem(scheme string, ipaddr string, port int, wwid string, tag string, localUUID uuid.UUID, tokenFile string) {
 	var resp *http.Response
 	counter := 0
 	for {
@@ -221,13 +263,20 @@ func updateSystem(ipaddr string, port int, wwid string, tag string, localUUID uu
 		values.Set("stage", "runtime")
 		values.Set("compress", "gz")
 		getURL := &url.URL{
-			Scheme:   "http",
+			Scheme:   scheme,
 			Host:     fmt.Sprintf("%s:%d", ipaddr, port),
 			Path:     fmt.Sprintf("provision/%s", wwid),
 			RawQuery: values.Encode(),
 		}
 		wwlog.Debug("making request: %s", getURL)
-		resp, err = Webclient.Get(getURL.String())
+		var req *http.Request
+		req, err = http.NewRequest(http.MethodGet, getURL.String(), nil)
+		if err != nil {
+			wwlog.Error("%s", err)
+			return
+		}
+		setToken(req, tokenFile)
+		resp, err = Webclient.Do(req)
 		if err == nil {
 			break
 		} else {
@@ -255,6 +304,55 @@ func updateSystem(ipaddr string, port int, wwid string, tag string, localUUID uu
 	}
 }
 
+// setToken authorizes req with the node token, which is read on every
+// request, as the system overlay may have been updated with a new one.
+func setToken(req *http.Request, tokenFile string) {
+	if token, err := os.ReadFile(tokenFile); err == nil {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	} else if !errors.Is(err, os.ErrNotExist) {
+		wwlog.Warn("could not read node token: %s", err)
+	}
+}
+
+// sendInventory posts the hardware inventory of the node to warewulfd
+// and returns whether it was accepted.
+func sendInventory(scheme string, ipaddr string, port int, wwid string, tag string, localUUID uuid.UUID, tokenFile string, inv *inventory.Inventory) bool {
+	body, err := json.Marshal(inv)
+	if err != nil {
+		wwlog.Error("could not encode inventory: %s", err)
+		return false
+	}
+	values := &url.Values{}
+	values.Set("assetkey", tag)
+	values.Set("uuid", localUUID.String())
+	postURL := &url.URL{
+		Scheme:   scheme,
+		Host:     fmt.Sprintf("%s:%d", ipaddr, port),
+		Path:     fmt.Sprintf("inventory/%s", wwid),
+		RawQuery: values.Encode(),
+	}
+	wwlog.Debug("sending inventory: %s", postURL)
+	req, err := http.NewRequest(http.MethodPost, postURL.String(), bytes.NewReader(body))
+	if err != nil {
+		wwlog.Error("%s", err)
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setToken(req, tokenFile)
+	resp, err := Webclient.Do(req)
+	if err != nil {
+		wwlog.Warn("could not send inventory: %s", err)
+		return false
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		wwlog.Warn("inventory not accepted: got status code: %d", resp.StatusCode)
+		return false
+	}
+	wwlog.Info("sent hardware inventory")
+	return true
+}
+
 func cleanUp() {
 	err := pidfile.Remove(PIDFile)
 	if err != nil {
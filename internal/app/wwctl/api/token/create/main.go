@@ -0,0 +1,27 @@
+package create
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/config"
+)
+
+func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		tokenFile := config.Get().Paths.APITokensConf()
+		tokens, err := config.ReadAPITokens(tokenFile)
+		if err != nil {
+			return fmt.Errorf("could not read API tokens: %w", err)
+		}
+		token, err := tokens.Create(args[0], config.Role(vars.role))
+		if err != nil {
+			return err
+		}
+		if err := tokens.Write(tokenFile); err != nil {
+			return fmt.Errorf("could not write API tokens: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), token)
+		return nil
+	}
+}
@@ -0,0 +1,38 @@
+package create
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Create(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	cmd := GetCommand()
+	cmd.SetArgs([]string{"--role", "operator", "ci"})
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	assert.NoError(t, cmd.Execute())
+
+	tokens, err := config.ReadAPITokens(config.Get().Paths.APITokensConf())
+	assert.NoError(t, err)
+	token, err := tokens.Authenticate(strings.TrimSpace(stdout.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, "ci", token.Name)
+	assert.Equal(t, config.RoleOperator, token.Role)
+	assert.NotContains(t, env.ReadFile("etc/warewulf/api-tokens.conf"), strings.TrimSpace(stdout.String()))
+
+	cmd = GetCommand()
+	cmd.SetArgs([]string{"ci"})
+	assert.Error(t, cmd.Execute(), "duplicate token names are rejected")
+
+	cmd = GetCommand()
+	cmd.SetArgs([]string{"--role", "root", "other"})
+	assert.Error(t, cmd.Execute(), "unknown roles are rejected")
+}
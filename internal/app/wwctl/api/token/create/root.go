@@ -0,0 +1,33 @@
+package create
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/pkg/config"
+)
+
+type variables struct {
+	role string
+}
+
+func GetCommand() *cobra.Command {
+	vars := variables{}
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "create [OPTIONS] NAME",
+		Short:                 "Create a REST API token",
+		Long: "This command creates a REST API token with the given NAME and prints it.\n" +
+			"The token can't be shown again.",
+		Example:           "  wwctl api token create --role=read-only monitoring",
+		RunE:              CobraRunE(&vars),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completions.None,
+	}
+	baseCmd.PersistentFlags().StringVar(&vars.role, "role", string(config.RoleReadOnly), "role of the token: read-only, operator, or admin")
+	if err := baseCmd.RegisterFlagCompletionFunc("role", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{string(config.RoleReadOnly), string(config.RoleOperator), string(config.RoleAdmin)}, cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		panic(err)
+	}
+	return baseCmd
+}
@@ -0,0 +1,24 @@
+package list
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/config"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	tokens, err := config.ReadAPITokens(config.Get().Paths.APITokensConf())
+	if err != nil {
+		return fmt.Errorf("could not read API tokens: %w", err)
+	}
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("NAME", "ROLE", "CREATED")
+	for _, token := range tokens.Tokens {
+		t.AddLine(token.Name, token.Role, token.Created.Local().Format(time.DateTime))
+	}
+	t.Print()
+	return nil
+}
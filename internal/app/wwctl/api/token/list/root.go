@@ -0,0 +1,20 @@
+package list
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+func GetCommand() *cobra.Command {
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "list",
+		Short:                 "List REST API tokens",
+		Long:                  "This command lists the names and roles of the REST API tokens.",
+		Aliases:               []string{"ls"},
+		RunE:                  CobraRunE,
+		Args:                  cobra.NoArgs,
+		ValidArgsFunction:     completions.None,
+	}
+	return baseCmd
+}
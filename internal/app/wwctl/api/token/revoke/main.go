@@ -0,0 +1,25 @@
+package revoke
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/config"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	tokenFile := config.Get().Paths.APITokensConf()
+	tokens, err := config.ReadAPITokens(tokenFile)
+	if err != nil {
+		return fmt.Errorf("could not read API tokens: %w", err)
+	}
+	for _, name := range args {
+		if err := tokens.Revoke(name); err != nil {
+			return err
+		}
+	}
+	if err := tokens.Write(tokenFile); err != nil {
+		return fmt.Errorf("could not write API tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package revoke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Revoke(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	tokenFile := config.Get().Paths.APITokensConf()
+	tokens, err := config.ReadAPITokens(tokenFile)
+	assert.NoError(t, err)
+	token, err := tokens.Create("ci", config.RoleOperator)
+	assert.NoError(t, err)
+	assert.NoError(t, tokens.Write(tokenFile))
+
+	cmd := GetCommand()
+	cmd.SetArgs([]string{"ci"})
+	assert.NoError(t, cmd.Execute())
+
+	tokens, err = config.ReadAPITokens(tokenFile)
+	assert.NoError(t, err)
+	_, err = tokens.Authenticate(token)
+	assert.Error(t, err)
+
+	cmd = GetCommand()
+	cmd.SetArgs([]string{"ci"})
+	assert.Error(t, cmd.Execute())
+}
@@ -0,0 +1,20 @@
+package revoke
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+func GetCommand() *cobra.Command {
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "revoke NAME ...",
+		Short:                 "Revoke REST API tokens",
+		Long:                  "This command revokes the REST API tokens with the given NAMEs.",
+		Aliases:               []string{"delete", "rm"},
+		RunE:                  CobraRunE,
+		Args:                  cobra.MinimumNArgs(1),
+		ValidArgsFunction:     completions.None,
+	}
+	return baseCmd
+}
@@ -0,0 +1,99 @@
+package list
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/hostlist"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+)
+
+func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err error) {
+	return func(cmd *cobra.Command, args []string) (err error) {
+		var since time.Time
+		if vars.since != "" {
+			if since, err = parseSince(vars.since); err != nil {
+				return err
+			}
+		}
+		entries, err := node.ReadAuditLog()
+		if err != nil {
+			return fmt.Errorf("could not read audit log: %w", err)
+		}
+		entities := hostlist.Expand(args)
+
+		var matched []node.AuditEntry
+		for _, entry := range entries {
+			if vars.actor != "" && entry.Actor != vars.actor {
+				continue
+			}
+			if vars.source != "" && string(entry.Source) != vars.source {
+				continue
+			}
+			if vars.kind != "" && entry.Kind != vars.kind {
+				continue
+			}
+			if !since.IsZero() && entry.Time.Before(since) {
+				continue
+			}
+			if len(entities) > 0 && !util.InSlice(entities, entry.Entity) {
+				continue
+			}
+			if vars.field != "" {
+				var changes []node.AuditChange
+				for _, change := range entry.Changes {
+					if change.Field == vars.field || strings.HasPrefix(change.Field, vars.field+".") {
+						changes = append(changes, change)
+					}
+				}
+				if len(changes) == 0 {
+					continue
+				}
+				entry.Changes = changes
+			}
+			matched = append(matched, entry)
+		}
+
+		if vars.showJson {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if matched == nil {
+				matched = []node.AuditEntry{}
+			}
+			return enc.Encode(matched)
+		}
+
+		t := table.New(cmd.OutOrStdout())
+		t.AddHeader("TIME", "ACTOR", "SOURCE", "ENTITY", "ACTION", "FIELD", "OLD", "NEW")
+		for _, entry := range matched {
+			timestamp := entry.Time.Local().Format(time.DateTime)
+			entity := entry.Kind + "/" + entry.Entity
+			if len(entry.Changes) == 0 {
+				t.AddLine(timestamp, entry.Actor, entry.Source, entity, entry.Action, "--", "--", "--")
+			}
+			for _, change := range entry.Changes {
+				t.AddLine(timestamp, entry.Actor, entry.Source, entity, entry.Action, change.Field, node.AuditValueString(change.Old), node.AuditValueString(change.New))
+			}
+		}
+		t.Print()
+		return nil
+	}
+}
+
+// parseSince parses a duration before now or a date.
+func parseSince(since string) (time.Time, error) {
+	if duration, err := time.ParseDuration(since); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, since, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid duration or date: %s", since)
+}
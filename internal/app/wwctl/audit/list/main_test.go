@@ -0,0 +1,110 @@
+package list
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_List(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("var/local/warewulf/audit.log", `{"time":"2024-01-01T10:00:00Z","actor":"alice","source":"cli","kind":"node","entity":"n1","action":"update","changes":[{"field":"comment","old":"a","new":"b"},{"field":"network devices.default.ipaddr","new":"10.0.0.1"}]}
+{"time":"2024-01-02T10:00:00Z","actor":"token:ci","source":"api","kind":"profile","entity":"default","action":"update","changes":[{"field":"kernel.args","new":["quiet"]}]}
+{"time":"2024-01-03T10:00:00Z","actor":"00:00:00:00:00:02","source":"discovery","kind":"node","entity":"n2","action":"update","changes":[{"field":"discoverable","old":"true"}]}
+{"time":"2024-01-04T10:00:00Z","actor":"alice","source":"cli","kind":"node","entity":"n3","action":"delete"}
+`)
+
+	tests := map[string]struct {
+		args     []string
+		contains []string
+		excludes []string
+	}{
+		"all": {
+			args:     []string{},
+			contains: []string{"node/n1", "profile/default", "node/n2", "node/n3", `["quiet"]`},
+		},
+		"by actor": {
+			args:     []string{"--actor", "alice"},
+			contains: []string{"node/n1", "node/n3"},
+			excludes: []string{"node/n2", "profile/default"},
+		},
+		"by source": {
+			args:     []string{"--source", "discovery"},
+			contains: []string{"node/n2"},
+			excludes: []string{"node/n1", "node/n3"},
+		},
+		"by kind": {
+			args:     []string{"--kind", "profile"},
+			contains: []string{"profile/default"},
+			excludes: []string{"node/"},
+		},
+		"by field": {
+			args:     []string{"--field", "network devices"},
+			contains: []string{"network devices.default.ipaddr"},
+			excludes: []string{"comment", "node/n2"},
+		},
+		"by entity": {
+			args:     []string{"n[2-3]"},
+			contains: []string{"node/n2", "node/n3"},
+			excludes: []string{"node/n1"},
+		},
+		"since": {
+			args:     []string{"--since", "2024-01-03"},
+			contains: []string{"node/n3"},
+			excludes: []string{"node/n1", "profile/default"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := GetCommand()
+			cmd.SetArgs(tt.args)
+			buf := new(bytes.Buffer)
+			cmd.SetOut(buf)
+			cmd.SetErr(buf)
+			assert.NoError(t, cmd.Execute())
+			for _, s := range tt.contains {
+				assert.Contains(t, buf.String(), s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, buf.String(), s)
+			}
+		})
+	}
+
+	t.Run("invalid since", func(t *testing.T) {
+		cmd := GetCommand()
+		cmd.SetArgs([]string{"--since", "yesterday"})
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		assert.Error(t, cmd.Execute())
+	})
+}
+
+func Test_List_Persist(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles: {}
+nodes:
+  n1: {}`)
+	node.SetAuditActor(node.AuditActor{Name: "alice", Source: node.AuditSourceCLI})
+
+	registry, err := node.New()
+	assert.NoError(t, err)
+	registry.Nodes["n1"].ClusterName = "cluster1"
+	assert.NoError(t, registry.Persist())
+
+	cmd := GetCommand()
+	cmd.SetArgs([]string{"--json"})
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	assert.NoError(t, cmd.Execute())
+	assert.Contains(t, buf.String(), `"actor": "alice"`)
+	assert.Contains(t, buf.String(), `"field": "cluster name"`)
+	assert.Contains(t, buf.String(), `"new": "cluster1"`)
+}
@@ -0,0 +1,38 @@
+package list
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+type variables struct {
+	actor    string
+	source   string
+	kind     string
+	field    string
+	since    string
+	showJson bool
+}
+
+func GetCommand() *cobra.Command {
+	vars := variables{}
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "list [OPTIONS] [PATTERN]",
+		Short:                 "List audit log entries",
+		Long: "This command lists the changes made to nodes and profiles. Optionally, it\n" +
+			"will list only changes of the nodes and profiles matching a PATTERN.",
+		RunE:              CobraRunE(&vars),
+		Aliases:           []string{"ls"},
+		ValidArgsFunction: completions.Nodes,
+		Args:              cobra.ArbitraryArgs,
+	}
+	baseCmd.PersistentFlags().StringVar(&vars.actor, "actor", "", "Show only changes made by this user or API token")
+	baseCmd.PersistentFlags().StringVar(&vars.source, "source", "", "Show only changes from this source (cli, api, discovery)")
+	baseCmd.PersistentFlags().StringVar(&vars.kind, "kind", "", "Show only changes of nodes or profiles (node, profile)")
+	baseCmd.PersistentFlags().StringVar(&vars.field, "field", "", "Show only changes of this field, e.g. \"network devices.default.ipaddr\"")
+	baseCmd.PersistentFlags().StringVar(&vars.since, "since", "", "Show only changes since a duration ago (e.g. 24h) or a date (e.g. 2006-01-02)")
+	baseCmd.PersistentFlags().BoolVarP(&vars.showJson, "json", "j", false, "Show json format")
+
+	return baseCmd
+}
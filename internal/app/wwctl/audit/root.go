@@ -0,0 +1,25 @@
+package audit
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/audit/list"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "audit COMMAND [OPTIONS]",
+		Short:                 "Node configuration audit log",
+		Long:                  "Show the changes made to nodes and profiles, and who made them.",
+		Args:                  cobra.NoArgs,
+	}
+)
+
+func init() {
+	baseCmd.AddCommand(list.GetCommand())
+}
+
+// GetCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
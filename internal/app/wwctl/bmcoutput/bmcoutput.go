@@ -0,0 +1,74 @@
+// Package bmcoutput prints the results of BMC commands run by wwctl,
+// either as log lines or in a machine-readable format.
+package bmcoutput
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/bmc"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+	"gopkg.in/yaml.v3"
+)
+
+type Format struct {
+	Json bool
+	Yaml bool
+}
+
+// AddFlags adds the --json and --yaml flags to cmd.
+func AddFlags(cmd *cobra.Command, format *Format) {
+	cmd.PersistentFlags().BoolVarP(&format.Json, "json", "j", false, "Show results in json format")
+	cmd.PersistentFlags().BoolVarP(&format.Yaml, "yaml", "y", false, "Show results in yaml format")
+	cmd.MarkFlagsMutuallyExclusive("json", "yaml")
+}
+
+// Print prints results in the requested format and returns the last
+// error reported by any of the commands. Skipped nodes are reported
+// but do not fail the command. Machine-readable formats are
+// written to the output of cmd rather than logged, so that they are not
+// changed by the log level or formatting.
+func Print(cmd *cobra.Command, format Format, results []bmc.CommandResult) (returnErr error) {
+	for _, result := range results {
+		if result.Err() != nil {
+			returnErr = result.Err()
+		}
+	}
+
+	if format.Json {
+		out, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(out))
+		return returnErr
+	} else if format.Yaml {
+		out, err := yaml.Marshal(results)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(cmd.OutOrStdout(), string(out))
+		return returnErr
+	}
+
+	for _, result := range results {
+		prefix := result.Ipaddr
+		if prefix == "" {
+			prefix = result.Node
+		}
+		out := result.Output
+		if out == "" {
+			out = result.Error
+		}
+		for _, line := range strings.Split(out, "\n") {
+			if result.Err() != nil || result.Skipped {
+				wwlog.Error("%s: %s", prefix, line)
+			} else {
+				wwlog.Info("%s: %s", prefix, line)
+			}
+		}
+	}
+	return returnErr
+}
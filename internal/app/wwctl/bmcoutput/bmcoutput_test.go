@@ -0,0 +1,58 @@
+package bmcoutput
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/bmc"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_Print(t *testing.T) {
+	results := []bmc.CommandResult{
+		{Node: "n1", Ipaddr: "192.168.1.1", Command: "PowerStatus", State: "on", Output: "Fan 1 | 100% | ok"},
+	}
+	wwlog.SetLogLevel(wwlog.DEBUG)
+	defer wwlog.SetLogLevel(wwlog.INFO)
+
+	t.Run("json", func(t *testing.T) {
+		cmd := &cobra.Command{}
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		assert.NoError(t, Print(cmd, Format{Json: true}, results))
+		var printed []bmc.CommandResult
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &printed))
+		assert.Equal(t, results, printed)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		cmd := &cobra.Command{}
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		assert.NoError(t, Print(cmd, Format{Yaml: true}, results))
+		var printed []bmc.CommandResult
+		assert.NoError(t, yaml.Unmarshal(buf.Bytes(), &printed))
+		assert.Equal(t, results, printed)
+	})
+}
+
+func Test_PrintSkipped(t *testing.T) {
+	results := []bmc.CommandResult{
+		{Node: "n1", Command: "PowerStatus", Error: "no IPMI IP address", Skipped: true},
+	}
+
+	cmd := &cobra.Command{}
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	assert.NoError(t, Print(cmd, Format{Json: true}, results))
+	assert.Contains(t, buf.String(), `"skipped": true`)
+
+	logBuf := new(bytes.Buffer)
+	wwlog.SetLogWriter(logBuf)
+	assert.NoError(t, Print(cmd, Format{}, results))
+	assert.Contains(t, logBuf.String(), "n1: no IPMI IP address")
+}
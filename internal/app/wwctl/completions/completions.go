@@ -58,6 +58,20 @@ func Images(cmd *cobra.Command, args []string, toComplete string) ([]string, cob
 	return nil, cobra.ShellCompDirectiveNoFileComp
 }
 
+// ImageSnapshots completes an image, and then its snapshots.
+func ImageSnapshots(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return Images(cmd, args, toComplete)
+	}
+	var names []string
+	if snapshots, err := image.ListSnapshots(args[0]); err == nil {
+		for _, snapshot := range snapshots {
+			names = append(names, snapshot.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
 func Nodes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	if registry, err := node.New(); err == nil {
 		return registry.ListAllNodes(), cobra.ShellCompDirectiveNoFileComp
@@ -72,6 +86,20 @@ func Profiles(cmd *cobra.Command, args []string, toComplete string) ([]string, c
 	return nil, cobra.ShellCompDirectiveNoFileComp
 }
 
+func Revisions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var revs []string
+	if snapshots, err := node.History(); err == nil {
+		seen := make(map[string]bool)
+		for _, snap := range snapshots {
+			if !seen[snap.Rev] {
+				seen[snap.Rev] = true
+				revs = append(revs, snap.Rev)
+			}
+		}
+	}
+	return revs, cobra.ShellCompDirectiveNoFileComp
+}
+
 func Overlays(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	list := overlay.FindOverlays()
 	return list, cobra.ShellCompDirectiveNoFileComp
@@ -108,3 +136,13 @@ func None(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra
 func LocalFiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	return nil, cobra.ShellCompDirectiveDefault
 }
+
+func Networks(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var networks []string
+	if registry, err := node.New(); err == nil {
+		for id := range registry.Networks {
+			networks = append(networks, id)
+		}
+	}
+	return networks, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,43 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/hostlist"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	registry, err := node.New()
+	if err != nil {
+		return fmt.Errorf("could not read node configuration: %w", err)
+	}
+	problems, err := registry.Validate(hostlist.Expand(args)...)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No problems found")
+		return nil
+	}
+
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("SEVERITY", "NODE", "FIELD", "SOURCE", "MESSAGE")
+	for _, problem := range problems {
+		source := problem.Source
+		if source == "" {
+			source = "--"
+		}
+		t.AddLine(problem.Severity, problem.Node, problem.Field, source, problem.Message)
+	}
+	t.Print()
+
+	errors, warnings := node.CountProblems(problems)
+	strict, _ := cmd.Flags().GetBool("strict")
+	if errors > 0 || strict || node.StrictValidation() {
+		return fmt.Errorf("%d error(s), %d warning(s)", errors, warnings)
+	}
+	return nil
+}
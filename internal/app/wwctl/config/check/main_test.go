@@ -0,0 +1,102 @@
+package check
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Check(t *testing.T) {
+	var tests = map[string]struct {
+		nodesConf string
+		args      []string
+		strict    bool
+		wantErr   bool
+		output    []string
+	}{
+		"no problems": {
+			nodesConf: `
+nodes:
+  n1: {}`,
+			output: []string{"No problems found"},
+		},
+		"error": {
+			nodesConf: `
+nodeprofiles:
+  default:
+    profiles:
+    - missing
+nodes:
+  n1:
+    profiles:
+    - default`,
+			wantErr: true,
+			output:  []string{"error     n1    Profiles  default  profile missing does not exist"},
+		},
+		"warning": {
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default: {}`,
+			output: []string{"warning   n1    NetDevs[default].Device  --      network device default has no device"},
+		},
+		"strict warning": {
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default: {}`,
+			strict:  true,
+			wantErr: true,
+		},
+		"strict flag": {
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default: {}`,
+			args:    []string{"--strict"},
+			wantErr: true,
+		},
+		"other node": {
+			nodesConf: `
+nodes:
+  n1:
+    profiles:
+    - missing
+  n2: {}`,
+			args:   []string{"n2"},
+			output: []string{"No problems found"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			env := testenv.New(t)
+			defer env.RemoveAll()
+			env.WriteFile("etc/warewulf/nodes.conf", tt.nodesConf)
+			strict := tt.strict
+			warewulfconf.Get().NodeDB.StrictP = &strict
+
+			cmd := GetCommand()
+			cmd.SetArgs(tt.args)
+			buf := new(bytes.Buffer)
+			cmd.SetOut(buf)
+			cmd.SetErr(buf)
+			err := cmd.Execute()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			for _, line := range tt.output {
+				assert.Contains(t, buf.String(), line)
+			}
+		})
+	}
+}
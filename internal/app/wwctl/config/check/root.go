@@ -0,0 +1,23 @@
+package check
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+func GetCommand() *cobra.Command {
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "check [NODE ...]",
+		Short:                 "Check the node configuration for problems",
+		Long: "This command checks the merged nodes for problems, such as duplicate\n" +
+			"hardware addresses, missing profiles, images, and overlays, addresses outside\n" +
+			"of the network, and network devices without a device. Each problem is listed\n" +
+			"with its node, field, and the profile the field was merged from.\n\n" +
+			"The command fails if there are errors, or, with --strict, any problems.",
+		RunE:              CobraRunE,
+		ValidArgsFunction: completions.Nodes,
+	}
+	baseCmd.Flags().Bool("strict", false, "Fail if there are any problems, not only errors")
+	return baseCmd
+}
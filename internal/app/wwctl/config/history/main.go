@@ -0,0 +1,109 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+// revLength is the length of the revisions shown in the history.
+const revLength = 12
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	current, modTime, err := node.Current()
+	if err != nil {
+		return fmt.Errorf("could not read node configuration: %w", err)
+	}
+
+	if len(args) == 1 {
+		snap, err := node.FindSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+		data, err := snap.Read()
+		if err != nil {
+			return err
+		}
+		entries, err := node.AuditDiff(data, current, node.AuditActor{})
+		if err != nil {
+			return err
+		}
+		t := table.New(cmd.OutOrStdout())
+		t.AddHeader("ENTITY", "ACTION", "FIELD", "OLD", "NEW")
+		for _, entry := range entries {
+			entity := entry.Kind + "/" + entry.Entity
+			if len(entry.Changes) == 0 {
+				t.AddLine(entity, entry.Action, "--", "--", "--")
+			}
+			for _, change := range entry.Changes {
+				t.AddLine(entity, entry.Action, change.Field, node.AuditValueString(change.Old), node.AuditValueString(change.New))
+			}
+		}
+		t.Print()
+		return nil
+	}
+
+	snapshots, err := node.History()
+	if err != nil {
+		return fmt.Errorf("could not read node configuration history: %w", err)
+	}
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("REV", "TIME", "NODES", "PROFILES", "CHANGES")
+	nodes, profiles := count(current)
+	t.AddLine(shortRev(node.Revision(current)), modTime.Format(time.DateTime), nodes, profiles, "(current)")
+	newer := current
+	for _, snap := range snapshots {
+		data, err := snap.Read()
+		if err != nil {
+			return err
+		}
+		nodes, profiles := count(data)
+		t.AddLine(shortRev(snap.Rev), snap.Time.Format(time.DateTime), nodes, profiles, changes(data, newer))
+		newer = data
+	}
+	t.Print()
+	return nil
+}
+
+func shortRev(rev string) string {
+	if len(rev) > revLength {
+		return rev[:revLength]
+	}
+	return rev
+}
+
+func count(data []byte) (nodes string, profiles string) {
+	registry, err := node.Parse(data)
+	if err != nil {
+		return "--", "--"
+	}
+	return fmt.Sprint(len(registry.Nodes)), fmt.Sprint(len(registry.NodeProfiles))
+}
+
+// changes summarizes the nodes and profiles added (+), deleted (-), and
+// updated (~) by the newer version.
+func changes(data []byte, newer []byte) string {
+	entries, err := node.AuditDiff(data, newer, node.AuditActor{})
+	if err != nil {
+		return "--"
+	}
+	var summary []string
+	for _, entry := range entries {
+		prefix := "~"
+		switch entry.Action {
+		case "add":
+			prefix = "+"
+		case "delete":
+			prefix = "-"
+		}
+		summary = append(summary, prefix+entry.Kind+"/"+entry.Entity)
+	}
+	if len(summary) == 0 {
+		return "--"
+	}
+	return strings.Join(summary, " ")
+}
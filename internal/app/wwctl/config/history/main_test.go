@@ -0,0 +1,47 @@
+package history
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_History(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default: {}
+nodes:
+  n1:
+    profiles:
+    - default`)
+
+	registry, err := node.New()
+	assert.NoError(t, err)
+	original := registry.StringHash()
+	registry.NodeProfiles["default"].ClusterName = "broken"
+	_, err = registry.AddNode("n2")
+	assert.NoError(t, err)
+	assert.NoError(t, registry.Persist())
+
+	cmd := GetCommand()
+	cmd.SetArgs([]string{})
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	assert.NoError(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "(current)")
+	assert.Contains(t, buf.String(), original[:12])
+	assert.Contains(t, buf.String(), "~profile/default +node/n2")
+
+	cmd = GetCommand()
+	cmd.SetArgs([]string{original[:12]})
+	buf = new(bytes.Buffer)
+	cmd.SetOut(buf)
+	assert.NoError(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "profile/default  update  cluster name")
+	assert.Contains(t, buf.String(), "node/n2          add")
+}
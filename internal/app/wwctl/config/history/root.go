@@ -0,0 +1,21 @@
+package history
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+func GetCommand() *cobra.Command {
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "history [REV]",
+		Short:                 "List previous versions of the node configuration",
+		Long: "This command lists the previous versions of nodes.conf, newest first, with\n" +
+			"the nodes and profiles changed by the following version. If a revision REV\n" +
+			"is given, it shows the changes between REV and the current nodes.conf.",
+		RunE:              CobraRunE,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completions.Revisions,
+	}
+	return baseCmd
+}
@@ -0,0 +1,28 @@
+package rollback
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		snap, err := node.FindSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+		if !vars.setYes && !util.Confirm(fmt.Sprintf("Are you sure you want to roll back the node configuration to %s", snap.Rev)) {
+			return nil
+		}
+		if err := node.Rollback(snap.Rev); err != nil {
+			return fmt.Errorf("could not roll back node configuration: %w", err)
+		}
+		wwlog.Info("rolled back node configuration to %s", snap.Rev)
+		return warewulfd.DaemonReload()
+	}
+}
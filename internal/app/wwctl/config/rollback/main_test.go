@@ -0,0 +1,47 @@
+package rollback
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+)
+
+func Test_Rollback(t *testing.T) {
+	warewulfd.SetNoDaemon()
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default: {}
+nodes:
+  n1:
+    profiles:
+    - default`)
+
+	registry, err := node.New()
+	assert.NoError(t, err)
+	original := registry.StringHash()
+	registry.NodeProfiles["default"].ClusterName = "broken"
+	_, err = registry.AddNode("n2")
+	assert.NoError(t, err)
+	assert.NoError(t, registry.Persist())
+
+	cmd := GetCommand()
+	cmd.SetArgs([]string{"--yes", original[:12]})
+	assert.NoError(t, cmd.Execute())
+
+	registry, err = node.New()
+	assert.NoError(t, err)
+	assert.Equal(t, original, registry.StringHash())
+	assert.NotContains(t, registry.Nodes, "n2")
+
+	cmd = GetCommand()
+	cmd.SetArgs([]string{"--yes", "unknown"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	assert.Error(t, cmd.Execute())
+}
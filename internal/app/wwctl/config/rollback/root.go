@@ -0,0 +1,29 @@
+package rollback
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
+)
+
+type variables struct {
+	setYes bool
+}
+
+func GetCommand() *cobra.Command {
+	vars := variables{}
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "rollback [OPTIONS] REV",
+		Short:                 "Restore a previous version of the node configuration",
+		Long: "This command restores the version REV of nodes.conf, as listed by\n" +
+			"\"wwctl config history\", and reloads warewulfd. REV may be abbreviated.\n" +
+			"The replaced version is kept in the history.",
+		RunE:              CobraRunE(&vars),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completions.Revisions,
+	}
+	baseCmd.PersistentFlags().BoolVarP(&vars.setYes, "yes", "y", false, "Set 'yes' to all questions asked")
+	flags.AddStrict(baseCmd)
+	return baseCmd
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/config/check"
+	"github.com/warewulf/warewulf/internal/app/wwctl/config/history"
+	"github.com/warewulf/warewulf/internal/app/wwctl/config/rollback"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "config COMMAND [OPTIONS]",
+		Short:                 "Node configuration management",
+		Long:                  "Check and manage the versions of the node configuration (nodes.conf).",
+		Args:                  cobra.NoArgs,
+	}
+)
+
+func init() {
+	baseCmd.AddCommand(check.GetCommand())
+	baseCmd.AddCommand(history.GetCommand())
+	baseCmd.AddCommand(rollback.GetCommand())
+}
+
+// GetCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -27,3 +27,10 @@ func AddRuntime(cmd *cobra.Command, dest *[]string) {
 		panic(err)
 	}
 }
+
+// AddStrict adds the --strict flag to a command which writes the node
+// configuration. It is applied by the root command, like nodedb:strict
+// in warewulf.conf.
+func AddStrict(cmd *cobra.Command) {
+	cmd.Flags().Bool("strict", false, "Refuse to write node configuration with problems")
+}
@@ -4,6 +4,7 @@
 package exec
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -109,7 +110,19 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 	beforeGroupTime := getTime(path.Join(imagePath, "/etc/group"))
 	wwlog.Debug("groupTime: %v", beforeGroupTime)
 
+	if Snapshot {
+		snapshot, err := image.CreateSnapshot(imageName, "", args[1:])
+		if err != nil {
+			return fmt.Errorf("could not create snapshot of image %s: %w", imageName, err)
+		}
+		wwlog.Info("Created snapshot %s (restore with: wwctl image snapshot restore %s %s)", snapshot.Name, imageName, snapshot.Name)
+	}
+
+	start := time.Now()
 	err := runContainedCmd(cmd, imageName, args[1:])
+	if recordErr := image.RecordExec(imageName, start, args[1:], exitCode(err)); recordErr != nil {
+		wwlog.Warn("could not record command in image history: %s", recordErr)
+	}
 	if err != nil {
 		return fmt.Errorf("command returned an error: %v: %s", args[1:], err)
 	}
@@ -159,6 +172,18 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// exitCode returns the exit code of a contained command which returned
+// err, or -1 if it could not be run.
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if err == nil {
+		return 0
+	} else if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
 func getTime(path string) time.Time {
 	if fileStat, err := os.Stat(path); err != nil {
 		return time.Time{}
@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/warewulf/warewulf/internal/pkg/image"
 	"github.com/warewulf/warewulf/internal/pkg/testenv"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
 )
@@ -104,3 +105,67 @@ func Test_Exec(t *testing.T) {
 		})
 	}
 }
+
+func Test_ExecHistory(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.MkdirAll("/var/lib/warewulf/chroots/test/rootfs")
+	defer func() {
+		childCommandFunc = runChildCmd
+		Build = true
+	}()
+	warewulfd.SetNoDaemon()
+
+	childCommandFunc = mockChildCmd
+	cmd := GetCommand()
+	cmd.SetArgs([]string{"test", "--build=false", "--", "/usr/bin/dnf", "-y", "update"})
+	cmd.SetOut(bytes.NewBufferString(""))
+	cmd.SetErr(bytes.NewBufferString(""))
+	assert.NoError(t, cmd.Execute())
+
+	childCommandFunc = func(cmd *cobra.Command, args []string) error {
+		return exec.Command("/bin/false").Run()
+	}
+	cmd.SetArgs([]string{"test", "--build=false", "/bin/false"})
+	assert.Error(t, cmd.Execute())
+
+	md, err := image.ReadMetadata("test")
+	assert.NoError(t, err)
+	if assert.Len(t, md.History, 2) {
+		assert.Equal(t, []string{"/usr/bin/dnf", "-y", "update"}, md.History[0].Command)
+		assert.Equal(t, 0, md.History[0].ExitCode)
+		assert.False(t, md.History[0].Time.IsZero())
+		assert.Equal(t, []string{"/bin/false"}, md.History[1].Command)
+		assert.Equal(t, 1, md.History[1].ExitCode)
+	}
+}
+
+func Test_ExecSnapshot(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/var/lib/warewulf/chroots/test/rootfs/etc/os-release", "v1")
+	defer func() {
+		childCommandFunc = runChildCmd
+		Build = true
+		Snapshot = false
+	}()
+	warewulfd.SetNoDaemon()
+
+	childCommandFunc = func(cmd *cobra.Command, args []string) error {
+		return os.WriteFile(env.GetPath("/var/lib/warewulf/chroots/test/rootfs/etc/os-release"), []byte("v2"), 0644)
+	}
+	cmd := GetCommand()
+	cmd.SetArgs([]string{"test", "--build=false", "--snapshot", "--", "/usr/bin/dnf", "-y", "update"})
+	cmd.SetOut(bytes.NewBufferString(""))
+	cmd.SetErr(bytes.NewBufferString(""))
+	assert.NoError(t, cmd.Execute())
+
+	snapshots, err := image.ListSnapshots("test")
+	assert.NoError(t, err)
+	if assert.Len(t, snapshots, 1) {
+		assert.Equal(t, []string{"/usr/bin/dnf", "-y", "update"}, snapshots[0].Command)
+		assert.NoError(t, image.RestoreSnapshot("test", snapshots[0].Name))
+		content, _ := os.ReadFile(env.GetPath("/var/lib/warewulf/chroots/test/rootfs/etc/os-release"))
+		assert.Equal(t, "v1", string(content))
+	}
+}
@@ -27,6 +27,7 @@ var (
 	}
 	SyncUser bool
 	Build    bool
+	Snapshot bool
 	binds    []string
 	nodeName string
 )
@@ -39,6 +40,7 @@ set, uses the same path as source. "ro" binds read-only. "copy" temporarily
 copies the file into the image.`)
 	baseCmd.PersistentFlags().BoolVar(&SyncUser, "syncuser", false, "Synchronize UIDs/GIDs from host to image")
 	baseCmd.PersistentFlags().BoolVar(&Build, "build", true, "(Re)build the image automatically")
+	baseCmd.PersistentFlags().BoolVar(&Snapshot, "snapshot", false, "Create a snapshot of the image before running the command")
 	baseCmd.PersistentFlags().StringVarP(&nodeName, "node", "n", "", "Create a read only view of the image for the given node")
 }
 
@@ -0,0 +1,23 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	apiimage "github.com/warewulf/warewulf/internal/pkg/api/image"
+	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) (err error) {
+	name, dest := args[0], args[1]
+	sCtx, err := apiimage.GetSystemContext(OciNoHttps, OciUsername, OciPassword, "")
+	if err != nil {
+		return err
+	}
+	if err := image.Export(name, dest, sCtx); err != nil {
+		return fmt.Errorf("could not export image %s: %w", name, err)
+	}
+	wwlog.Info("Exported image %s to %s", name, dest)
+	return nil
+}
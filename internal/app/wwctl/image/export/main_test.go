@@ -0,0 +1,61 @@
+package export
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+)
+
+func Test_Export(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/var/lib/warewulf/chroots/test/rootfs/etc/os-release", "test")
+	warewulfd.SetNoDaemon()
+	layout := filepath.Join(t.TempDir(), "layout")
+
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{
+			name: "oci layout",
+			args: []string{"test", "oci:" + layout + ":test"},
+		},
+		{
+			name: "unknown image",
+			args: []string{"unknown", "oci:" + layout + ":unknown"},
+			err:  "could not export image unknown: image does not exist: unknown",
+		},
+		{
+			name: "unknown transport",
+			args: []string{"test", "ftp://example.com/test"},
+			err:  "unknown uri scheme",
+		},
+		{
+			name: "missing destination",
+			args: []string{"test"},
+			err:  "accepts 2 arg(s), received 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := GetCommand()
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(bytes.NewBufferString(""))
+			cmd.SetErr(bytes.NewBufferString(""))
+			err := cmd.Execute()
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+				assert.FileExists(t, filepath.Join(layout, "index.json"))
+			}
+		})
+	}
+}
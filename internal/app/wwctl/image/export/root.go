@@ -0,0 +1,46 @@
+package export
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "export [OPTIONS] IMAGE DESTINATION",
+		Short:                 "Export an image as an OCI image",
+		Long: `This command packs the rootfs of an image into an OCI image and writes it
+to a registry (docker://), an OCI archive (oci-archive:), a docker
+archive (docker-archive:), or an OCI layout directory (oci:).
+
+Files matching the patterns in /etc/warewulf/excludes of the image are
+left out, as they are when the image is built. The exported image is
+annotated with the name, source, and parent of the image, and the
+commands run in it are recorded in its history.`,
+		Example: `wwctl image export rockylinux-9 docker://registry.example.com/warewulf/rockylinux-9:latest
+wwctl image export rockylinux-9 oci-archive:/tmp/rockylinux-9.tar`,
+		RunE: CobraRunE,
+		Args: cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return completions.Images(cmd, args, toComplete)
+			}
+			return completions.None(cmd, args, toComplete)
+		},
+	}
+	OciNoHttps  bool
+	OciUsername string
+	OciPassword string
+)
+
+func init() {
+	baseCmd.PersistentFlags().BoolVar(&OciNoHttps, "nohttps", false, "Ignore wrong TLS certificates, superseedes env WAREWULF_OCI_NOHTTPS")
+	baseCmd.PersistentFlags().StringVar(&OciUsername, "username", "", "Set username for the access to the registry, superseedes env WAREWULF_OCI_USERNAME")
+	baseCmd.PersistentFlags().StringVar(&OciPassword, "password", "", "Set password for the access to the registry, superseedes env WAREWULF_OCI_PASSWORD")
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
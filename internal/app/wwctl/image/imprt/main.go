@@ -27,6 +27,6 @@ func CobraRunE(cmd *cobra.Command, args []string) (err error) {
 		Platform:    Platform,
 	}
 
-	_, err = apiimage.ImageImport(cip)
+	_, err = apiimage.ImageImport(cip, Verify, PolicyPath)
 	return
 }
@@ -10,7 +10,6 @@ var (
 		DisableFlagsInUseLine: true,
 		Use:                   "import [OPTIONS] SOURCE [NAME]",
 		Short:                 "Import an image into Warewulf",
-		Aliases:               []string{"pull"},
 		Long: `This command will pull and import an image into Warewulf from SOURCE,
 optionally renaming it to NAME. The SOURCE must be in a supported URI format. Formats
 are:
@@ -19,7 +18,13 @@ are:
  * file://path/to/archive/tar/ball
  * /path/to/archive/tar/ball
  * /path/to/chroot/
-Imported images are used to create bootable images.`,
+Imported images are used to create bootable images.
+
+With --verify, the signatures of the image are verified with the policy or
+keys configured in warewulf.conf, or else with the system default policy
+(/etc/containers/policy.json). --policy verifies them with the given
+containers-policy.json(5) file. If "signature: verify" is set in
+warewulf.conf, every image is verified.`,
 		Example: "wwctl image import docker://ghcr.io/warewulf/warewulf-rockylinux:8 rockylinux-8",
 		RunE:    CobraRunE,
 		Args:    cobra.RangeArgs(1, 2),
@@ -37,6 +42,8 @@ Imported images are used to create bootable images.`,
 	OciUsername string
 	OciPassword string
 	Platform    string
+	Verify      bool
+	PolicyPath  string
 )
 
 func init() {
@@ -52,6 +59,8 @@ func init() {
 	baseCmd.PersistentFlags().StringVar(&Platform, "platform", "", "Set other hardware platform e.g. amd64 or arm64, superseedes env WAREWULF_OCI_PLATFORM")
 	baseCmd.PersistentFlags().StringVar(&Platform, "arch", "", "Set other hardware platform, superseedes env WAREWULF_OCI_PLATFORM")
 	_ = baseCmd.PersistentFlags().MarkHidden("arch")
+	baseCmd.PersistentFlags().BoolVar(&Verify, "verify", false, "Verify the signatures of the image")
+	baseCmd.PersistentFlags().StringVar(&PolicyPath, "policy", "", "Verify the signatures of the image with this signature policy file")
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
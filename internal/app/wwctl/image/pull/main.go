@@ -0,0 +1,79 @@
+package pull
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	apiimage "github.com/warewulf/warewulf/internal/pkg/api/image"
+	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/oci"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) (err error) {
+	name := args[0]
+	sCtx, err := apiimage.GetSystemContext(OciNoHttps, OciUsername, OciPassword, Platform)
+	if err != nil {
+		return err
+	}
+	if PolicyPath != "" {
+		sCtx.SignaturePolicyPath = PolicyPath
+	}
+
+	result, err := image.Pull(name, sCtx, image.PullOptions{
+		Preserve: Preserve,
+		Discard:  Discard,
+		Verify:   Verify || PolicyPath != "",
+	})
+	if result != nil && len(result.Changes) > 0 {
+		printChanges(cmd, result)
+	}
+	if errors.Is(err, image.ErrLocalChanges) {
+		return fmt.Errorf("%w: keep them with --preserve or discard them with --discard", err)
+	} else if err != nil {
+		return fmt.Errorf("could not pull image %s: %w", name, err)
+	}
+	if result.UpToDate() {
+		wwlog.Info("Image %s is up to date: %s", name, result.NewDigest)
+		return nil
+	}
+	wwlog.Info("Updated image %s: %s -> %s (%d of %d layers unpacked)",
+		name, result.OldDigest, result.NewDigest, result.Applied, result.Layers)
+	if Preserve && len(result.Conflicts) > 0 {
+		wwlog.Warn("%d local modifications replaced files changed upstream", len(result.Conflicts))
+	}
+
+	if SyncUser {
+		if err = image.Syncuser(name, true); err != nil {
+			return fmt.Errorf("syncuser error: %w", err)
+		}
+	}
+	if SetBuild {
+		wwlog.Info("Building image: %s", name)
+		if err = image.Build(name, true); err != nil {
+			return fmt.Errorf("could not build image %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// printChanges lists the local modifications of an image, and whether
+// the files were also changed upstream.
+func printChanges(cmd *cobra.Command, result *image.PullResult) {
+	conflicts := make(map[oci.Change]bool)
+	for _, change := range result.Conflicts {
+		conflicts[change] = true
+	}
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("CHANGE", "PATH", "CHANGED UPSTREAM")
+	for _, change := range result.Changes {
+		upstream := "no"
+		if conflicts[change] {
+			upstream = "yes"
+		}
+		t.AddLine(string(change.Kind), change.Path, upstream)
+	}
+	t.Print()
+}
@@ -0,0 +1,64 @@
+package pull
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+)
+
+func Test_Pull(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.CreateFile("/tmp/dir/bin/sh")
+	assert.NoError(t, image.ImportDirectory(env.GetPath("/tmp/dir"), "dir"))
+	env.MkdirAll("/var/lib/warewulf/chroots/sandbox/rootfs")
+	assert.NoError(t, image.WriteMetadata("sandbox", &image.Metadata{Source: env.GetPath("/tmp/dir"), Digest: "sha256:0123"}))
+	warewulfd.SetNoDaemon()
+
+	// the mutually exclusive flags stay set, so that case runs last
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{
+			name: "unknown image",
+			args: []string{"unknown"},
+			err:  "image does not exist: unknown",
+		},
+		{
+			name: "no recorded source",
+			args: []string{"dir"},
+			err:  "the OCI source of image dir is not recorded",
+		},
+		{
+			name: "directory source",
+			args: []string{"sandbox"},
+			err:  "image sandbox was not imported from an OCI source",
+		},
+		{
+			name: "preserve and discard",
+			args: []string{"--preserve", "--discard", "dir"},
+			err:  "[discard preserve] were all set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				Preserve = false
+				Discard = false
+			}()
+			cmd := GetCommand()
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(bytes.NewBufferString(""))
+			cmd.SetErr(bytes.NewBufferString(""))
+			err := cmd.Execute()
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
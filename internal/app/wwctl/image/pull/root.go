@@ -0,0 +1,60 @@
+package pull
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "pull [OPTIONS] IMAGE",
+		Short:                 "Update an image from its source",
+		Long: `This command updates an image from the OCI source it was imported from.
+If the source has changed, only its new layers are fetched, and the image
+is rebased onto them.
+
+Files which were changed in the image since it was imported, e.g. with
+'wwctl image exec', are local modifications. An image with local
+modifications is not updated unless they are kept with --preserve or
+discarded with --discard.`,
+		Example: "wwctl image pull rockylinux-9",
+		RunE:    CobraRunE,
+		Args:    cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return completions.Images(cmd, args, toComplete)
+			}
+			return completions.None(cmd, args, toComplete)
+		},
+	}
+	Preserve    bool
+	Discard     bool
+	SetBuild    bool
+	SyncUser    bool
+	OciNoHttps  bool
+	OciUsername string
+	OciPassword string
+	Platform    string
+	Verify      bool
+	PolicyPath  string
+)
+
+func init() {
+	baseCmd.PersistentFlags().BoolVar(&Preserve, "preserve", false, "Keep the local modifications of the image")
+	baseCmd.PersistentFlags().BoolVar(&Discard, "discard", false, "Discard the local modifications of the image")
+	baseCmd.MarkFlagsMutuallyExclusive("preserve", "discard")
+	baseCmd.PersistentFlags().BoolVarP(&SetBuild, "build", "b", false, "Build image after pulling")
+	baseCmd.PersistentFlags().BoolVar(&SyncUser, "syncuser", false, "Synchronize UIDs/GIDs from host to image")
+	baseCmd.PersistentFlags().BoolVar(&OciNoHttps, "nohttps", false, "Ignore wrong TLS certificates, superseedes env WAREWULF_OCI_NOHTTPS")
+	baseCmd.PersistentFlags().StringVar(&OciUsername, "username", "", "Set username for the access to the registry, superseedes env WAREWULF_OCI_USERNAME")
+	baseCmd.PersistentFlags().StringVar(&OciPassword, "password", "", "Set password for the access to the registry, superseedes env WAREWULF_OCI_PASSWORD")
+	baseCmd.PersistentFlags().StringVar(&Platform, "platform", "", "Set other hardware platform e.g. amd64 or arm64, defaults to the platform of the image")
+	baseCmd.PersistentFlags().BoolVar(&Verify, "verify", false, "Verify the signatures of the image")
+	baseCmd.PersistentFlags().StringVar(&PolicyPath, "policy", "", "Verify the signatures of the image with this signature policy file")
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -3,6 +3,7 @@ package rename
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 )
 
 var baseCmd = &cobra.Command{
@@ -25,6 +26,7 @@ var SetBuild bool
 
 func init() {
 	baseCmd.PersistentFlags().BoolVarP(&SetBuild, "build", "b", false, "Build image after rename")
+	flags.AddStrict(baseCmd)
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
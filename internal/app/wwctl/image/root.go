@@ -6,12 +6,15 @@ import (
 	"github.com/warewulf/warewulf/internal/app/wwctl/image/copy"
 	"github.com/warewulf/warewulf/internal/app/wwctl/image/delete"
 	"github.com/warewulf/warewulf/internal/app/wwctl/image/exec"
+	"github.com/warewulf/warewulf/internal/app/wwctl/image/export"
 	"github.com/warewulf/warewulf/internal/app/wwctl/image/imprt"
 	"github.com/warewulf/warewulf/internal/app/wwctl/image/kernels"
 	"github.com/warewulf/warewulf/internal/app/wwctl/image/list"
+	"github.com/warewulf/warewulf/internal/app/wwctl/image/pull"
 	"github.com/warewulf/warewulf/internal/app/wwctl/image/rename"
 	"github.com/warewulf/warewulf/internal/app/wwctl/image/shell"
 	"github.com/warewulf/warewulf/internal/app/wwctl/image/show"
+	"github.com/warewulf/warewulf/internal/app/wwctl/image/snapshot"
 	"github.com/warewulf/warewulf/internal/app/wwctl/image/syncuser"
 )
 
@@ -30,6 +33,8 @@ func init() {
 	baseCmd.AddCommand(build.GetCommand())
 	baseCmd.AddCommand(list.GetCommand())
 	baseCmd.AddCommand(imprt.GetCommand())
+	baseCmd.AddCommand(pull.GetCommand())
+	baseCmd.AddCommand(export.GetCommand())
 	baseCmd.AddCommand(exec.GetCommand())
 	baseCmd.AddCommand(shell.GetCommand())
 	baseCmd.AddCommand(delete.GetCommand())
@@ -38,6 +43,7 @@ func init() {
 	baseCmd.AddCommand(copy.GetCommand())
 	baseCmd.AddCommand(rename.GetCommand())
 	baseCmd.AddCommand(kernels.GetCommand())
+	baseCmd.AddCommand(snapshot.GetCommand())
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
@@ -48,6 +48,7 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 	cntexec.SetNode(nodeName)
 	cntexec.SyncUser = syncUser
 	cntexec.Build = build
+	cntexec.Snapshot = snapshot
 	if cntexec.Build {
 		wwlog.Info("Image build will be skipped if the shell ends with a non-zero exit code.")
 	}
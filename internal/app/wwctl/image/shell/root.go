@@ -27,6 +27,7 @@ var (
 	nodeName string
 	syncUser bool
 	build    bool
+	snapshot bool
 )
 
 func init() {
@@ -39,6 +40,7 @@ copies the file into the image.`)
 node`)
 	baseCmd.PersistentFlags().BoolVar(&syncUser, "syncuser", false, "Synchronize UIDs/GIDs from host to image")
 	baseCmd.PersistentFlags().BoolVar(&build, "build", true, "(Re)build the image automatically")
+	baseCmd.PersistentFlags().BoolVar(&snapshot, "snapshot", false, "Create a snapshot of the image before running the shell")
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
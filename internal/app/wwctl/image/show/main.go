@@ -2,9 +2,14 @@ package show
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
 	"github.com/warewulf/warewulf/internal/pkg/api/image"
 	"github.com/warewulf/warewulf/internal/pkg/api/routes/wwapiv1"
+	wwimage "github.com/warewulf/warewulf/internal/pkg/image"
 
 	"github.com/spf13/cobra"
 )
@@ -20,18 +25,66 @@ func CobraRunE(cmd *cobra.Command, args []string) (err error) {
 		return
 	}
 
+	if ShowHistory {
+		return showHistory(cmd, r.Name)
+	}
+
+	out := cmd.OutOrStdout()
 	if !ShowAll {
-		fmt.Printf("%s\n", r.Rootfs)
+		fmt.Fprintf(out, "%s\n", r.Rootfs)
 	} else {
 		kernelVersion := r.KernelVersion
 		if kernelVersion == "" {
 			kernelVersion = "not found"
 		}
-		fmt.Printf("Name: %s\n", r.Name)
-		fmt.Printf("KernelVersion: %s\n", kernelVersion)
-		fmt.Printf("Rootfs: %s\n", r.Rootfs)
-		fmt.Printf("Nr nodes: %d\n", len(r.Nodes))
-		fmt.Printf("Nodes: %s\n", r.Nodes)
+		md, err := wwimage.ReadMetadata(r.Name)
+		if err != nil {
+			return fmt.Errorf("could not read metadata of image %s: %w", r.Name, err)
+		}
+		fmt.Fprintf(out, "Name: %s\n", r.Name)
+		fmt.Fprintf(out, "KernelVersion: %s\n", kernelVersion)
+		fmt.Fprintf(out, "Rootfs: %s\n", r.Rootfs)
+		fmt.Fprintf(out, "Nr nodes: %d\n", len(r.Nodes))
+		fmt.Fprintf(out, "Nodes: %s\n", r.Nodes)
+		fmt.Fprintf(out, "Source: %s\n", orUnknown(md.Source))
+		fmt.Fprintf(out, "Digest: %s\n", orUnknown(md.Digest))
+		fmt.Fprintf(out, "Platform: %s\n", orUnknown(md.Platform))
+		imported := ""
+		if !md.Imported.IsZero() {
+			imported = md.Imported.Local().Format(time.RFC3339)
+		}
+		fmt.Fprintf(out, "Imported: %s\n", orUnknown(imported))
+		fmt.Fprintf(out, "Imported by: %s\n", orUnknown(md.User))
+		if md.Parent != "" {
+			fmt.Fprintf(out, "Parent: %s\n", md.Parent)
+		}
+		fmt.Fprintf(out, "Nr commands: %d\n", len(md.History))
 	}
 	return
 }
+
+// showHistory lists the commands run in an image.
+func showHistory(cmd *cobra.Command, name string) error {
+	md, err := wwimage.ReadMetadata(name)
+	if err != nil {
+		return fmt.Errorf("could not read metadata of image %s: %w", name, err)
+	}
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("TIME", "USER", "EXIT", "COMMAND")
+	for _, record := range md.History {
+		t.AddLine(table.Prep([]string{
+			record.Time.Local().Format(time.DateTime),
+			record.User,
+			strconv.Itoa(record.ExitCode),
+			strings.Join(record.Command, " ")})...)
+	}
+	t.Print()
+	return nil
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
@@ -0,0 +1,84 @@
+package show
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+)
+
+func Test_Show(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles: {}
+nodes:
+  n1:
+    image name: rocky
+`)
+	env.MkdirAll("/var/lib/warewulf/chroots/rocky/rootfs")
+	imported := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, image.WriteMetadata("rocky", &image.Metadata{
+		Source:   "docker://ghcr.io/warewulf/warewulf-rockylinux:9",
+		Digest:   "sha256:0123",
+		Platform: "linux/amd64",
+		Imported: imported,
+		User:     "admin",
+		History: []image.ExecRecord{
+			{Time: imported, User: "admin", Command: []string{"dnf", "-y", "update"}, ExitCode: 0},
+			{Time: imported, User: "admin", Command: []string{"/bin/false"}, ExitCode: 1},
+		},
+	}))
+	env.MkdirAll("/var/lib/warewulf/chroots/legacy/rootfs")
+	warewulfd.SetNoDaemon()
+
+	tests := map[string]struct {
+		args     []string
+		contains []string
+	}{
+		"rootfs": {
+			args:     []string{"rocky"},
+			contains: []string{env.GetPath("/var/lib/warewulf/chroots/rocky/rootfs")},
+		},
+		"all": {
+			args: []string{"-a", "rocky"},
+			contains: []string{
+				"Source: docker://ghcr.io/warewulf/warewulf-rockylinux:9\n",
+				"Digest: sha256:0123\n",
+				"Platform: linux/amd64\n",
+				"Imported: " + imported.Local().Format(time.RFC3339) + "\n",
+				"Imported by: admin\n",
+				"Nr commands: 2\n",
+			},
+		},
+		"all without metadata": {
+			args:     []string{"-a", "legacy"},
+			contains: []string{"Source: unknown\n", "Nr commands: 0\n"},
+		},
+		"history": {
+			args:     []string{"--history", "rocky"},
+			contains: []string{"TIME", "COMMAND", "dnf -y update", "/bin/false"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				ShowAll = false
+				ShowHistory = false
+			}()
+			cmd := GetCommand()
+			cmd.SetArgs(tt.args)
+			out := bytes.NewBufferString("")
+			cmd.SetOut(out)
+			assert.NoError(t, cmd.Execute())
+			for _, s := range tt.contains {
+				assert.Contains(t, out.String(), s)
+			}
+		})
+	}
+}
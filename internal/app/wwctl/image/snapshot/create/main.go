@@ -0,0 +1,21 @@
+package create
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/image"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	name := ""
+	if len(args) > 1 {
+		name = args[1]
+	}
+	snapshot, err := image.CreateSnapshot(args[0], name, nil)
+	if err != nil {
+		return fmt.Errorf("could not create snapshot of image %s: %w", args[0], err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), snapshot.Name)
+	return nil
+}
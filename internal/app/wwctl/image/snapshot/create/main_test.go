@@ -0,0 +1,41 @@
+package create
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Create(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/var/lib/warewulf/chroots/test/rootfs/etc/os-release", "v1")
+
+	cmd := GetCommand()
+	cmd.SetArgs([]string{"test", "before-update"})
+	out := bytes.NewBufferString("")
+	cmd.SetOut(out)
+	assert.NoError(t, cmd.Execute())
+	assert.Equal(t, "before-update\n", out.String())
+	assert.FileExists(t, env.GetPath("/var/lib/warewulf/chroots/test/snapshots/before-update/rootfs/etc/os-release"))
+
+	cmd = GetCommand()
+	cmd.SetArgs([]string{"test"})
+	out.Reset()
+	cmd.SetOut(out)
+	assert.NoError(t, cmd.Execute())
+	snapshots, err := image.ListSnapshots("test")
+	assert.NoError(t, err)
+	assert.Len(t, snapshots, 2)
+
+	cmd = GetCommand()
+	cmd.SetArgs([]string{"test", "before-update"})
+	assert.ErrorContains(t, cmd.Execute(), "already exists")
+
+	cmd = GetCommand()
+	cmd.SetArgs([]string{"unknown"})
+	assert.ErrorContains(t, cmd.Execute(), "image does not exist: unknown")
+}
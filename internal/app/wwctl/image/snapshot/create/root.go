@@ -0,0 +1,26 @@
+package create
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+func GetCommand() *cobra.Command {
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "create IMAGE [SNAPSHOT]",
+		Short:                 "Create a snapshot of an image",
+		Long: "This command copies the rootfs of IMAGE to a new SNAPSHOT. If SNAPSHOT\n" +
+			"is not given, the snapshot is named after the current time.",
+		Example: "  wwctl image snapshot create rockylinux-9 before-update",
+		RunE:    CobraRunE,
+		Args:    cobra.RangeArgs(1, 2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return completions.Images(cmd, args, toComplete)
+			}
+			return completions.None(cmd, args, toComplete)
+		},
+	}
+	return baseCmd
+}
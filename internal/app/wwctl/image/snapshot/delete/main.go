@@ -0,0 +1,24 @@
+package delete
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+)
+
+func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		name, snapshots := args[0], args[1:]
+		if !vars.yes && !util.Confirm(fmt.Sprintf("Are you sure you want to delete snapshots %s of image %s", snapshots, name)) {
+			return nil
+		}
+		for _, snapshot := range snapshots {
+			if err := image.DeleteSnapshot(name, snapshot); err != nil {
+				return fmt.Errorf("could not delete snapshot: %w", err)
+			}
+		}
+		return nil
+	}
+}
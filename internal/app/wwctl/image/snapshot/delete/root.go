@@ -0,0 +1,26 @@
+package delete
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+type variables struct {
+	yes bool
+}
+
+func GetCommand() *cobra.Command {
+	vars := variables{}
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "delete [OPTIONS] IMAGE SNAPSHOT [...]",
+		Aliases:               []string{"rm", "remove", "del"},
+		Short:                 "Delete snapshots of an image",
+		Long:                  "This command deletes the SNAPSHOTs of IMAGE.",
+		RunE:                  CobraRunE(&vars),
+		Args:                  cobra.MinimumNArgs(2),
+		ValidArgsFunction:     completions.ImageSnapshots,
+	}
+	baseCmd.PersistentFlags().BoolVarP(&vars.yes, "yes", "y", false, "Set 'yes' to all questions asked")
+	return baseCmd
+}
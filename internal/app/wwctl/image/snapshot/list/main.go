@@ -0,0 +1,30 @@
+package list
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/image"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	snapshots, err := image.ListSnapshots(args[0])
+	if err != nil {
+		return fmt.Errorf("could not list snapshots of image %s: %w", args[0], err)
+	}
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("SNAPSHOT", "CREATED", "USER", "COMMAND")
+	for _, snapshot := range snapshots {
+		t.AddLine(table.Prep([]string{
+			snapshot.Name,
+			snapshot.Created.Local().Format(time.DateTime),
+			snapshot.User,
+			strings.Join(snapshot.Command, " "),
+		})...)
+	}
+	t.Print()
+	return nil
+}
@@ -0,0 +1,26 @@
+package list
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+func GetCommand() *cobra.Command {
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "list IMAGE",
+		Short:                 "List the snapshots of an image",
+		Long: "This command lists the snapshots of IMAGE, oldest first, along with the\n" +
+			"command which they were taken before by 'wwctl image exec --snapshot'.",
+		Aliases: []string{"ls"},
+		RunE:    CobraRunE,
+		Args:    cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return completions.Images(cmd, args, toComplete)
+			}
+			return completions.None(cmd, args, toComplete)
+		},
+	}
+	return baseCmd
+}
@@ -0,0 +1,29 @@
+package restore
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		name, snapshot := args[0], args[1]
+		if !vars.yes && !util.Confirm(fmt.Sprintf("Are you sure you want to restore image %s to snapshot %s", name, snapshot)) {
+			return nil
+		}
+		if err := image.RestoreSnapshot(name, snapshot); err != nil {
+			return fmt.Errorf("could not restore image %s: %w", name, err)
+		}
+		if vars.build {
+			wwlog.Info("Building image: %s", name)
+			if err := image.Build(name, true); err != nil {
+				return fmt.Errorf("could not build image %s: %w", name, err)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,29 @@
+package restore
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Restore(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/var/lib/warewulf/chroots/test/rootfs/etc/os-release", "v1")
+	_, err := image.CreateSnapshot("test", "before-update", nil)
+	assert.NoError(t, err)
+	env.WriteFile("/var/lib/warewulf/chroots/test/rootfs/etc/os-release", "v2")
+
+	cmd := GetCommand()
+	cmd.SetArgs([]string{"--yes", "test", "before-update"})
+	assert.NoError(t, cmd.Execute())
+	content, _ := os.ReadFile(env.GetPath("/var/lib/warewulf/chroots/test/rootfs/etc/os-release"))
+	assert.Equal(t, "v1", string(content))
+
+	cmd = GetCommand()
+	cmd.SetArgs([]string{"--yes", "test", "unknown"})
+	assert.ErrorContains(t, cmd.Execute(), "snapshot unknown of image test does not exist")
+}
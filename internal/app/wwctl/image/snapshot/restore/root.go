@@ -0,0 +1,29 @@
+package restore
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+type variables struct {
+	build bool
+	yes   bool
+}
+
+func GetCommand() *cobra.Command {
+	vars := variables{}
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "restore [OPTIONS] IMAGE SNAPSHOT",
+		Short:                 "Restore an image to a snapshot",
+		Long: "This command replaces the rootfs of IMAGE with SNAPSHOT. Changes made to\n" +
+			"the image since the snapshot was taken are lost. The snapshot is kept.",
+		Example:           "  wwctl image snapshot restore --build rockylinux-9 before-update",
+		RunE:              CobraRunE(&vars),
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completions.ImageSnapshots,
+	}
+	baseCmd.PersistentFlags().BoolVarP(&vars.build, "build", "b", false, "Build image after restoring")
+	baseCmd.PersistentFlags().BoolVarP(&vars.yes, "yes", "y", false, "Set 'yes' to all questions asked")
+	return baseCmd
+}
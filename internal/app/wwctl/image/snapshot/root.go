@@ -0,0 +1,32 @@
+package snapshot
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/image/snapshot/create"
+	"github.com/warewulf/warewulf/internal/app/wwctl/image/snapshot/delete"
+	"github.com/warewulf/warewulf/internal/app/wwctl/image/snapshot/list"
+	"github.com/warewulf/warewulf/internal/app/wwctl/image/snapshot/restore"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "snapshot COMMAND [OPTIONS]",
+		Short:                 "Image snapshot management",
+		Long: "Manage snapshots of the rootfs of an image, which the image can be\n" +
+			"restored to, e.g. after a failed update with 'wwctl image exec'.",
+		Args: cobra.NoArgs,
+	}
+)
+
+func init() {
+	baseCmd.AddCommand(create.GetCommand())
+	baseCmd.AddCommand(list.GetCommand())
+	baseCmd.AddCommand(restore.GetCommand())
+	baseCmd.AddCommand(delete.GetCommand())
+}
+
+// GetCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -0,0 +1,26 @@
+package add
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		registry, err := node.New()
+		if err != nil {
+			return fmt.Errorf("could not read node configuration: %w", err)
+		}
+		if _, err := registry.AddNetwork(args[0], vars.network); err != nil {
+			return err
+		}
+		if err := registry.Persist(); err != nil {
+			return fmt.Errorf("failed to persist nodedb: %w", err)
+		}
+		wwlog.Info("Added network: %s", args[0])
+		return nil
+	}
+}
@@ -0,0 +1,36 @@
+package add
+
+import (
+	"net"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+type variables struct {
+	network node.Network
+}
+
+func GetCommand() *cobra.Command {
+	vars := variables{}
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "add [OPTIONS] NETWORK",
+		Short:                 "Add a network",
+		Long: "This command adds NETWORK, from which the addresses of network devices which\n" +
+			"reference it are allocated. The netmask, gateway, and MTU of the network are\n" +
+			"used for network devices which don't set them.",
+		Example: "wwctl network add cluster --cidr 10.0.0.0/16 --gateway 10.0.0.1 --reserved 10.0.0.2-10.0.0.99",
+		RunE:    CobraRunE(&vars),
+		Args:    cobra.ExactArgs(1),
+	}
+	baseCmd.Flags().StringVar(&vars.network.CIDR, "cidr", "", "IPv4 network of the addresses (e.g., 10.0.0.0/16)")
+	baseCmd.Flags().IPVar(&vars.network.Gateway, "gateway", net.IP(nil), "gateway of the network, which is never allocated")
+	baseCmd.Flags().StringSliceVar(&vars.network.Reserved, "reserved", nil, "addresses and ranges (FIRST-LAST) which are never allocated")
+	baseCmd.Flags().StringVar(&vars.network.MTU, "mtu", "", "MTU of the network")
+	baseCmd.Flags().StringVar(&vars.network.Comment, "comment", "", "comment")
+	_ = baseCmd.MarkFlagRequired("cidr")
+	flags.AddStrict(baseCmd)
+	return baseCmd
+}
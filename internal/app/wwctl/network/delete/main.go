@@ -0,0 +1,36 @@
+package delete
+
+import (
+	"fmt"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		registry, err := node.New()
+		if err != nil {
+			return fmt.Errorf("could not read node configuration: %w", err)
+		}
+		for _, id := range args {
+			if err := registry.DelNetwork(id); err != nil {
+				return err
+			}
+		}
+		if !vars.yes {
+			prompt := promptui.Prompt{
+				Label:     fmt.Sprintf("Are you sure you want to delete %d network(s)", len(args)),
+				IsConfirm: true,
+			}
+			if result, _ := prompt.Run(); result != "y" && result != "yes" {
+				return nil
+			}
+		}
+		if err := registry.Persist(); err != nil {
+			return fmt.Errorf("failed to persist nodedb: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,29 @@
+package delete
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
+)
+
+type variables struct {
+	yes bool
+}
+
+func GetCommand() *cobra.Command {
+	vars := variables{}
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "delete [OPTIONS] NETWORK ...",
+		Short:                 "Delete networks",
+		Long: "This command deletes the given networks. Addresses which were allocated from\n" +
+			"them are kept.",
+		Aliases:           []string{"remove", "rm", "del"},
+		RunE:              CobraRunE(&vars),
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completions.Networks,
+	}
+	baseCmd.Flags().BoolVarP(&vars.yes, "yes", "y", false, "Set 'yes' to all questions asked")
+	flags.AddStrict(baseCmd)
+	return baseCmd
+}
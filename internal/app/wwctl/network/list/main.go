@@ -0,0 +1,71 @@
+package list
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	registry, err := node.New()
+	if err != nil {
+		return fmt.Errorf("could not read node configuration: %w", err)
+	}
+	var ids []string
+	for id := range registry.Networks {
+		if len(args) == 0 || util.InSlice(args, id) {
+			ids = append(ids, id)
+		}
+	}
+	for _, arg := range args {
+		if _, ok := registry.Networks[arg]; !ok {
+			wwlog.Warn("Network not found: %s", arg)
+		}
+	}
+	sort.Strings(ids)
+
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("NETWORK", "CIDR", "GATEWAY", "MTU", "USED", "FREE", "UTILIZATION")
+	for _, id := range ids {
+		network := registry.Networks[id]
+		addresses, err := registry.NetworkAddresses(id)
+		if err != nil {
+			return err
+		}
+		used, free := Utilization(network, addresses)
+		utilization := "--"
+		if size := network.Size(); size > 0 {
+			utilization = fmt.Sprintf("%d%%", 100*used/size)
+		}
+		t.AddLine(id, network.CIDR, orDashes(network.Gateway.String(), network.Gateway == nil),
+			orDashes(network.MTU, network.MTU == ""), used, free, utilization)
+	}
+	t.Print()
+	return nil
+}
+
+// Utilization returns the number of allocatable addresses of network
+// which are used and free. Addresses which are reserved, or used more
+// than once, are only counted once.
+func Utilization(network *node.Network, addresses []node.NetworkAddress) (used int, free int) {
+	seen := make(map[string]bool)
+	for _, address := range addresses {
+		if !network.IsReserved(address.Ipaddr) && !seen[address.Ipaddr.String()] {
+			seen[address.Ipaddr.String()] = true
+			used++
+		}
+	}
+	return used, network.Size() - used
+}
+
+func orDashes(value string, unset bool) string {
+	if unset {
+		return "--"
+	}
+	return value
+}
@@ -0,0 +1,86 @@
+package list
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+const nodesConf = `
+networks:
+  cluster:
+    cidr: 10.0.0.0/28
+    gateway: 10.0.0.1
+    reserved:
+    - 10.0.0.2-10.0.0.5
+  ipmi:
+    cidr: 10.0.1.0/24
+    mtu: "1500"
+nodeprofiles:
+  default:
+    network devices:
+      default:
+        device: eth0
+        network: cluster
+nodes:
+  n1:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 10.0.0.6
+  n2:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 10.0.0.7
+  n3:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 10.0.0.7`
+
+func Test_List(t *testing.T) {
+	var tests = map[string]struct {
+		args   []string
+		output string
+	}{
+		"all": {
+			output: `
+NETWORK  CIDR         GATEWAY   MTU   USED  FREE  UTILIZATION
+-------  ----         -------   ---   ----  ----  -----------
+cluster  10.0.0.0/28  10.0.0.1  --    2     7     22%
+ipmi     10.0.1.0/24  --        1500  0     254   0%
+`,
+		},
+		"one": {
+			args: []string{"ipmi"},
+			output: `
+NETWORK  CIDR         GATEWAY  MTU   USED  FREE  UTILIZATION
+-------  ----         -------  ---   ----  ----  -----------
+ipmi     10.0.1.0/24  --       1500  0     254   0%
+`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			env := testenv.New(t)
+			defer env.RemoveAll()
+			env.WriteFile("etc/warewulf/nodes.conf", nodesConf)
+
+			cmd := GetCommand()
+			cmd.SetArgs(tt.args)
+			buf := new(bytes.Buffer)
+			cmd.SetOut(buf)
+			cmd.SetErr(buf)
+			assert.NoError(t, cmd.Execute())
+			assert.Equal(t, tt.output[1:], buf.String())
+		})
+	}
+}
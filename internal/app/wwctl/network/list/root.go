@@ -0,0 +1,21 @@
+package list
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+func GetCommand() *cobra.Command {
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "list [NETWORK ...]",
+		Short:                 "List networks",
+		Long: "This command lists the networks with the number of addresses used by network\n" +
+			"devices and the number of addresses which are still free.",
+		Aliases:           []string{"ls"},
+		RunE:              CobraRunE,
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completions.Networks,
+	}
+	return baseCmd
+}
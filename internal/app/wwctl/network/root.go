@@ -0,0 +1,34 @@
+package network
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/network/add"
+	"github.com/warewulf/warewulf/internal/app/wwctl/network/delete"
+	"github.com/warewulf/warewulf/internal/app/wwctl/network/list"
+	"github.com/warewulf/warewulf/internal/app/wwctl/network/show"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "network COMMAND [OPTIONS]",
+		Short:                 "Network management",
+		Long: "Management of the networks from which node addresses are allocated. Network\n" +
+			"devices reference a network with --network; a node added or discovered without\n" +
+			"an address gets the next free address of the network.",
+		Aliases: []string{"net"},
+		Args:    cobra.NoArgs,
+	}
+)
+
+func init() {
+	baseCmd.AddCommand(add.GetCommand())
+	baseCmd.AddCommand(delete.GetCommand())
+	baseCmd.AddCommand(list.GetCommand())
+	baseCmd.AddCommand(show.GetCommand())
+}
+
+// GetCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
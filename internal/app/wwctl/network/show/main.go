@@ -0,0 +1,76 @@
+package show
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/network/list"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	registry, err := node.New()
+	if err != nil {
+		return fmt.Errorf("could not read node configuration: %w", err)
+	}
+	network, err := registry.GetNetwork(args[0])
+	if err != nil {
+		return fmt.Errorf("network does not exist: %s", args[0])
+	}
+	addresses, err := registry.NetworkAddresses(args[0])
+	if err != nil {
+		return err
+	}
+	used, free := list.Utilization(network, addresses)
+
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("FIELD", "VALUE")
+	t.AddLine("Network", args[0])
+	t.AddLine("CIDR", network.CIDR)
+	if network.Netmask() != nil {
+		t.AddLine("Netmask", network.Netmask())
+	}
+	if network.Gateway != nil {
+		t.AddLine("Gateway", network.Gateway)
+	}
+	if network.MTU != "" {
+		t.AddLine("MTU", network.MTU)
+	}
+	if len(network.Reserved) > 0 {
+		t.AddLine("Reserved", strings.Join(network.Reserved, ","))
+	}
+	if network.Comment != "" {
+		t.AddLine("Comment", network.Comment)
+	}
+	t.AddLine("Used", used)
+	t.AddLine("Free", free)
+	t.Print()
+
+	if len(addresses) > 0 {
+		fmt.Fprintln(cmd.OutOrStdout())
+		t := table.New(cmd.OutOrStdout())
+		t.AddHeader("IPADDR", "NODE", "NETDEV", "NOTE")
+		used := make(map[string]int)
+		for _, address := range addresses {
+			used[address.Ipaddr.String()]++
+		}
+		for _, address := range addresses {
+			var notes []string
+			if network.IsReserved(address.Ipaddr) {
+				notes = append(notes, "reserved")
+			}
+			if used[address.Ipaddr.String()] > 1 {
+				notes = append(notes, "conflict")
+			}
+			note := "--"
+			if len(notes) > 0 {
+				note = strings.Join(notes, ",")
+			}
+			t.AddLine(address.Ipaddr, address.Node, address.NetDev, note)
+		}
+		t.Print()
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package show
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Show(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+networks:
+  cluster:
+    cidr: 10.0.0.0/28
+    gateway: 10.0.0.1
+    reserved:
+    - 10.0.0.2-10.0.0.5
+nodeprofiles:
+  default:
+    network devices:
+      default:
+        device: eth0
+        network: cluster
+nodes:
+  n1:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 10.0.0.2
+  n2:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 10.0.0.7
+  n3:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 10.0.0.7`)
+
+	cmd := GetCommand()
+	cmd.SetArgs([]string{"cluster"})
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	assert.NoError(t, cmd.Execute())
+	assert.Equal(t, `FIELD     VALUE
+-----     -----
+Network   cluster
+CIDR      10.0.0.0/28
+Netmask   255.255.255.240
+Gateway   10.0.0.1
+Reserved  10.0.0.2-10.0.0.5
+Used      1
+Free      8
+
+IPADDR    NODE  NETDEV   NOTE
+------    ----  ------   ----
+10.0.0.2  n1    default  reserved
+10.0.0.7  n2    default  conflict
+10.0.0.7  n3    default  conflict
+`, buf.String())
+
+	cmd.SetArgs([]string{"missing"})
+	assert.EqualError(t, cmd.Execute(), "network does not exist: missing")
+}
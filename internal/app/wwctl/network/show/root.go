@@ -0,0 +1,20 @@
+package show
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+func GetCommand() *cobra.Command {
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "show NETWORK",
+		Short:                 "Show a network and its addresses",
+		Long: "This command shows the definition of NETWORK and the addresses which are used\n" +
+			"by network devices, with their node and network device.",
+		RunE:              CobraRunE,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completions.Networks,
+	}
+	return baseCmd
+}
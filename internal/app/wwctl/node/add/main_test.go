@@ -197,6 +197,77 @@ nodes:
       foo:
         ipaddr: 10.10.0.3
 `},
+		{name: "nodes add with addresses from network",
+			args:    []string{"n[01-02]"},
+			wantErr: false,
+			stdout:  "",
+			inDb: `
+networks:
+  cluster:
+    cidr: 10.0.0.0/24
+    gateway: 10.0.0.1
+nodeprofiles:
+  default:
+    network devices:
+      default:
+        device: eth0
+        network: cluster
+nodes:
+  n00:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 10.0.0.2`,
+			outDb: `
+networks:
+  cluster:
+    cidr: 10.0.0.0/24
+    gateway: 10.0.0.1
+nodeprofiles:
+  default:
+    network devices:
+      default:
+        device: eth0
+        network: cluster
+nodes:
+  n00:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 10.0.0.2
+  n01:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 10.0.0.3
+  n02:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 10.0.0.4`},
+		{name: "node add with address from network on the command line",
+			args:    []string{"--netname=default", "--network=cluster", "n01"},
+			wantErr: false,
+			stdout:  "",
+			inDb: `
+networks:
+  cluster:
+    cidr: 10.0.0.0/24`,
+			outDb: `
+networks:
+  cluster:
+    cidr: 10.0.0.0/24
+nodeprofiles: {}
+nodes:
+  n01:
+    network devices:
+      default:
+        ipaddr: 10.0.0.1
+        network: cluster`},
 		{name: "one node with filesystem",
 			args:    []string{"--fsname=/dev/vda1", "--fspath=/var", "n01"},
 			wantErr: false,
@@ -56,5 +56,6 @@ func GetCommand() *cobra.Command {
 	}
 
 	// GetRootCommand returns the root cobra.Command for the application.
+	flags.AddStrict(baseCmd)
 	return baseCmd
 }
@@ -3,6 +3,7 @@ package delete
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 )
 
 var (
@@ -24,6 +25,7 @@ func init() {
 	SetForce = true
 	baseCmd.PersistentFlags().BoolVarP(&SetYes, "yes", "y", false, "Set 'yes' to all questions asked")
 
+	flags.AddStrict(baseCmd)
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
@@ -0,0 +1,40 @@
+package accept
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/discovery"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	pending, err := discovery.Get(args[0])
+	if errors.Is(err, discovery.ErrNotFound) {
+		return fmt.Errorf("machine %s is not pending discovery", args[0])
+	} else if err != nil {
+		return fmt.Errorf("could not read the discovery queue: %w", err)
+	}
+	registry, err := node.New()
+	if err != nil {
+		return fmt.Errorf("could not read node configuration: %w", err)
+	}
+	if other, err := registry.FindByHwaddr(pending.Hwaddr); err == nil {
+		return fmt.Errorf("hardware address %s is already assigned to node %s", pending.Hwaddr, other.Id())
+	}
+	netdev, err := registry.DiscoverNode(args[1], NetName, pending.Hwaddr)
+	if err != nil {
+		return fmt.Errorf("could not accept %s as node %s: %w", pending.Hwaddr, args[1], err)
+	}
+	if err := registry.Persist(); err != nil {
+		return fmt.Errorf("failed to persist nodedb: %w", err)
+	}
+	if err := discovery.Remove(pending.Hwaddr); err != nil {
+		return fmt.Errorf("could not remove %s from the discovery queue: %w", pending.Hwaddr, err)
+	}
+	wwlog.Info("accepted %s as node %s (%s)", pending.Hwaddr, args[1], netdev)
+	return warewulfd.DaemonReload()
+}
@@ -0,0 +1,65 @@
+package accept
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/discovery"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+)
+
+func Test_Accept(t *testing.T) {
+	warewulfd.SetNoDaemon()
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodes:
+  n1:
+    discoverable: true
+    network devices:
+      default: {}
+  n2:
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:02`)
+	_, _, err := discovery.Record(node.Machine{Hwaddr: "00:00:00:00:00:01"}, "")
+	assert.NoError(t, err)
+	_, _, err = discovery.Record(node.Machine{Hwaddr: "00:00:00:00:00:02"}, "")
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		args []string
+		err  string
+	}{
+		"not pending":    {args: []string{"00:00:00:00:00:03", "n1"}, err: "machine 00:00:00:00:00:03 is not pending discovery"},
+		"assigned":       {args: []string{"00:00:00:00:00:02", "n1"}, err: "hardware address 00:00:00:00:00:02 is already assigned to node n2"},
+		"unknown node":   {args: []string{"00:00:00:00:00:01", "n3"}, err: "could not accept 00:00:00:00:00:01 as node n3: node/profile not found"},
+		"accept machine": {args: []string{"00:00:00:00:00:01", "n1"}},
+	}
+	for _, name := range []string{"not pending", "assigned", "unknown node", "accept machine"} {
+		tt := tests[name]
+		t.Run(name, func(t *testing.T) {
+			NetName = ""
+			cmd := GetCommand()
+			cmd.SetArgs(tt.args)
+			err := cmd.Execute()
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	registry, err := node.New()
+	assert.NoError(t, err)
+	n1, err := registry.GetNode("n1")
+	assert.NoError(t, err)
+	assert.Equal(t, "00:00:00:00:00:01", n1.NetDevs["default"].Hwaddr)
+	assert.False(t, n1.Discoverable.Bool())
+	_, err = discovery.Get("00:00:00:00:00:01")
+	assert.ErrorIs(t, err, discovery.ErrNotFound)
+}
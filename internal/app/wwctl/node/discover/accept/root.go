@@ -0,0 +1,38 @@
+package accept
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "accept [OPTIONS] HWADDR NODE",
+		Short:                 "Accept a pending machine as a node",
+		Long: "This command assigns the hardware address of the pending machine HWADDR to\n" +
+			"NODE, as if NODE had been discovered by it, and removes the machine from the\n" +
+			"discovery queue. The address is assigned to the primary network device of\n" +
+			"NODE, unless --netname is given.",
+		RunE: CobraRunE,
+		Args: cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 1 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completions.Nodes(cmd, args, toComplete)
+		},
+	}
+	NetName string
+)
+
+func init() {
+	baseCmd.PersistentFlags().StringVarP(&NetName, "netname", "n", "", "Network device to assign the hardware address to")
+	flags.AddStrict(baseCmd)
+}
+
+// GetCommand returns the accept command.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
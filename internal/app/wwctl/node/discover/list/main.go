@@ -0,0 +1,28 @@
+package list
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/discovery"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	pending, err := discovery.List()
+	if err != nil {
+		return fmt.Errorf("could not read the discovery queue: %w", err)
+	}
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("HWADDR", "UUID", "SERIAL", "ASSET TAG", "SWITCH PORT", "IPADDR", "LAST SEEN", "STATE")
+	for _, p := range pending {
+		state := "pending"
+		if p.Rejected {
+			state = "rejected"
+		}
+		t.AddLine(table.Prep([]string{p.Hwaddr, p.UUID, p.Serial, p.AssetTag, p.SwitchPort, p.Ipaddr, p.LastSeen.Local().Format(time.DateTime), state})...)
+	}
+	t.Print()
+	return nil
+}
@@ -0,0 +1,33 @@
+package list
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/discovery"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_List(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	_, _, err := discovery.Record(node.Machine{Hwaddr: "00:00:00:00:00:01", UUID: "uuid-1", Serial: "S1", SwitchPort: "sw1:Ethernet1/1"}, "10.0.0.1")
+	assert.NoError(t, err)
+	_, _, err = discovery.Record(node.Machine{Hwaddr: "00:00:00:00:00:02"}, "10.0.0.2")
+	assert.NoError(t, err)
+	assert.NoError(t, discovery.Reject("00:00:00:00:00:02"))
+
+	cmd := GetCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{})
+	assert.NoError(t, cmd.Execute())
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 4)
+	assert.Regexp(t, `^00:00:00:00:00:01\s+uuid-1\s+S1\s+--\s+sw1:Ethernet1/1\s+10.0.0.1\s+.*\s+pending$`, lines[2])
+	assert.Regexp(t, `^00:00:00:00:00:02\s+--\s+--\s+--\s+--\s+10.0.0.2\s+.*\s+rejected$`, lines[3])
+}
@@ -0,0 +1,21 @@
+package list
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+func GetCommand() *cobra.Command {
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "list",
+		Short:                 "List machines pending discovery",
+		Long: "This command lists the machines in the discovery queue, with what they\n" +
+			"reported: SMBIOS UUID, serial number, asset tag, and switch port.",
+		Aliases:           []string{"ls"},
+		RunE:              CobraRunE,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: completions.None,
+	}
+	return baseCmd
+}
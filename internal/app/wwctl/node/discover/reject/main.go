@@ -0,0 +1,26 @@
+package reject
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/discovery"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	for _, hwaddr := range args {
+		var err error
+		if Remove {
+			err = discovery.Remove(hwaddr)
+		} else {
+			err = discovery.Reject(hwaddr)
+		}
+		if errors.Is(err, discovery.ErrNotFound) {
+			return fmt.Errorf("machine %s is not pending discovery", hwaddr)
+		} else if err != nil {
+			return fmt.Errorf("could not update the discovery queue: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package reject
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/discovery"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Reject(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	_, _, err := discovery.Record(node.Machine{Hwaddr: "00:00:00:00:00:01"}, "")
+	assert.NoError(t, err)
+	_, _, err = discovery.Record(node.Machine{Hwaddr: "00:00:00:00:00:02"}, "")
+	assert.NoError(t, err)
+
+	Remove = false
+	cmd := GetCommand()
+	cmd.SetArgs([]string{"00:00:00:00:00:01"})
+	assert.NoError(t, cmd.Execute())
+	pending, err := discovery.Get("00:00:00:00:00:01")
+	assert.NoError(t, err)
+	assert.True(t, pending.Rejected)
+
+	cmd = GetCommand()
+	cmd.SetArgs([]string{"--remove", "00:00:00:00:00:01", "00:00:00:00:00:02"})
+	assert.NoError(t, cmd.Execute())
+	pending2, err := discovery.List()
+	assert.NoError(t, err)
+	assert.Empty(t, pending2)
+
+	Remove = false
+	cmd = GetCommand()
+	cmd.SetArgs([]string{"00:00:00:00:00:03"})
+	assert.EqualError(t, cmd.Execute(), "machine 00:00:00:00:00:03 is not pending discovery")
+}
@@ -0,0 +1,31 @@
+package reject
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "reject [OPTIONS] HWADDR ...",
+		Short:                 "Reject pending machines",
+		Long: "This command rejects the pending machines HWADDR, which are then not\n" +
+			"discovered as nodes, even if they match the discovery rules of a node.\n" +
+			"With --remove, the machines are removed from the queue instead, and are\n" +
+			"queued again when they next request to be provisioned.",
+		RunE:              CobraRunE,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completions.None,
+	}
+	Remove bool
+)
+
+func init() {
+	baseCmd.PersistentFlags().BoolVar(&Remove, "remove", false, "Remove the machines from the queue")
+}
+
+// GetCommand returns the reject command.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -0,0 +1,31 @@
+package discover
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/node/discover/accept"
+	"github.com/warewulf/warewulf/internal/app/wwctl/node/discover/list"
+	"github.com/warewulf/warewulf/internal/app/wwctl/node/discover/reject"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "discover COMMAND [OPTIONS]",
+		Short:                 "Manage machines pending discovery",
+		Long: "Unknown machines which do not match the discovery rules of a discoverable\n" +
+			"node are queued as pending. These commands list the queue, and accept a\n" +
+			"pending machine as a node or reject it.",
+		Args: cobra.NoArgs,
+	}
+)
+
+func init() {
+	baseCmd.AddCommand(list.GetCommand())
+	baseCmd.AddCommand(accept.GetCommand())
+	baseCmd.AddCommand(reject.GetCommand())
+}
+
+// GetCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -0,0 +1,60 @@
+package explain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	registry, err := node.New()
+	if err != nil {
+		return fmt.Errorf("could not read node configuration: %w", err)
+	}
+	fields, err := registry.ExplainNode(args[0])
+	if err != nil {
+		return fmt.Errorf("could not explain node %s: %w", args[0], err)
+	}
+	if len(args) > 1 {
+		fields = filterFields(fields, args[1])
+		if len(fields) == 0 {
+			return fmt.Errorf("node %s has no field %s", args[0], args[1])
+		}
+	}
+
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("FIELD", "SOURCE", "VALUE", "NOTE")
+	for _, field := range fields {
+		for _, source := range field.Chain {
+			note := "--"
+			if source.Overridden {
+				note = "overridden"
+			} else if len(source.Removed) > 0 {
+				note = "removed " + strings.Join(source.Removed, ",")
+			}
+			t.AddLine(field.Field, source.Source, source.Value, note)
+		}
+		value := field.Value
+		if value == "" {
+			value = "--"
+		}
+		t.AddLine(field.Field, "(merged)", value, "--")
+	}
+	t.Print()
+	return nil
+}
+
+// filterFields returns the fields named name, or below name, ignoring case.
+func filterFields(fields []node.Field, name string) (filtered []node.Field) {
+	name = strings.ToLower(name)
+	for _, field := range fields {
+		fieldName := strings.ToLower(field.Field)
+		if fieldName == name || strings.HasPrefix(fieldName, name+".") || strings.HasPrefix(fieldName, name+"[") {
+			filtered = append(filtered, field)
+		}
+	}
+	return filtered
+}
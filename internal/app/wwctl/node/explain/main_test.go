@@ -0,0 +1,109 @@
+package explain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Explain(t *testing.T) {
+	var tests = map[string]struct {
+		args   []string
+		err    string
+		stdout string
+	}{
+		"kernel args": {
+			args: []string{"n1", "kernel.args"},
+			stdout: `FIELD        SOURCE    VALUE                                   NOTE
+-----        ------    -----                                   ----
+Kernel.Args  default   quiet,crashkernel=no                    removed quiet
+Kernel.Args  gpu       ~quiet,nomodeset                        removed ~quiet
+Kernel.Args  n1        console=ttyS0                           --
+Kernel.Args  (merged)  crashkernel=no,nomodeset,console=ttyS0  --
+`,
+		},
+		"nested fields": {
+			args: []string{"n1", "Kernel"},
+			stdout: `FIELD           SOURCE    VALUE                                   NOTE
+-----           ------    -----                                   ----
+Kernel.Version  default   5.14                                    overridden
+Kernel.Version  n1        6.1                                     --
+Kernel.Version  (merged)  6.1                                     --
+Kernel.Args     default   quiet,crashkernel=no                    removed quiet
+Kernel.Args     gpu       ~quiet,nomodeset                        removed ~quiet
+Kernel.Args     n1        console=ttyS0                           --
+Kernel.Args     (merged)  crashkernel=no,nomodeset,console=ttyS0  --
+`,
+		},
+		"all negated": {
+			args: []string{"n2", "Kernel.Args"},
+			stdout: `FIELD        SOURCE    VALUE                   NOTE
+-----        ------    -----                   ----
+Kernel.Args  default   quiet,crashkernel=no    removed quiet,crashkernel=no
+Kernel.Args  n2        ~quiet,~crashkernel=no  removed ~quiet,~crashkernel=no
+Kernel.Args  (merged)  --                      --
+`,
+		},
+		"missing field": {
+			args: []string{"n1", "ImageName"},
+			err:  "node n1 has no field ImageName",
+		},
+		"missing node": {
+			args: []string{"n3"},
+			err:  "could not explain node n3: node/profile not found",
+		},
+	}
+
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default:
+    kernel:
+      version: "5.14"
+      args:
+      - quiet
+      - crashkernel=no
+  gpu:
+    profiles:
+    - default
+    kernel:
+      args:
+      - ~quiet
+      - nomodeset
+nodes:
+  n1:
+    profiles:
+    - gpu
+    kernel:
+      version: "6.1"
+      args:
+      - console=ttyS0
+  n2:
+    profiles:
+    - default
+    kernel:
+      args:
+      - ~quiet
+      - ~crashkernel=no`)
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := GetCommand()
+			cmd.SetArgs(tt.args)
+			buf := new(bytes.Buffer)
+			cmd.SetOut(buf)
+			cmd.SetErr(buf)
+			err := cmd.Execute()
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.stdout, buf.String())
+		})
+	}
+}
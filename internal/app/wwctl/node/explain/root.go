@@ -0,0 +1,28 @@
+package explain
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+func GetCommand() *cobra.Command {
+	baseCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "explain NODE [FIELD]",
+		Short:                 "Show where the fields of a node come from",
+		Long: "This command shows, for each field of NODE, the value set by each of its\n" +
+			"profiles (in merge order), by the node itself, and by its networks, and how\n" +
+			"they are merged: overridden values and list entries removed by a negation\n" +
+			"(~entry) are noted. FIELD limits the output to a field (e.g. Kernel.Args) or\n" +
+			"to the fields below it (e.g. NetDevs[default]).",
+		RunE: CobraRunE,
+		Args: cobra.RangeArgs(1, 2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completions.Nodes(cmd, args, toComplete)
+		},
+	}
+	return baseCmd
+}
@@ -1,6 +1,8 @@
 package export
 
 import (
+	"bytes"
+
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/node"
@@ -19,10 +21,23 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 		names = registry.ListAllNodes()
 	}
 	for _, name := range hostlist.Expand(names) {
-		if n, err := registry.GetNode(name); err == nil {
+		if ExportCSV {
+			// export the fields of the node itself, so that they can be imported again
+			if n, err := registry.GetNodeOnly(name); err == nil {
+				nodeMap[name] = &n
+			}
+		} else if n, err := registry.GetNode(name); err == nil {
 			nodeMap[name] = &n
 		}
 	}
+	if ExportCSV {
+		buf := new(bytes.Buffer)
+		if err := node.WriteNodesCSV(buf, nodeMap); err != nil {
+			return err
+		}
+		wwlog.Output("%s", buf.String())
+		return nil
+	}
 	y, err := util.EncodeYaml(nodeMap)
 	if err != nil {
 		return err
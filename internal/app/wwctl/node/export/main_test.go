@@ -0,0 +1,45 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func Test_Export_CSV(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default:
+    image name: rocky9
+nodes:
+  n1:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 10.0.0.1
+  n2:
+    profiles:
+    - default
+    tags:
+      rack: "1"`)
+
+	defer func() { ExportCSV = false }()
+	cmd := GetCommand()
+	cmd.SetArgs([]string{"--csv"})
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	wwlog.SetLogWriter(buf)
+	assert.NoError(t, cmd.Execute())
+	assert.Equal(t, `node,Profiles,NetDevs[default].Ipaddr,Tags[rack]
+n1,default,10.0.0.1,
+n2,default,,1
+`, buf.String())
+}
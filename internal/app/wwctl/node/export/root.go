@@ -9,15 +9,22 @@ var (
 	baseCmd = &cobra.Command{
 		DisableFlagsInUseLine: true,
 		Use:                   "export  NODENAME",
-		Short:                 "Export nodes as yaml to stdout",
-		Long:                  "This command exports the given nodes as yaml to stdout.",
-		RunE:                  CobraRunE,
-		ValidArgsFunction:     completions.Nodes,
-		Args:                  cobra.ArbitraryArgs,
+		Short:                 "Export nodes as yaml or csv to stdout",
+		Long: "This command exports the given nodes as yaml to stdout. With --csv, the fields\n" +
+			"which are set on the nodes themselves are exported as csv, with a column for\n" +
+			"each field, which can be imported with 'wwctl node import --csv'.",
+		RunE:              CobraRunE,
+		ValidArgsFunction: completions.Nodes,
+		Args:              cobra.ArbitraryArgs,
 	}
-	NoHeader bool
+	NoHeader  bool
+	ExportCSV bool
 )
 
+func init() {
+	baseCmd.PersistentFlags().BoolVarP(&ExportCSV, "csv", "c", false, "Export as CSV")
+}
+
 // GetRootCommand returns the root cobra.Command for the application.
 func GetCommand() *cobra.Command {
 	return baseCmd
@@ -2,83 +2,75 @@ package imprt
 
 import (
 	"bytes"
-	"encoding/csv"
 	"fmt"
-	"io"
 	"os"
 
 	"github.com/spf13/cobra"
-	apinode "github.com/warewulf/warewulf/internal/pkg/api/node"
-	"github.com/warewulf/warewulf/internal/pkg/api/routes/wwapiv1"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 	"gopkg.in/yaml.v3"
 )
 
 func CobraRunE(cmd *cobra.Command, args []string) error {
-	file, err := os.Open(args[0])
+	buffer, err := os.ReadFile(args[0])
 	if err != nil {
-		return fmt.Errorf("could not open file: %s", err)
+		return fmt.Errorf("could not read file: %w", err)
 	}
-	defer file.Close()
-
-	importMap := make(map[string]*node.Node)
-	buffer, err := io.ReadAll(file)
+	registry, err := node.New()
 	if err != nil {
-		return fmt.Errorf("could not read: %s", err)
+		return fmt.Errorf("could not open node configuration: %w", err)
 	}
-	if !ImportCSV {
-		err = yaml.Unmarshal(buffer, importMap)
-		if err == nil {
-			if setYes || util.Confirm(fmt.Sprintf("Are you sure you want to modify %d nodes", len(importMap))) {
-				err = apinode.NodeAddFromYaml(&wwapiv1.NodeYaml{NodeConfMapYaml: string(buffer)})
-				if err != nil {
-					return fmt.Errorf("got following problem when writing back yaml: %s", err)
-				}
-			}
-		} else {
-			return fmt.Errorf("could not parse import file: %s", err)
-		}
-	} else {
-		// reading from buffer is a bit overshot
-		csvReader := csv.NewReader(bytes.NewReader(buffer))
-		records, err := csvReader.ReadAll()
+
+	importMap := make(map[string]*node.Node)
+	if ImportCSV {
+		importMap, err = registry.ReadNodesCSV(bytes.NewReader(buffer))
 		if err != nil {
-			return fmt.Errorf("could not parse %s: %s", args[0], err)
+			return fmt.Errorf("could not parse %s: %w", args[0], err)
 		}
-		if len(records) < 1 || len(records[0]) < 1 {
-			return fmt.Errorf("did not find any data in %s", args[0])
+	} else if err = yaml.Unmarshal(buffer, importMap); err != nil {
+		return fmt.Errorf("could not parse import file: %w", err)
+	}
+
+	changes := registry.Import(importMap, Prune)
+	if len(changes) == 0 {
+		wwlog.Info("No changes to import")
+		return nil
+	}
+	counts := make(map[string]int)
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("NODE", "CHANGE", "FIELD", "OLD", "NEW")
+	for _, change := range changes {
+		counts[change.Action]++
+		if len(change.Fields) == 0 {
+			t.AddLine(change.Node, change.Action, "--", "--", "--")
 		}
-		if !(records[0][0] == "node" || records[0][0] == "nodename") {
-			Usage()
-			os.Exit(1)
+		for _, field := range change.Fields {
+			t.AddLine(change.Node, change.Action, field.Field, orNone(field.Old), orNone(field.New))
 		}
-		argsLen := len(records[0])
-		for i, line := range records[1:] {
-			if len(line) != argsLen {
-				return fmt.Errorf("wrong number of fields in lube %d", i+1)
-			}
-			for j := range line {
-				if j == 0 {
-					continue
-				}
-				if importMap[line[0]] == nil {
-					importMap[line[0]] = new(node.Node)
-				}
-			}
+	}
+	t.Print()
+	if DryRun {
+		return nil
+	}
+
+	if setYes || util.Confirm(fmt.Sprintf("Are you sure you want to add %d, update %d, and delete %d nodes",
+		counts[node.ImportAdd], counts[node.ImportUpdate], counts[node.ImportDelete])) {
+		if err := registry.Persist(); err != nil {
+			return fmt.Errorf("failed to persist nodedb: %w", err)
 		}
-		if setYes || util.Confirm(fmt.Sprintf("Are you sure you want to import %d nodes", len(importMap))) {
-			// create second buffer an marshall nodeMap to it
-			buffer, err = yaml.Marshal(importMap)
-			if err != nil {
-				return fmt.Errorf("got following problem when creating yaml: %s", err)
-			}
-			err = apinode.NodeAddFromYaml(&wwapiv1.NodeYaml{NodeConfMapYaml: string(buffer)})
-			if err != nil {
-				return fmt.Errorf("got following problem when writing back yaml: %s", err)
-			}
+		if err := warewulfd.DaemonReload(); err != nil {
+			return fmt.Errorf("failed to reload warewulfd: %w", err)
 		}
 	}
-
 	return nil
 }
+
+func orNone(value string) string {
+	if value == "" {
+		return "--"
+	}
+	return value
+}
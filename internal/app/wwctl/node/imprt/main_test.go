@@ -3,6 +3,7 @@ package imprt
 import (
 	"bytes"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,6 +18,7 @@ func Test_Node_Import(t *testing.T) {
 		wantErr    bool
 		inDB       string
 		outDB      string
+		stdout     string
 	}{
 		"import new node": {
 			args: []string{"importFile"},
@@ -46,6 +48,86 @@ nodes:
         hwaddr: c4:cb:e1:bb:dd:e9
         ipaddr: 192.168.1.10`,
 		},
+		"dry run": {
+			args: []string{"importFile", "--dry-run"},
+			importFile: `
+n1:
+  image name: rocky9`,
+			inDB: `
+nodes:
+  n1:
+    image name: rocky8`,
+			outDB: `
+nodes:
+  n1:
+    image name: rocky8`,
+			stdout: `NODE  CHANGE  FIELD      OLD     NEW
+----  ------  -----      ---     ---
+n1    update  ImageName  rocky8  rocky9`,
+		},
+		"import with prune": {
+			args: []string{"importFile", "--prune"},
+			importFile: `
+n1:
+  image name: rocky9
+n3: {}`,
+			inDB: `
+nodes:
+  n1:
+    image name: rocky8
+  n2:
+    image name: rocky8`,
+			outDB: `
+nodeprofiles: {}
+nodes:
+  n1:
+    image name: rocky9
+  n3: {}`,
+			stdout: `NODE  CHANGE  FIELD      OLD     NEW
+----  ------  -----      ---     ---
+n1    update  ImageName  rocky8  rocky9
+n2    delete  --         --      --
+n3    add     --         --      --`,
+		},
+		"import csv": {
+			args: []string{"importFile", "--csv"},
+			importFile: `node,ImageName,NetDevs[default].Ipaddr,Tags[rack]
+n1,rocky9,10.0.0.1,
+n2,rocky9,10.0.0.2,2`,
+			inDB: `
+nodes:
+  n1:
+    profiles:
+    - default
+    image name: rocky8
+    tags:
+      rack: "1"`,
+			outDB: `
+nodeprofiles: {}
+nodes:
+  n1:
+    profiles:
+    - default
+    image name: rocky9
+    network devices:
+      default:
+        ipaddr: 10.0.0.1
+  n2:
+    image name: rocky9
+    network devices:
+      default:
+        ipaddr: 10.0.0.2
+    tags:
+      rack: "2"`,
+		},
+		"import csv with unknown field": {
+			args: []string{"importFile", "--csv"},
+			importFile: `node,Color
+n1,red`,
+			wantErr: true,
+			inDB: `
+nodes: {}`,
+		},
 	}
 
 	for name, tt := range tests {
@@ -61,6 +143,7 @@ nodes:
 			env.WriteFile("./importFile", tt.importFile)
 			env.WriteFile("etc/warewulf/nodes.conf", tt.inDB)
 			warewulfd.SetNoDaemon()
+			ImportCSV, DryRun, Prune = false, false, false
 
 			baseCmd := GetCommand()
 			args := append(tt.args, "--yes")
@@ -75,6 +158,9 @@ nodes:
 				assert.NoError(t, err)
 				content := env.ReadFile("etc/warewulf/nodes.conf")
 				assert.YAMLEq(t, tt.outDB, content)
+				if tt.stdout != "" {
+					assert.Equal(t, tt.stdout, strings.TrimSpace(buf.String()))
+				}
 			}
 		})
 	}
@@ -1,22 +1,27 @@
 package imprt
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 )
 
 var (
 	baseCmd = &cobra.Command{
 		DisableFlagsInUseLine: true,
 		Use:                   "import [OPTIONS] FILE",
-		Short:                 "Import node(s) from yaml FILE",
-		Long:                  "This command imports all the nodes defined in a file. It will overwrite nodes with same name.",
-		RunE:                  CobraRunE,
-		Args:                  cobra.ExactArgs(1),
-		Aliases:               []string{"import"},
+		Short:                 "Import node(s) from yaml or csv FILE",
+		Long: "This command imports all the nodes defined in a file, and shows the changed\n" +
+			"fields of each node. Nodes which don't exist are added, and the configuration\n" +
+			"of existing nodes is replaced. A CSV file has the node name in its first\n" +
+			"column and a field name (see 'wwctl node list --all') in each other column,\n" +
+			"e.g. node,ImageName,NetDevs[default].Ipaddr; only these fields are changed.",
+		RunE:    CobraRunE,
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"import"},
 	}
 	ImportCSV bool
+	DryRun    bool
+	Prune     bool
 	setYes    bool
 )
 
@@ -27,16 +32,13 @@ func init() {
 	if err := baseCmd.Flags().MarkDeprecated("cvs", "use --csv instead"); err != nil {
 		panic(err)
 	}
+	baseCmd.PersistentFlags().BoolVarP(&DryRun, "dry-run", "n", false, "Show the changes without importing them")
+	baseCmd.PersistentFlags().BoolVar(&Prune, "prune", false, "Delete nodes which are not in FILE")
 	baseCmd.PersistentFlags().BoolVarP(&setYes, "yes", "y", false, "Set 'yes' to all questions asked")
+	flags.AddStrict(baseCmd)
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
 func GetCommand() *cobra.Command {
 	return baseCmd
 }
-
-func Usage() {
-	fmt.Println(`The csv file must be structured in following way:
-node,option1,option2,net.netname1.netopt
-node01,value1,value2,net.netname1,netvalue`)
-}
@@ -0,0 +1,71 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/hostlist"
+	"github.com/warewulf/warewulf/internal/pkg/inventory"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	registry, err := node.New()
+	if err != nil {
+		return fmt.Errorf("could not read node configuration: %w", err)
+	}
+	nodes, err := registry.FindAllNodes()
+	if err != nil {
+		return err
+	}
+	nodes = node.FilterNodeListByName(nodes, hostlist.Expand(args))
+
+	var records []*inventory.Record
+	for _, n := range nodes {
+		record, err := inventory.Read(n.Id())
+		if errors.Is(err, inventory.ErrNotFound) {
+			wwlog.Verbose("no inventory for node %s", n.Id())
+			continue
+		} else if err != nil {
+			return fmt.Errorf("could not read the inventory of node %s: %w", n.Id(), err)
+		}
+		records = append(records, record)
+	}
+
+	if ShowJson {
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	t := table.New(cmd.OutOrStdout())
+	if ShowDiff {
+		t.AddHeader("NODE", "FIELD", "OLD", "NEW")
+		for _, record := range records {
+			if record.Previous == nil {
+				continue
+			}
+			for _, change := range inventory.Diff(record.Previous, record.Current) {
+				t.AddLine(table.Prep([]string{record.Node, change.Field, change.Old, change.New})...)
+			}
+		}
+	} else {
+		t.AddHeader("NODE", "FIELD", "VALUE")
+		for _, record := range records {
+			t.AddLine(record.Node, "Collected", record.Current.Collected.Format(time.RFC3339))
+			for _, item := range record.Current.Items() {
+				t.AddLine(record.Node, item.Field, item.Value)
+			}
+		}
+	}
+	t.Print()
+	return nil
+}
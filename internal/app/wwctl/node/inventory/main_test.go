@@ -0,0 +1,81 @@
+package inventory
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/inventory"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Inventory(t *testing.T) {
+	var tests = map[string]struct {
+		args   []string
+		diff   bool
+		stdout string
+	}{
+		"all nodes": {
+			args: []string{},
+			stdout: `NODE  FIELD                 VALUE
+----  -----                 -----
+n1    Collected             2024-01-01T00:00:00Z
+n1    CPU.Model             Xeon
+n1    Disks[sda].Serial     S2
+n2    Collected             2024-01-01T00:00:00Z
+n2    CPU.Model             EPYC
+n2    NICs[eth0].Hwaddr     00:00:00:00:00:02
+n2    NICs[eth0].SpeedMbps  25000
+`,
+		},
+		"one node": {
+			args: []string{"n2"},
+			stdout: `NODE  FIELD                 VALUE
+----  -----                 -----
+n2    Collected             2024-01-01T00:00:00Z
+n2    CPU.Model             EPYC
+n2    NICs[eth0].Hwaddr     00:00:00:00:00:02
+n2    NICs[eth0].SpeedMbps  25000
+`,
+		},
+		"diff": {
+			args: []string{"n[1-3]"},
+			diff: true,
+			stdout: `NODE  FIELD              OLD  NEW
+----  -----              ---  ---
+n1    Disks[sda].Serial  S1   S2
+`,
+		},
+	}
+
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodes:
+  n1: {}
+  n2: {}
+  n3: {}`)
+	collected := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := inventory.Update("n1", &inventory.Inventory{Collected: collected, CPU: inventory.CPU{Model: "Xeon"}, Disks: []inventory.Disk{{Name: "sda", Serial: "S1"}}})
+	assert.NoError(t, err)
+	_, err = inventory.Update("n1", &inventory.Inventory{Collected: collected, CPU: inventory.CPU{Model: "Xeon"}, Disks: []inventory.Disk{{Name: "sda", Serial: "S2"}}})
+	assert.NoError(t, err)
+	_, err = inventory.Update("n2", &inventory.Inventory{Collected: collected, CPU: inventory.CPU{Model: "EPYC"}, NICs: []inventory.NIC{{Name: "eth0", Hwaddr: "00:00:00:00:00:02", SpeedMbps: 25000}}})
+	assert.NoError(t, err)
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ShowDiff = tt.diff
+			ShowJson = false
+			cmd := GetCommand()
+			cmd.SetArgs(tt.args)
+			buf := new(bytes.Buffer)
+			cmd.SetOut(buf)
+			cmd.SetErr(buf)
+			assert.NoError(t, cmd.Execute())
+			assert.Equal(t, tt.stdout, buf.String())
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package inventory
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "inventory [OPTIONS] [NODENAME...]",
+		Short:                 "Show the hardware inventory of nodes",
+		Long: "This command shows the hardware inventory which wwclient reported for each\n" +
+			"node: system and firmware versions, CPUs, memory and DIMMs, network\n" +
+			"interfaces, disks, and PCI devices. When the hardware of a node changes,\n" +
+			"its previous inventory is kept, and --diff shows what changed.",
+		RunE:              CobraRunE,
+		ValidArgsFunction: completions.Nodes,
+		Args:              cobra.ArbitraryArgs,
+	}
+	ShowDiff bool
+	ShowJson bool
+)
+
+func init() {
+	baseCmd.PersistentFlags().BoolVar(&ShowDiff, "diff", false, "Show the changes since the previous inventory")
+	baseCmd.PersistentFlags().BoolVarP(&ShowJson, "json", "j", false, "Show the inventory in JSON format")
+}
+
+// GetCommand returns the inventory command.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/warewulf/warewulf/internal/app/wwctl/selector"
 	"github.com/warewulf/warewulf/internal/app/wwctl/table"
 	apinode "github.com/warewulf/warewulf/internal/pkg/api/node"
 	"github.com/warewulf/warewulf/internal/pkg/api/routes/wwapiv1"
@@ -30,7 +31,15 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 		} else if vars.showJson {
 			req.Type = wwapiv1.GetNodeList_JSON
 		}
-		nodeInfo, err := apinode.NodeList(&req)
+		nodeSelector, err := selector.Parse(vars.selector)
+		if err != nil {
+			return err
+		}
+		state, err := selector.State(nodeSelector)
+		if err != nil {
+			return err
+		}
+		nodeInfo, err := apinode.NodeList(&req, nodeSelector, state)
 
 		if len(nodeInfo.Output) > 0 {
 			if req.Type == wwapiv1.GetNodeList_YAML || req.Type == wwapiv1.GetNodeList_JSON {
@@ -127,6 +127,55 @@ nodes:
   n02:
    profiles:
    - default
+`,
+		},
+		{
+			name:    "node list with selector",
+			args:    []string{"--select", "tag.gpu=a100 && profile=gpu"},
+			wantErr: false,
+			stdout: `
+NODE NAME  PROFILES  NETWORK
+---------  --------  -------
+n02        gpu       --
+`,
+			inDb: `nodeprofiles:
+  default: {}
+  gpu:
+    tags:
+      gpu: a100
+nodes:
+  n01:
+    profiles:
+    - default
+  n02:
+    profiles:
+    - gpu
+  n03:
+    profiles:
+    - gpu
+    tags:
+      gpu: h100
+`,
+		},
+		{
+			name:    "node list with pattern and selector",
+			args:    []string{"--select", "profile=gpu", "n0[1-2]"},
+			wantErr: false,
+			stdout: `
+NODE NAME  PROFILES  NETWORK
+---------  --------  -------
+n02        gpu       --
+`,
+			inDb: `nodeprofiles:
+  gpu: {}
+nodes:
+  n01: {}
+  n02:
+    profiles:
+    - gpu
+  n03:
+    profiles:
+    - gpu
 `,
 		},
 		{
@@ -3,6 +3,7 @@ package list
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/selector"
 )
 
 type variables struct {
@@ -12,6 +13,7 @@ type variables struct {
 	showLong bool
 	showYaml bool
 	showJson bool
+	selector string
 }
 
 func GetCommand() *cobra.Command {
@@ -21,7 +23,7 @@ func GetCommand() *cobra.Command {
 		Use:                   "list [OPTIONS] [PATTERN]",
 		Short:                 "List nodes",
 		Long: "This command lists all configured nodes. Optionally, it will list only\n" +
-			"nodes matching a PATTERN.",
+			"nodes matching a PATTERN, or a selector expression given with --select.",
 		RunE:              CobraRunE(&vars),
 		Aliases:           []string{"ls"},
 		ValidArgsFunction: completions.Nodes,
@@ -33,6 +35,7 @@ func GetCommand() *cobra.Command {
 	baseCmd.PersistentFlags().BoolVarP(&vars.showLong, "long", "l", false, "Show long or wide format")
 	baseCmd.PersistentFlags().BoolVarP(&vars.showYaml, "yaml", "y", false, "Show yaml format")
 	baseCmd.PersistentFlags().BoolVarP(&vars.showJson, "json", "j", false, "Show json format")
+	selector.AddFlag(baseCmd, &vars.selector)
 
 	return baseCmd
 }
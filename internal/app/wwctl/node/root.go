@@ -5,9 +5,12 @@ import (
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/add"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/console"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/delete"
+	"github.com/warewulf/warewulf/internal/app/wwctl/node/discover"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/edit"
+	"github.com/warewulf/warewulf/internal/app/wwctl/node/explain"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/export"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/imprt"
+	"github.com/warewulf/warewulf/internal/app/wwctl/node/inventory"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/list"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/sensors"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/set"
@@ -38,6 +41,9 @@ func init() {
 	baseCmd.AddCommand(edit.GetCommand())
 	baseCmd.AddCommand(imprt.GetCommand())
 	baseCmd.AddCommand(export.GetCommand())
+	baseCmd.AddCommand(explain.GetCommand())
+	baseCmd.AddCommand(inventory.GetCommand())
+	baseCmd.AddCommand(discover.GetCommand())
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
@@ -3,10 +3,9 @@ package sensors
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/warewulf/warewulf/internal/pkg/batch"
+	"github.com/warewulf/warewulf/internal/app/wwctl/bmcoutput"
 	"github.com/warewulf/warewulf/internal/pkg/bmc"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/node"
@@ -15,8 +14,6 @@ import (
 
 func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err error) {
 	return func(cmd *cobra.Command, args []string) error {
-		var returnErr error = nil
-
 		nodeDB, err := node.New()
 		if err != nil {
 			return fmt.Errorf("could not open node configuration: %s", err)
@@ -42,52 +39,11 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 			os.Exit(1)
 		}
 
-		batchpool := batch.New(50)
-		jobcount := len(nodes)
-		results := make(chan bmc.TemplateStruct, jobcount)
-
-		for _, node := range nodes {
-			if node.Ipmi == nil || node.Ipmi.Ipaddr == nil || node.Ipmi.Ipaddr.IsUnspecified() {
-				wwlog.Error("%s: No IPMI IP address", node.Id())
-				continue
-			}
-			ipmiCmd := bmc.TemplateStruct{
-				IpmiConf: *node.Ipmi,
-				ShowOnly: vars.Showcmd,
-			}
-			batchpool.Submit(func() {
-				if vars.Full {
-					//nolint:errcheck
-					ipmiCmd.SensorList()
-				} else {
-					//nolint:errcheck
-					ipmiCmd.SDRList()
-				}
-				results <- ipmiCmd
-			})
-
-		}
-
-		batchpool.Run()
-
-		close(results)
-
-		for result := range results {
-			out, err := result.Result()
-
-			if err != nil {
-				for _, line := range strings.Split(out, "\n") {
-					wwlog.Error("%s: %s", result.Ipaddr, line)
-				}
-				returnErr = err
-				continue
-			}
-
-			for _, line := range strings.Split(out, "\n") {
-				wwlog.Info("%s: %s", result.Ipaddr, line)
-			}
+		ipmiCmd := "SDRList"
+		if vars.Full {
+			ipmiCmd = "SensorList"
 		}
-
-		return returnErr
+		results := bmc.RunCommand(nodes, ipmiCmd, vars.Fanout, vars.Showcmd)
+		return bmcoutput.Print(cmd, vars.Output, results)
 	}
 }
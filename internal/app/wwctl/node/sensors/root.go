@@ -2,6 +2,7 @@ package sensors
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/bmcoutput"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
 )
 
@@ -9,6 +10,7 @@ type variables struct {
 	Showcmd bool
 	Full    bool
 	Fanout  int
+	Output  bmcoutput.Format
 }
 
 func GetCommand() *cobra.Command {
@@ -26,5 +28,6 @@ func GetCommand() *cobra.Command {
 	powerCmd.PersistentFlags().BoolVarP(&vars.Full, "full", "F", false, "show detailed output.")
 	powerCmd.PersistentFlags().BoolVarP(&vars.Showcmd, "show", "s", false, "only show command which will be executed")
 	powerCmd.PersistentFlags().IntVar(&vars.Fanout, "fanout", 50, "how many command should be executed in parallel")
+	bmcoutput.AddFlags(powerCmd, &vars.Output)
 	return powerCmd
 }
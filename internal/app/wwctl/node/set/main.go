@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/selector"
 	apinode "github.com/warewulf/warewulf/internal/pkg/api/node"
 	"github.com/warewulf/warewulf/internal/pkg/api/routes/wwapiv1"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
@@ -54,6 +55,27 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 		}
 		wwlog.Debug("sending following values: %s", string(buffer))
 		args = hostlist.Expand(args)
+		if vars.selector != "" {
+			registry, err := node.New()
+			if err != nil {
+				return fmt.Errorf("could not open node configuration: %w", err)
+			}
+			nodes, err := registry.FindAllNodes()
+			if err != nil {
+				return fmt.Errorf("could not get node list: %w", err)
+			}
+			nodes, err = selector.FilterNodes(registry, nodes, args, vars.selector)
+			if err != nil {
+				return err
+			}
+			if len(nodes) == 0 {
+				return fmt.Errorf("no nodes match the selector")
+			}
+			args = nil
+			for _, n := range nodes {
+				args = append(args, n.Id())
+			}
+		}
 		set := wwapiv1.ConfSetParameter{
 			NodeConfYaml: string(buffer),
 
@@ -86,6 +108,12 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 				return nil
 			}
 		}
-		return apinode.NodeSet(&set)
+		if err := apinode.NodeSet(&set); err != nil {
+			return err
+		}
+		if vars.rotateToken {
+			return apinode.NodeRotateToken(args, vars.setNodeAll)
+		}
+		return nil
 	}
 }
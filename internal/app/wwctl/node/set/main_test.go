@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/testenv"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
 )
@@ -685,3 +686,33 @@ nodes:
 		})
 	}
 }
+
+func Test_Node_Set_RotateToken(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles: {}
+nodes:
+  n01: {}
+  n02: {}`)
+	warewulfd.SetNoDaemon()
+
+	token1, err := node.Token("n01")
+	assert.NoError(t, err)
+	token2, err := node.Token("n02")
+	assert.NoError(t, err)
+	env.WriteFile("srv/warewulf/overlays/n01/__SYSTEM__.img", "system overlay")
+	env.WriteFile("srv/warewulf/overlays/n02/__SYSTEM__.img", "system overlay")
+
+	baseCmd := GetCommand()
+	baseCmd.SetArgs([]string{"--rotate-token", "--yes", "n01"})
+	buf := new(bytes.Buffer)
+	baseCmd.SetOut(buf)
+	baseCmd.SetErr(buf)
+	assert.NoError(t, baseCmd.Execute())
+
+	assert.False(t, node.VerifyToken("n01", token1))
+	assert.True(t, node.VerifyToken("n02", token2))
+	assert.NoFileExists(t, env.GetPath("srv/warewulf/overlays/n01/__SYSTEM__.img"))
+	assert.FileExists(t, env.GetPath("srv/warewulf/overlays/n02/__SYSTEM__.img"))
+}
@@ -4,16 +4,19 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
 	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
+	"github.com/warewulf/warewulf/internal/app/wwctl/selector"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 )
 
 type variables struct {
-	setNodeAll bool
-	setYes     bool
-	setForce   bool
-	nodeConf   node.Node
-	nodeDel    node.NodeConfDel
-	nodeAdd    node.NodeConfAdd
+	setNodeAll  bool
+	setYes      bool
+	setForce    bool
+	rotateToken bool
+	selector    string
+	nodeConf    node.Node
+	nodeDel     node.NodeConfDel
+	nodeAdd     node.NodeConfAdd
 }
 
 func GetCommand() *cobra.Command {
@@ -23,9 +26,9 @@ func GetCommand() *cobra.Command {
 		DisableFlagsInUseLine: true,
 		Use:                   "set [OPTIONS] PATTERN",
 		Short:                 "Configure node properties",
-		Long:                  "This command sets configuration properties for nodes matching PATTERN.\n\nNote: use the string 'UNSET' to remove a configuration",
+		Long:                  "This command sets configuration properties for nodes matching PATTERN, or a\nselector expression given with --select.\n\nNote: use the string 'UNSET' to remove a configuration",
 		Aliases:               []string{"modify"},
-		Args:                  cobra.MinimumNArgs(1), // require pattern as a mandatory arg
+		Args:                  selector.MinimumNArgs(1, &vars.selector), // require pattern as a mandatory arg
 		RunE:                  CobraRunE(&vars),
 		ValidArgsFunction:     completions.Nodes,
 	}
@@ -39,6 +42,8 @@ func GetCommand() *cobra.Command {
 	baseCmd.PersistentFlags().BoolVarP(&vars.setNodeAll, "all", "a", false, "Set all nodes")
 	baseCmd.PersistentFlags().BoolVarP(&vars.setYes, "yes", "y", false, "Set 'yes' to all questions asked")
 	baseCmd.PersistentFlags().BoolVarP(&vars.setForce, "force", "f", false, "Force configuration (even on error)")
+	baseCmd.PersistentFlags().BoolVar(&vars.rotateToken, "rotate-token", false, "Issue a new token for runtime overlay requests")
+	selector.AddFlag(baseCmd, &vars.selector)
 	// register the command line completions
 	if err := baseCmd.RegisterFlagCompletionFunc("image", completions.Images); err != nil {
 		panic(err)
@@ -62,5 +67,6 @@ func GetCommand() *cobra.Command {
 		panic(err)
 	}
 
+	flags.AddStrict(baseCmd)
 	return baseCmd
 }
@@ -25,109 +25,189 @@ func CobraRunE(cmd *cobra.Command, args []string) (err error) {
 
 	}
 
-	for {
-		var elipsis bool
-		var height int
-		var count int
-		rightnow := time.Now().Unix()
+	if SetHistory {
+		return printHistory(args)
+	}
+
+	var nodeStatusResponse *wwapiv1.NodeStatusResponse
+	nodeStatusResponse, err = apinode.NodeStatus([]string{})
+	if err != nil {
+		return err
+	}
+
+	if !SetWatch {
+		printStatus(nodeStatusResponse.NodeStatus, args, 0)
+		return nil
+	}
+
+	return watchStatus(nodeStatusResponse.NodeStatus, args)
+}
+
+// watchStatus redraws the status table every update interval, applying
+// the events received from the warewulfd event stream to the initial
+// statuses.
+func watchStatus(initial []*wwapiv1.NodeStatus, args []string) error {
+	statuses := make(map[string]*wwapiv1.NodeStatus)
+	for _, o := range initial {
+		statuses[o.NodeName] = o
+	}
+
+	events := make(chan *apinode.NodeStatusEvent)
+	streamErr := make(chan error, 1)
+	go func() {
+		streamErr <- apinode.NodeStatusEvents(func(event *apinode.NodeStatusEvent) error {
+			events <- event
+			return nil
+		})
+	}()
 
-		var nodeStatusResponse *wwapiv1.NodeStatusResponse
-		nodeStatusResponse, err = apinode.NodeStatus([]string{})
+	ticker := time.NewTicker(time.Duration(SetUpdate) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		fmt.Print("\033[H\033[2J")
+		height, err := termHeight()
 		if err != nil {
-			return err
+			wwlog.Warn("Could not get terminal height, using 24")
 		}
-
-		if SetWatch {
-			fmt.Print("\033[H\033[2J")
-			_, height, err = term.GetSize(0)
-			if err != nil {
-				wwlog.Warn("Could not get terminal height, using 24")
-				height = 24
-			}
+		var list []*wwapiv1.NodeStatus
+		for _, o := range statuses {
+			list = append(list, o)
+		}
+		if printStatus(list, args, height) {
+			fmt.Printf("... ")
 		}
 
-		fmt.Printf("%-20s %-20s %-25s %-10s\n", "NODENAME", "STAGE", "SENT", "LASTSEEN (s)")
-		fmt.Printf("%s\n", strings.Repeat("=", 80))
-
-		wwlog.Verbose("Building sort index")
-		var statuses []*wwapiv1.NodeStatus
-		if len(args) > 0 {
-			nodeList := hostlist.Expand(args)
-			for i := 0; i < len(nodeStatusResponse.NodeStatus); i++ {
-				for j := 0; j < len(nodeList); j++ {
-					if nodeStatusResponse.NodeStatus[i].NodeName == nodeList[j] {
-						statuses = append(statuses, nodeStatusResponse.NodeStatus[i])
-						break
-					}
+	wait:
+		for {
+			select {
+			case err := <-streamErr:
+				return err
+			case event := <-events:
+				if event.NodeName == "" {
+					continue
 				}
-			}
-		} else {
-			for i := 0; i < len(nodeStatusResponse.NodeStatus); i++ {
-				statuses = append(statuses, nodeStatusResponse.NodeStatus[i])
+				statuses[event.NodeName] = &wwapiv1.NodeStatus{
+					NodeName: event.NodeName,
+					Stage:    event.Stage,
+					Sent:     event.Sent,
+					Ipaddr:   event.Ipaddr,
+					Lastseen: event.Lastseen,
+				}
+			case <-ticker.C:
+				break wait
 			}
 		}
+	}
+}
+
+func termHeight() (int, error) {
+	_, height, err := term.GetSize(0)
+	if err != nil {
+		return 24, err
+	}
+	return height, nil
+}
 
-		wwlog.Verbose("Sorting index")
-		if SetSortLast {
-			sort.Slice(statuses, func(i, j int) bool {
-				if statuses[i].Lastseen > statuses[j].Lastseen {
-					return true
-				} else if statuses[i].Lastseen < statuses[j].Lastseen {
-					return false
-				} else {
-					return statuses[i].NodeName < statuses[j].NodeName
+// printStatus prints the status table for the nodes matching args. If
+// height is greater than zero, output is truncated to fit and the
+// return value reports whether any nodes were left out.
+func printStatus(nodeStatuses []*wwapiv1.NodeStatus, args []string, height int) (elipsis bool) {
+	controller := warewulfconf.Get()
+	var count int
+	rightnow := time.Now().Unix()
+
+	fmt.Printf("%-20s %-20s %-25s %-10s\n", "NODENAME", "STAGE", "SENT", "LASTSEEN (s)")
+	fmt.Printf("%s\n", strings.Repeat("=", 80))
+
+	wwlog.Verbose("Building sort index")
+	var statuses []*wwapiv1.NodeStatus
+	if len(args) > 0 {
+		nodeList := hostlist.Expand(args)
+		for i := 0; i < len(nodeStatuses); i++ {
+			for j := 0; j < len(nodeList); j++ {
+				if nodeStatuses[i].NodeName == nodeList[j] {
+					statuses = append(statuses, nodeStatuses[i])
+					break
 				}
-			})
-		} else if SetSortReverse {
-			wwlog.Verbose("Reversing sort order")
-			sort.Slice(statuses, func(i, j int) bool {
-				return statuses[i].NodeName > statuses[j].NodeName
-			})
+			}
+		}
+	} else {
+		statuses = append(statuses, nodeStatuses...)
+	}
 
-		} else {
-			sort.Slice(statuses, func(i, j int) bool {
+	wwlog.Verbose("Sorting index")
+	if SetSortLast {
+		sort.Slice(statuses, func(i, j int) bool {
+			if statuses[i].Lastseen > statuses[j].Lastseen {
+				return true
+			} else if statuses[i].Lastseen < statuses[j].Lastseen {
+				return false
+			} else {
 				return statuses[i].NodeName < statuses[j].NodeName
-			})
+			}
+		})
+	} else if SetSortReverse {
+		wwlog.Verbose("Reversing sort order")
+		sort.Slice(statuses, func(i, j int) bool {
+			return statuses[i].NodeName > statuses[j].NodeName
+		})
+
+	} else {
+		sort.Slice(statuses, func(i, j int) bool {
+			return statuses[i].NodeName < statuses[j].NodeName
+		})
+	}
+
+	wwlog.Verbose("Printing results")
+	for i := 0; i < len(statuses); i++ {
+		o := statuses[i]
+		if SetTime > 0 && o.Lastseen < SetTime {
+			continue
 		}
 
-		wwlog.Verbose("Printing results")
-		for i := 0; i < len(statuses); i++ {
-			o := statuses[i]
-			if SetTime > 0 && o.Lastseen < SetTime {
+		if o.Lastseen > 0 {
+			if SetUnknown {
 				continue
 			}
-
-			if o.Lastseen > 0 {
-				if SetUnknown {
-					continue
-				}
-				if rightnow-o.Lastseen >= int64(controller.Warewulf.UpdateInterval*2) {
-					color.Red("%-20s %-20s %-25s %-10d\n", o.NodeName, o.Stage, o.Sent, rightnow-o.Lastseen)
-				} else if rightnow-o.Lastseen >= int64(controller.Warewulf.UpdateInterval+5) {
-					color.Yellow("%-20s %-20s %-25s %-10d\n", o.NodeName, o.Stage, o.Sent, rightnow-o.Lastseen)
-				} else {
-					fmt.Printf("%-20s %-20s %-25s %-10d\n", o.NodeName, o.Stage, o.Sent, rightnow-o.Lastseen)
-				}
+			if rightnow-o.Lastseen >= int64(controller.Warewulf.UpdateInterval*2) {
+				color.Red("%-20s %-20s %-25s %-10d\n", o.NodeName, o.Stage, o.Sent, rightnow-o.Lastseen)
+			} else if rightnow-o.Lastseen >= int64(controller.Warewulf.UpdateInterval+5) {
+				color.Yellow("%-20s %-20s %-25s %-10d\n", o.NodeName, o.Stage, o.Sent, rightnow-o.Lastseen)
 			} else {
-				color.HiBlack("%-20s %-20s %-25s %-10s\n", o.NodeName, "--", "--", "--")
-			}
-			if count+4 >= height && SetWatch {
-				if count+1 != len(statuses) {
-					elipsis = true
-				}
-				break
+				fmt.Printf("%-20s %-20s %-25s %-10d\n", o.NodeName, o.Stage, o.Sent, rightnow-o.Lastseen)
 			}
-			count++
+		} else {
+			color.HiBlack("%-20s %-20s %-25s %-10s\n", o.NodeName, "--", "--", "--")
 		}
-
-		if SetWatch {
-			if elipsis {
-				fmt.Printf("... ")
+		if height > 0 && count+4 >= height {
+			if count+1 != len(statuses) {
+				elipsis = true
 			}
-			time.Sleep(time.Duration(SetUpdate) * time.Millisecond)
-		} else {
 			break
 		}
+		count++
+	}
+	return elipsis
+}
+
+func printHistory(args []string) error {
+	nodeList := hostlist.Expand(args)
+	if len(nodeList) == 0 {
+		return fmt.Errorf("--history requires at least one node name")
+	}
+
+	fmt.Printf("%-20s %-20s %-25s %-16s %-20s\n", "NODENAME", "STAGE", "SENT", "IPADDR", "TIME")
+	fmt.Printf("%s\n", strings.Repeat("=", 105))
+	for _, nodeName := range nodeList {
+		history, err := apinode.NodeStatusHistory(nodeName)
+		if err != nil {
+			return err
+		}
+		for _, o := range history {
+			fmt.Printf("%-20s %-20s %-25s %-16s %-20s\n", o.NodeName, o.Stage, o.Sent, o.Ipaddr,
+				time.Unix(o.Lastseen, 0).Format(time.DateTime))
+		}
 	}
-	return
+	return nil
 }
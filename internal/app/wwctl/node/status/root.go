@@ -21,15 +21,17 @@ var (
 	SetSortLast    bool
 	SetSortReverse bool
 	SetUnknown     bool
+	SetHistory     bool
 )
 
 func init() {
 	baseCmd.PersistentFlags().BoolVarP(&SetWatch, "watch", "w", false, "Watch the status automatically")
-	baseCmd.PersistentFlags().IntVarP(&SetUpdate, "update", "U", 500, "Set the update frequency for 'watch' (ms)")
+	baseCmd.PersistentFlags().IntVarP(&SetUpdate, "update", "U", 500, "Set the redraw frequency for 'watch' (ms)")
 	baseCmd.PersistentFlags().Int64VarP(&SetTime, "time", "t", 0, "Filter by last checkin time (seconds)")
 	baseCmd.PersistentFlags().BoolVarP(&SetSortLast, "last", "l", false, "Sort by the last check-in time")
 	baseCmd.PersistentFlags().BoolVarP(&SetSortReverse, "reverse", "r", false, "Reverse the sort order")
 	baseCmd.PersistentFlags().BoolVarP(&SetUnknown, "unknown", "u", false, "Only show nodes of unknown status")
+	baseCmd.PersistentFlags().BoolVar(&SetHistory, "history", false, "Show the recorded stage transitions for the given nodes")
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
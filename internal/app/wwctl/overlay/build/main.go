@@ -8,6 +8,7 @@ import (
 	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/selector"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
@@ -35,6 +36,15 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 	} else {
 		filteredNodes = allNodes
 	}
+	if Selector != "" {
+		filteredNodes, err = selector.FilterNodes(nodeDB, filteredNodes, nil, Selector)
+		if err != nil {
+			return err
+		}
+		if len(filteredNodes) == 0 {
+			return errors.New("no nodes match the selector")
+		}
+	}
 
 	// NOTE: this is to keep backward compatible
 	// passing -O a,b,c versus -O a -O b -O c, but will also accept -O a,b -O c
@@ -52,7 +62,7 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 			return errors.New("must specify overlay(s) to build")
 		}
 
-		if len(args) > 0 {
+		if len(args) > 0 || Selector != "" {
 			if len(filteredNodes) != 1 {
 				return errors.New("must specify one node to build overlay")
 			}
@@ -3,6 +3,7 @@ package build
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/selector"
 )
 
 var (
@@ -18,6 +19,7 @@ var (
 	OverlayNames []string
 	OverlayDir   string
 	Workers      int
+	Selector     string
 )
 
 func init() {
@@ -28,6 +30,7 @@ func init() {
 	}
 	baseCmd.PersistentFlags().StringVarP(&OverlayDir, "output", "o", "", `Do not create an overlay image for distribution but write to
 	the given directory. An overlay must also be ge given to use this option.`)
+	selector.AddFlag(baseCmd, &Selector)
 	baseCmd.PersistentFlags().IntVar(&Workers, "workers", 0, "The number of parallel workers building overlays (<=0 indicates 1 worker per CPU)")
 }
 
@@ -0,0 +1,51 @@
+package bootdev
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/bmcoutput"
+	"github.com/warewulf/warewulf/internal/app/wwctl/selector"
+	"github.com/warewulf/warewulf/internal/pkg/bmc"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err error) {
+	return func(cmd *cobra.Command, args []string) error {
+
+		ipmiCmd := "BootDevPXE"
+		if vars.Disk {
+			ipmiCmd = "BootDevDisk"
+		} else if vars.BIOS {
+			ipmiCmd = "BootDevBIOS"
+		}
+
+		nodeDB, err := node.New()
+		if err != nil {
+			return fmt.Errorf("could not open node configuration: %s", err)
+		}
+
+		nodes, err := nodeDB.FindAllNodes()
+		if err != nil {
+			return fmt.Errorf("could not get node list: %s", err)
+		}
+
+		if len(args) == 0 && vars.Selector == "" {
+			//nolint:errcheck
+			cmd.Usage()
+			os.Exit(1)
+		}
+		nodes, err = selector.FilterNodes(nodeDB, nodes, args, vars.Selector)
+		if err != nil {
+			return err
+		}
+
+		if len(nodes) == 0 {
+			return fmt.Errorf("no nodes found")
+		}
+
+		results := bmc.RunBootDevCommand(nodes, ipmiCmd, vars.Persistent, vars.Fanout, vars.Showcmd)
+		return bmcoutput.Print(cmd, vars.Output, results)
+	}
+}
@@ -0,0 +1,48 @@
+package bootdev
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/bmcoutput"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/selector"
+)
+
+type variables struct {
+	Showcmd    bool
+	Fanout     int
+	Output     bmcoutput.Format
+	PXE        bool
+	Disk       bool
+	BIOS       bool
+	Persistent bool
+	Selector   string
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	vars := variables{}
+	powerCmd := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "bootdev [OPTIONS] --pxe|--disk|--bios PATTERN ...",
+		Short:                 "Set the boot device for the given node(s)",
+		Long: "This command sets the boot device of a set of nodes specified by PATTERN.\n" +
+			"By default the boot device is only used for the next boot.",
+		Example: "  wwctl power bootdev --pxe n1\n" +
+			"  wwctl power bootdev --disk --persistent n[1-10]",
+		RunE:              CobraRunE(&vars),
+		Args:              selector.MinimumNArgs(1, &vars.Selector),
+		ValidArgsFunction: completions.Nodes,
+	}
+	powerCmd.PersistentFlags().BoolVar(&vars.PXE, "pxe", false, "boot from the network")
+	powerCmd.PersistentFlags().BoolVar(&vars.Disk, "disk", false, "boot from the local disk")
+	powerCmd.PersistentFlags().BoolVar(&vars.BIOS, "bios", false, "boot into the BIOS setup")
+	powerCmd.PersistentFlags().BoolVar(&vars.Persistent, "persistent", false, "use the boot device for all following boots")
+	powerCmd.MarkFlagsMutuallyExclusive("pxe", "disk", "bios")
+	powerCmd.MarkFlagsOneRequired("pxe", "disk", "bios")
+	powerCmd.PersistentFlags().BoolVarP(&vars.Showcmd, "show", "s", false, "only show command which will be executed")
+	powerCmd.PersistentFlags().IntVar(&vars.Fanout, "fanout", 50, "how many command should be executed in parallel")
+	bmcoutput.AddFlags(powerCmd, &vars.Output)
+	selector.AddFlag(powerCmd, &vars.Selector)
+
+	return powerCmd
+}
@@ -0,0 +1,76 @@
+package bootdev
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func Test_Power_Bootdev(t *testing.T) {
+	warewulfd.SetNoDaemon()
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default:
+    ipmi:
+      template: ipmitool.tmpl
+      username: admin
+      password: admin
+nodes:
+  n01:
+    profiles:
+    - default
+    ipmi:
+      ipaddr: 10.10.10.10`)
+	env.ImportFile("usr/share/warewulf/bmc/ipmitool.tmpl", "../../../../../lib/warewulf/bmc/ipmitool.tmpl")
+
+	tests := map[string]struct {
+		args     []string
+		expected string
+		err      bool
+	}{
+		"bootdev pxe": {
+			args:     []string{"--show", "--pxe", "n01"},
+			expected: `10.10.10.10: ipmitool -H 10.10.10.10 -U "admin" -P "admin" chassis bootdev pxe`,
+		},
+		"bootdev disk persistent": {
+			args:     []string{"--show", "--disk", "--persistent", "n01"},
+			expected: `10.10.10.10: ipmitool -H 10.10.10.10 -U "admin" -P "admin" chassis bootdev disk options=persistent`,
+		},
+		"bootdev bios": {
+			args:     []string{"--show", "--bios", "n01"},
+			expected: `10.10.10.10: ipmitool -H 10.10.10.10 -U "admin" -P "admin" chassis bootdev bios`,
+		},
+		"no device": {
+			args: []string{"--show", "n01"},
+			err:  true,
+		},
+		"multiple devices": {
+			args: []string{"--show", "--pxe", "--disk", "n01"},
+			err:  true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			baseCmd := GetCommand()
+			buf := new(bytes.Buffer)
+			baseCmd.SetOut(buf)
+			baseCmd.SetErr(buf)
+			wwlog.SetLogWriter(buf)
+			baseCmd.SetArgs(tt.args)
+			err := baseCmd.Execute()
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(tt.expected), strings.TrimSpace(buf.String()))
+		})
+	}
+}
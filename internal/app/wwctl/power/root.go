@@ -2,6 +2,7 @@ package power
 
 import (
 	"github.com/spf13/cobra"
+	powerbootdev "github.com/warewulf/warewulf/internal/app/wwctl/power/bootdev"
 	powercycle "github.com/warewulf/warewulf/internal/app/wwctl/power/cycle"
 	poweroff "github.com/warewulf/warewulf/internal/app/wwctl/power/off"
 	poweron "github.com/warewulf/warewulf/internal/app/wwctl/power/on"
@@ -21,6 +22,7 @@ var (
 )
 
 func init() {
+	baseCmd.AddCommand(powerbootdev.GetCommand())
 	baseCmd.AddCommand(powercycle.GetCommand())
 	baseCmd.AddCommand(poweroff.GetCommand())
 	baseCmd.AddCommand(poweron.GetCommand())
@@ -2,12 +2,16 @@ package status
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/bmcoutput"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/selector"
 )
 
 type variables struct {
-	Showcmd bool
-	Fanout  int
+	Showcmd  bool
+	Fanout   int
+	Output   bmcoutput.Format
+	Selector string
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
@@ -19,10 +23,12 @@ func GetCommand() *cobra.Command {
 		Short:                 "Show power status for the given node(s)",
 		Long:                  "This command displays the power status of a set of nodes specified by PATTERN.",
 		RunE:                  CobraRunE(&vars),
-		Args:                  cobra.MinimumNArgs(1),
+		Args:                  selector.MinimumNArgs(1, &vars.Selector),
 		ValidArgsFunction:     completions.Nodes,
 	}
 	powerCmd.PersistentFlags().BoolVarP(&vars.Showcmd, "show", "s", false, "only show command which will be executed")
 	powerCmd.PersistentFlags().IntVar(&vars.Fanout, "fanout", 50, "how many command should be executed in parallel")
+	bmcoutput.AddFlags(powerCmd, &vars.Output)
+	selector.AddFlag(powerCmd, &vars.Selector)
 	return powerCmd
 }
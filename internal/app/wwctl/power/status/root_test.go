@@ -2,6 +2,7 @@ package status
 
 import (
 	"bytes"
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -54,3 +55,40 @@ nodes:
 		})
 	}
 }
+
+func Test_Power_Status_Json(t *testing.T) {
+	warewulfd.SetNoDaemon()
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodes:
+  n01:
+    ipmi:
+      template: nobmc.tmpl
+      ipaddr: 10.10.10.10
+  n02: {}`)
+	env.ImportFile("usr/share/warewulf/bmc/nobmc.tmpl", "../../../../../lib/warewulf/bmc/nobmc.tmpl")
+
+	baseCmd := GetCommand()
+	buf := new(bytes.Buffer)
+	baseCmd.SetOut(buf)
+	baseCmd.SetErr(new(bytes.Buffer))
+	wwlog.SetLogWriter(buf)
+	baseCmd.SetArgs([]string{"--show", "--json", "n01,n02"})
+	err := baseCmd.Execute()
+	assert.NoError(t, err)
+
+	var results []map[string]interface{}
+	assert.NoError(t, json.NewDecoder(buf).Decode(&results))
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "n01", results[0]["node"])
+		assert.Equal(t, "10.10.10.10", results[0]["ipaddr"])
+		assert.Equal(t, "PowerStatus", results[0]["command"])
+		assert.Equal(t, `ping -c 1 "10.10.10.10" &> /dev/null && echo ON || echo OFF`, results[0]["output"])
+		assert.NotContains(t, results[0], "error")
+		assert.Contains(t, results[0], "duration")
+		assert.Equal(t, "n02", results[1]["node"])
+		assert.Equal(t, "no IPMI IP address", results[1]["error"])
+		assert.Equal(t, true, results[1]["skipped"])
+	}
+}
@@ -50,5 +50,6 @@ func GetCommand() *cobra.Command {
 	if err := baseCmd.RegisterFlagCompletionFunc("wwinit", completions.OverlayList); err != nil {
 		panic(err)
 	}
+	flags.AddStrict(baseCmd)
 	return baseCmd
 }
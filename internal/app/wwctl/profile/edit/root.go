@@ -3,6 +3,7 @@ package edit
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 )
 
 var (
@@ -20,6 +21,7 @@ var (
 
 func init() {
 	baseCmd.PersistentFlags().BoolVar(&NoHeader, "noheader", false, "Do not print header")
+	flags.AddStrict(baseCmd)
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
@@ -4,10 +4,14 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/api"
+	"github.com/warewulf/warewulf/internal/app/wwctl/audit"
 	"github.com/warewulf/warewulf/internal/app/wwctl/clean"
+	"github.com/warewulf/warewulf/internal/app/wwctl/config"
 	"github.com/warewulf/warewulf/internal/app/wwctl/configure"
 	"github.com/warewulf/warewulf/internal/app/wwctl/genconf"
 	"github.com/warewulf/warewulf/internal/app/wwctl/image"
+	"github.com/warewulf/warewulf/internal/app/wwctl/network"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node"
 	"github.com/warewulf/warewulf/internal/app/wwctl/overlay"
 	"github.com/warewulf/warewulf/internal/app/wwctl/power"
@@ -62,6 +66,10 @@ func init() {
 	rootCmd.AddCommand(genconf.GetCommand())
 	rootCmd.AddCommand(clean.GetCommand())
 	rootCmd.AddCommand(upgrade.GetCommand())
+	rootCmd.AddCommand(api.GetCommand())
+	rootCmd.AddCommand(audit.GetCommand())
+	rootCmd.AddCommand(config.GetCommand())
+	rootCmd.AddCommand(network.GetCommand())
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
@@ -94,5 +102,13 @@ func rootPersistentPreRunE(cmd *cobra.Command, args []string) (err error) {
 			return
 		}
 	}
+	// commands which write the node configuration, and config check,
+	// have a --strict flag which overrides nodedb:strict
+	if strict, err := cmd.Flags().GetBool("strict"); err == nil && strict {
+		if conf.NodeDB == nil {
+			conf.NodeDB = new(warewulfconf.NodeDBConf)
+		}
+		conf.NodeDB.StrictP = &strict
+	}
 	return
 }
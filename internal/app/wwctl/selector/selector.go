@@ -0,0 +1,87 @@
+// Package selector adds the --select flag to wwctl commands which act on
+// nodes, and selects the nodes which match it and the node patterns
+// given as arguments.
+package selector
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	apinode "github.com/warewulf/warewulf/internal/pkg/api/node"
+	"github.com/warewulf/warewulf/internal/pkg/hostlist"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+// Usage describes the selector language in flag help.
+const Usage = "Select nodes with an expression, e.g. 'tag.gpu=a100 && cluster=hpc2' (keys: name, cluster, image, profile, network, tag.NAME, stage, lastseen)"
+
+// AddFlag adds the --select flag to cmd.
+func AddFlag(cmd *cobra.Command, expression *string) {
+	cmd.PersistentFlags().StringVar(expression, "select", "", Usage)
+}
+
+// MinimumNArgs requires at least n arguments, unless a selector is
+// given.
+func MinimumNArgs(n int, expression *string) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if *expression != "" {
+			return nil
+		}
+		return cobra.MinimumNArgs(n)(cmd, args)
+	}
+}
+
+// Parse parses expression, returning nil if it is empty.
+func Parse(expression string) (*node.Selector, error) {
+	if expression == "" {
+		return nil, nil
+	}
+	return node.ParseSelector(expression)
+}
+
+/*
+FilterNodes returns the nodes of set which match the node patterns, if
+any, and the selector expression, if given. The state of the nodes is
+only read from warewulfd if the expression uses it.
+*/
+func FilterNodes(registry node.NodesYaml, set []node.Node, patterns []string, expression string) ([]node.Node, error) {
+	selector, err := Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) > 0 {
+		set = node.FilterNodeListByName(set, hostlist.Expand(patterns))
+	}
+	if selector == nil {
+		return set, nil
+	}
+	state, err := State(selector)
+	if err != nil {
+		return nil, err
+	}
+	return registry.SelectNodes(set, selector, state), nil
+}
+
+// State returns the state of the nodes from warewulfd, if selector
+// needs it, and nil otherwise.
+func State(selector *node.Selector) (node.StateFunc, error) {
+	if selector == nil || !selector.NeedsState() {
+		return nil, nil
+	}
+	response, err := apinode.NodeStatus([]string{})
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]node.NodeState)
+	for _, status := range response.NodeStatus {
+		state := node.NodeState{Stage: status.Stage}
+		if status.Lastseen > 0 {
+			state.LastSeen = time.Unix(status.Lastseen, 0)
+		}
+		states[status.NodeName] = state
+	}
+	return func(id string) (node.NodeState, bool) {
+		state, ok := states[id]
+		return state, ok
+	}, nil
+}
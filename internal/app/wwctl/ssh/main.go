@@ -10,8 +10,8 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/selector"
 	"github.com/warewulf/warewulf/internal/pkg/batch"
-	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
@@ -31,12 +31,16 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 		os.Exit(1)
 	}
 
-	if len(args) > 0 {
-		nodes = node.FilterNodeListByName(nodes, hostlist.Expand(args))
-	} else {
-		//nolint:errcheck
-		cmd.Usage()
-		os.Exit(1)
+	// with a selector, all arguments are the command
+	patterns := args[:1]
+	commandArgs := args[1:]
+	if Selector != "" {
+		patterns = nil
+		commandArgs = args
+	}
+	nodes, err = selector.FilterNodes(nodeDB, nodes, patterns, Selector)
+	if err != nil {
+		return err
 	}
 	for _, node := range nodes {
 		var primaryNet string
@@ -59,7 +63,7 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 		var command []string
 
 		command = append(command, node.NetDevs[primaryNet].Ipaddr.String())
-		command = append(command, args[1:]...)
+		command = append(command, commandArgs...)
 
 		batchpool.Submit(func() {
 
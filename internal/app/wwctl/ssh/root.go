@@ -3,27 +3,34 @@ package ssh
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/selector"
 )
 
 var (
 	baseCmd = &cobra.Command{
 		DisableFlagsInUseLine: true,
-		Use:                   "ssh [OPTIONS] NODE_PATTERN COMMAND",
+		Use:                   "ssh [OPTIONS] NODE_PATTERN|--select=EXPRESSION COMMAND",
 		Short:                 "SSH into configured nodes in parallel",
 		Long:                  "Easily ssh into nodes in parallel to run non-interactive commands\n",
 		RunE:                  CobraRunE,
-		Args:                  cobra.MinimumNArgs(2),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if Selector != "" {
+				return cobra.MinimumNArgs(1)(cmd, args)
+			}
+			return cobra.MinimumNArgs(2)(cmd, args)
+		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-			if len(args) == 0 {
+			if len(args) == 0 && Selector == "" {
 				return completions.Nodes(cmd, args, toComplete)
 			}
 			return completions.None(cmd, args, toComplete)
 		},
 	}
-	DryRun  bool
-	FanOut  int
-	Sleep   int
-	SshPath string
+	DryRun   bool
+	FanOut   int
+	Sleep    int
+	SshPath  string
+	Selector string
 )
 
 func init() {
@@ -31,6 +38,7 @@ func init() {
 	baseCmd.PersistentFlags().IntVarP(&FanOut, "fanout", "f", 32, "How many connections to run in parallel")
 	baseCmd.PersistentFlags().IntVarP(&Sleep, "sleep", "s", 0, "Seconds to sleep inbetween processes")
 	baseCmd.PersistentFlags().StringVar(&SshPath, "rsh", "/usr/bin/ssh", "Path to use for RSH/SSH command")
+	selector.AddFlag(baseCmd, &Selector)
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
@@ -4,18 +4,21 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/warewulf/warewulf/internal/app/wwctl/upgrade/config"
+	"github.com/warewulf/warewulf/internal/app/wwctl/upgrade/nodedb"
 	"github.com/warewulf/warewulf/internal/app/wwctl/upgrade/nodes"
 )
 
 func GetCommand() *cobra.Command {
 	command := &cobra.Command{
 		DisableFlagsInUseLine: true,
-		Use:                   "upgrade <config|nodes> [OPTIONS]",
+		Use:                   "upgrade <config|nodes|nodedb> [OPTIONS]",
 		Short:                 "Upgrade configuration files",
 		Long: `Upgrade warewulf.conf or nodes.conf from a previous version of Warewulf 4 to a format
-supported by the current version.`,
+supported by the current version, or migrate nodes and profiles to another
+node database backend.`,
 	}
 	command.AddCommand(config.GetCommand())
 	command.AddCommand(nodes.GetCommand())
+	command.AddCommand(nodedb.GetCommand())
 	return command
 }
@@ -0,0 +1,75 @@
+package nodedb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+var (
+	fromBackend string
+	toBackend   string
+)
+
+func GetCommand() *cobra.Command {
+	command := &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "nodedb [OPTIONS]",
+		Short:                 "Migrate nodes and profiles to another node database backend",
+		Long: `Copies all nodes and profiles from one node database backend to another,
+replacing its content. Afterwards, select the new backend with nodedb:backend
+in warewulf.conf and restart warewulfd.`,
+		RunE:              MigrateNodeDB,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: completions.None,
+	}
+	backends := strings.Join(node.Backends, ", ")
+	command.Flags().StringVar(&fromBackend, "from", "", "Backend to migrate from ("+backends+"; default: the configured backend)")
+	command.Flags().StringVar(&toBackend, "to", "", "Backend to migrate to ("+backends+")")
+	if err := command.MarkFlagRequired("to"); err != nil {
+		panic(err)
+	}
+	if err := command.RegisterFlagCompletionFunc("from", backendCompletion); err != nil {
+		panic(err)
+	}
+	if err := command.RegisterFlagCompletionFunc("to", backendCompletion); err != nil {
+		panic(err)
+	}
+	return command
+}
+
+func backendCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return node.Backends, cobra.ShellCompDirectiveNoFileComp
+}
+
+func MigrateNodeDB(cmd *cobra.Command, args []string) error {
+	fromBackend := fromBackend
+	if fromBackend == "" {
+		fromBackend = config.Get().NodeDB.Backend
+	}
+	from, err := node.NewStore(fromBackend)
+	if err != nil {
+		return err
+	}
+	to, err := node.NewStore(toBackend)
+	if err != nil {
+		return err
+	}
+	if from.File() == to.File() {
+		return fmt.Errorf("can't migrate the %s backend to itself", toBackend)
+	}
+	if err := node.Migrate(from, to); err != nil {
+		return fmt.Errorf("could not migrate %s to %s: %w", from.File(), to.File(), err)
+	}
+	wwlog.Info("Migrated %s to %s", from.File(), to.File())
+	if config.Get().NodeDB.Backend != toBackend {
+		wwlog.Info("Set nodedb:backend to %s in warewulf.conf to use it", toBackend)
+	}
+	return nil
+}
@@ -94,7 +94,12 @@ ARG_LOOP:
 	return
 }
 
-func ImageImport(cip *wwapiv1.ImageImportParameter) (imageName string, err error) {
+/*
+ImageImport imports an image as described by cip. Images pulled from
+OCI sources are verified if verify is set, or with the signature policy
+file at policyPath, if it is set.
+*/
+func ImageImport(cip *wwapiv1.ImageImportParameter, verify bool, policyPath string) (imageName string, err error) {
 	if cip == nil {
 		err = fmt.Errorf("input parameter is nil")
 		return
@@ -135,6 +140,10 @@ func ImageImport(cip *wwapiv1.ImageImportParameter) (imageName string, err error
 		if err != nil {
 			return
 		}
+		if policyPath != "" {
+			sCtx.SignaturePolicyPath = policyPath
+			verify = true
+		}
 
 		if util.IsFile(cip.Source) && !filepath.IsAbs(cip.Source) {
 			cip.Source, err = filepath.Abs(cip.Source)
@@ -143,13 +152,17 @@ func ImageImport(cip *wwapiv1.ImageImportParameter) (imageName string, err error
 				return
 			}
 		}
-		err = image.ImportDocker(cip.Source, cip.Name, sCtx)
+		err = image.ImportDocker(cip.Source, cip.Name, sCtx, verify)
 		if err != nil {
 			err = fmt.Errorf("could not import image: %s", err.Error())
 			_ = image.DeleteSource(cip.Name)
 			return
 		}
 	} else if util.IsDir(cip.Source) {
+		if verify || policyPath != "" {
+			err = fmt.Errorf("signatures can only be verified for images from OCI sources: %s", cip.Source)
+			return
+		}
 		err = image.ImportDirectory(cip.Source, cip.Name)
 		if err != nil {
 			err = fmt.Errorf("could not import image: %s", err.Error())
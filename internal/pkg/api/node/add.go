@@ -62,6 +62,10 @@ func NodeAdd(nap *wwapiv1.NodeAddParameter) (err error) {
 			}
 		}
 	}
+	err = nodeDB.AllocateIPs(node_args...)
+	if err != nil {
+		return fmt.Errorf("failed to allocate addresses: %w", err)
+	}
 
 	err = nodeDB.Persist()
 	if err != nil {
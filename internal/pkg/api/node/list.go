@@ -15,9 +15,10 @@ import (
 
 /*
 NodeList lists all to none of the nodes managed by Warewulf. Returns
-a formated string slice, with each line as separate string
+a formated string slice, with each line as separate string. If selector
+isn't nil, only the nodes which match it are listed.
 */
-func NodeList(nodeGet *wwapiv1.GetNodeList) (nodeList wwapiv1.NodeList, err error) {
+func NodeList(nodeGet *wwapiv1.GetNodeList, selector *node.Selector, state node.StateFunc) (nodeList wwapiv1.NodeList, err error) {
 	// nil is okay for nodeNames
 	nodeDB, err := node.New()
 	if err != nil {
@@ -29,11 +30,15 @@ func NodeList(nodeGet *wwapiv1.GetNodeList) (nodeList wwapiv1.NodeList, err erro
 	}
 	nodeGet.Nodes = hostlist.Expand(nodeGet.Nodes)
 	sort.Strings(nodeGet.Nodes)
+	nodes = node.FilterNodeListByName(nodes, nodeGet.Nodes)
+	if selector != nil {
+		nodes = nodeDB.SelectNodes(nodes, selector, state)
+	}
 
 	if nodeGet.Type == wwapiv1.GetNodeList_Simple {
 		nodeList.Output = append(nodeList.Output,
 			fmt.Sprintf("%s:=:%s:=:%s", "NODE NAME", "PROFILES", "NETWORK"))
-		for _, n := range node.FilterNodeListByName(nodes, nodeGet.Nodes) {
+		for _, n := range nodes {
 			var netNames []string
 			for k := range n.NetDevs {
 				netNames = append(netNames, k)
@@ -45,7 +50,7 @@ func NodeList(nodeGet *wwapiv1.GetNodeList) (nodeList wwapiv1.NodeList, err erro
 	} else if nodeGet.Type == wwapiv1.GetNodeList_Network {
 		nodeList.Output = append(nodeList.Output,
 			fmt.Sprintf("%s:=:%s:=:%s:=:%s:=:%s:=:%s", "NODE", "NETWORK", "HWADDR", "IPADDR", "GATEWAY", "DEVICE"))
-		for _, n := range node.FilterNodeListByName(nodes, nodeGet.Nodes) {
+		for _, n := range nodes {
 			if len(n.NetDevs) > 0 {
 				for name := range n.NetDevs {
 					nodeList.Output = append(nodeList.Output,
@@ -63,7 +68,7 @@ func NodeList(nodeGet *wwapiv1.GetNodeList) (nodeList wwapiv1.NodeList, err erro
 	} else if nodeGet.Type == wwapiv1.GetNodeList_Ipmi {
 		nodeList.Output = append(nodeList.Output,
 			fmt.Sprintf("%s:=:%s:=:%s:=:%s:=:%s", "NODE", "IPMI IPADDR", "IPMI PORT", "IPMI USERNAME", "IPMI INTERFACE"))
-		for _, n := range node.FilterNodeListByName(nodes, nodeGet.Nodes) {
+		for _, n := range nodes {
 			ipaddr, port, username, iface := "", "", "", ""
 			if n.Ipmi != nil {
 				ipaddr = n.Ipmi.Ipaddr.String()
@@ -82,7 +87,7 @@ func NodeList(nodeGet *wwapiv1.GetNodeList) (nodeList wwapiv1.NodeList, err erro
 	} else if nodeGet.Type == wwapiv1.GetNodeList_Long {
 		nodeList.Output = append(nodeList.Output,
 			fmt.Sprintf("%s:=:%s:=:%s:=:%s", "NODE NAME", "KERNEL VERSION", "IMAGE", "OVERLAYS (S/R)"))
-		for _, n := range node.FilterNodeListByName(nodes, nodeGet.Nodes) {
+		for _, n := range nodes {
 			kernelVersion := ""
 			if n.Kernel != nil {
 				kernelVersion = n.Kernel.Version
@@ -96,7 +101,7 @@ func NodeList(nodeGet *wwapiv1.GetNodeList) (nodeList wwapiv1.NodeList, err erro
 	} else if nodeGet.Type == wwapiv1.GetNodeList_All {
 		nodeList.Output = append(nodeList.Output,
 			fmt.Sprintf("%s:=:%s:=:%s:=:%s", "NODE", "FIELD", "PROFILE", "VALUE"))
-		for _, n := range node.FilterNodeListByName(nodes, nodeGet.Nodes) {
+		for _, n := range nodes {
 			if _, fields, err := nodeDB.MergeNode(n.Id()); err != nil {
 				wwlog.Error("unable to merge node %v: %v", n.Id(), err)
 				continue
@@ -109,7 +114,7 @@ func NodeList(nodeGet *wwapiv1.GetNodeList) (nodeList wwapiv1.NodeList, err erro
 		}
 	} else if nodeGet.Type == wwapiv1.GetNodeList_YAML || nodeGet.Type == wwapiv1.GetNodeList_JSON {
 		nodeMap := make(map[string]node.Node)
-		for _, n := range node.FilterNodeListByName(nodes, nodeGet.Nodes) {
+		for _, n := range nodes {
 			nodeMap[n.Id()] = n
 		}
 		var buf []byte
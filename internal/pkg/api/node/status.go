@@ -1,9 +1,13 @@
 package apinode
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 
 	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
 
@@ -92,3 +96,116 @@ func NodeStatus(nodeNames []string) (nodeStatusResponse *wwapiv1.NodeStatusRespo
 	}
 	return
 }
+
+// NodeStatusEvent is a single stage transition for a node, either
+// recorded in the node's history or received from the event stream.
+type NodeStatusEvent struct {
+	Type     string `json:"type,omitempty"`
+	Hwaddr   string `json:"hwaddr,omitempty"`
+	NodeName string `json:"node name"`
+	Stage    string `json:"stage"`
+	Sent     string `json:"sent"`
+	Ipaddr   string `json:"ipaddr"`
+	Lastseen int64  `json:"last seen"`
+}
+
+// NodeStatusHistory returns the recorded stage transitions for a
+// node, oldest first.
+// This requires warewulfd.
+func NodeStatusHistory(nodeName string) (history []*NodeStatusEvent, err error) {
+	type nodeStatusHistory struct {
+		NodeName string             `json:"node name"`
+		History  []*NodeStatusEvent `json:"history"`
+	}
+
+	controller := warewulfconf.Get()
+
+	if controller.Ipaddr == "" {
+		err = fmt.Errorf("the Warewulf Server IP Address is not properly configured")
+		wwlog.Error(fmt.Sprintf("%v", err.Error()))
+		return
+	}
+
+	historyURL := fmt.Sprintf("http://%s:%d/status/%s/history", controller.Ipaddr, controller.Warewulf.Port, url.PathEscape(nodeName))
+	wwlog.Verbose("Connecting to: %s", historyURL)
+
+	resp, err := http.Get(historyURL)
+	if err != nil {
+		wwlog.Error("Could not connect to Warewulf server: %s", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("no status recorded for node: %s", nodeName)
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response from Warewulf server: %s", resp.Status)
+	}
+
+	var wwHistory nodeStatusHistory
+	if err = json.NewDecoder(resp.Body).Decode(&wwHistory); err != nil {
+		wwlog.Error("Could not decode JSON: %s", err)
+		return
+	}
+	return wwHistory.History, nil
+}
+
+// NodeStatusEvents connects to the warewulfd event stream and calls
+// handler for every status and discovery event until the stream ends or
+// handler returns an error.
+// This requires warewulfd.
+func NodeStatusEvents(handler func(*NodeStatusEvent) error) (err error) {
+	controller := warewulfconf.Get()
+
+	if controller.Ipaddr == "" {
+		err = fmt.Errorf("the Warewulf Server IP Address is not properly configured")
+		wwlog.Error(fmt.Sprintf("%v", err.Error()))
+		return
+	}
+
+	eventsURL := fmt.Sprintf("http://%s:%d/status/events", controller.Ipaddr, controller.Warewulf.Port)
+	wwlog.Verbose("Connecting to: %s", eventsURL)
+
+	resp, err := http.Get(eventsURL)
+	if err != nil {
+		wwlog.Error("Could not connect to Warewulf server: %s", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response from Warewulf server: %s", resp.Status)
+	}
+
+	return readStatusEvents(resp.Body, handler)
+}
+
+// readStatusEvents parses a server-sent event stream, calling handler
+// with the decoded data of each event.
+func readStatusEvents(stream io.Reader, handler func(*NodeStatusEvent) error) error {
+	var data []string
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) == 0 {
+				continue
+			}
+			var event NodeStatusEvent
+			if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &event); err != nil {
+				return fmt.Errorf("could not decode status event: %w", err)
+			}
+			data = nil
+			if err := handler(&event); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("event stream closed by Warewulf server")
+}
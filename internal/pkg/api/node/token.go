@@ -0,0 +1,39 @@
+package apinode
+
+import (
+	"fmt"
+
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/overlay"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// NodeRotateToken issues new runtime overlay tokens for the given
+// nodes, or for all nodes if all is set. The overlay images of the
+// nodes are removed, so that the system overlay is built again with the
+// new token when it is next requested; nodes must fetch their system
+// overlay again (e.g., by rebooting) before they can fetch the runtime
+// overlay.
+func NodeRotateToken(nodeNames []string, all bool) error {
+	nodeDB, err := node.New()
+	if err != nil {
+		return fmt.Errorf("failed to open node database: %w", err)
+	}
+	if all {
+		nodeNames = nodeDB.ListAllNodes()
+	}
+	for _, nodeName := range nodeNames {
+		if _, err := nodeDB.GetNodeOnly(nodeName); err != nil {
+			wwlog.Warn("not rotating token of unknown node: %s", nodeName)
+			continue
+		}
+		if _, err := node.RotateToken(nodeName); err != nil {
+			return fmt.Errorf("failed to rotate token of node %s: %w", nodeName, err)
+		}
+		if err := overlay.RemoveOverlayImages(nodeName); err != nil {
+			return fmt.Errorf("failed to remove overlay images of node %s: %w", nodeName, err)
+		}
+		wwlog.Info("Rotated token of node %s", nodeName)
+	}
+	return nil
+}
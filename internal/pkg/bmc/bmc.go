@@ -23,9 +23,10 @@ type Result struct {
 
 type TemplateStruct struct {
 	node.IpmiConf
-	ShowOnly bool
-	Cmd      string
-	result   Result
+	ShowOnly   bool
+	Persistent bool
+	Cmd        string
+	result     Result
 }
 
 func (tstruct *TemplateStruct) Result() (string, error) {
@@ -84,17 +85,44 @@ func (tstruct *TemplateStruct) runInteractiveCommand() (err error) {
 
 func (tstruct *TemplateStruct) InteractiveCommand(cmd string) error {
 	tstruct.Cmd = cmd
+	if tstruct.Interface == RedfishInterface {
+		return fmt.Errorf("%s is not supported by the redfish interface", cmd)
+	}
 	return tstruct.runInteractiveCommand()
 }
 
 func (tstruct *TemplateStruct) Command(cmd string) (string, error) {
-	tstruct.Cmd = cmd
-	out, err := tstruct.runCommand()
-	tstruct.result.out = strings.TrimSpace(string(out))
-	tstruct.result.err = err
+	result := tstruct.commandResult(cmd)
+	tstruct.result.out = result.Output
+	tstruct.result.err = result.err
+	if result.err != nil && result.Output == "" {
+		tstruct.result.out = result.Error
+	}
 	return tstruct.result.out, tstruct.result.err
 }
 
+// commandResult runs the meta command cmd. The Redfish client reports
+// the power state directly; the output of the command template is
+// parsed for it.
+func (tstruct *TemplateStruct) commandResult(cmd string) (result CommandResult) {
+	tstruct.Cmd = cmd
+	if tstruct.Interface == RedfishInterface {
+		result = tstruct.redfishCommand()
+	} else {
+		out, err := tstruct.runCommand()
+		result.Output = strings.TrimSpace(string(out))
+		result.err = err
+		if err == nil && cmd == "PowerStatus" && !tstruct.ShowOnly {
+			result.State = ParsePowerState(result.Output)
+		}
+	}
+	result.Command = cmd
+	if result.err != nil {
+		result.Error = result.err.Error()
+	}
+	return result
+}
+
 /*
 Just define meta commands here, implementation is in the template
 */
@@ -123,6 +151,18 @@ func (tstruct *TemplateStruct) PowerStatus() (string, error) {
 	return tstruct.Command("PowerStatus")
 }
 
+func (tstruct *TemplateStruct) BootDevPXE() (string, error) {
+	return tstruct.Command("BootDevPXE")
+}
+
+func (tstruct *TemplateStruct) BootDevDisk() (string, error) {
+	return tstruct.Command("BootDevDisk")
+}
+
+func (tstruct *TemplateStruct) BootDevBIOS() (string, error) {
+	return tstruct.Command("BootDevBIOS")
+}
+
 func (tstruct *TemplateStruct) SDRList() (string, error) {
 	return tstruct.Command("SDRList")
 }
@@ -0,0 +1,385 @@
+package bmc
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// RedfishInterface is the IpmiConf.Interface value which selects the
+// native Redfish client instead of the command template.
+const RedfishInterface = "redfish"
+
+// Redfish reset types used for the power meta commands.
+const (
+	redfishOn               = "On"
+	redfishForceOff         = "ForceOff"
+	redfishPowerCycle       = "PowerCycle"
+	redfishForceRestart     = "ForceRestart"
+	redfishGracefulShutdown = "GracefulShutdown"
+)
+
+// redfishTimeout bounds every request made to a BMC.
+var redfishTimeout = 30 * time.Second
+
+// Sensor is a single reading reported by a BMC.
+type Sensor struct {
+	Name    string  `json:"name"    yaml:"name"`
+	Reading float64 `json:"reading" yaml:"reading"`
+	Units   string  `json:"units"   yaml:"units"`
+	Health  string  `json:"health"  yaml:"health"`
+}
+
+// Redfish is a client for the DMTF Redfish API of a BMC. The BMC is
+// reached over https at IpmiConf.Ipaddr and, if set, IpmiConf.Port,
+// authenticating with IpmiConf.UserName and IpmiConf.Password. The
+// certificate of the BMC is verified as configured at redfish in
+// warewulf.conf.
+type Redfish struct {
+	node.IpmiConf
+	scheme string
+	client *http.Client
+}
+
+func NewRedfish(conf node.IpmiConf) (*Redfish, error) {
+	tlsConfig, err := redfishTLSConfig(warewulfconf.Get().Redfish)
+	if err != nil {
+		return nil, err
+	}
+	return &Redfish{
+		IpmiConf: conf,
+		scheme:   "https",
+		client: &http.Client{
+			Timeout: redfishTimeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+	}, nil
+}
+
+// redfishTLSConfig verifies BMC certificates against the system roots
+// and the configured CA certificates, unless verification is disabled.
+func redfishTLSConfig(conf *warewulfconf.RedfishConf) (*tls.Config, error) {
+	if conf.Insecure() {
+		// #nosec G402 -- verification is disabled in warewulf.conf
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if conf != nil && conf.CACert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(conf.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("could not read redfish CA certificates: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", conf.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+type redfishLink struct {
+	ID string `json:"@odata.id"`
+}
+
+type redfishCollection struct {
+	Members []redfishLink `json:"Members"`
+}
+
+type redfishStatus struct {
+	State  string `json:"State"`
+	Health string `json:"Health"`
+}
+
+type redfishSystem struct {
+	PowerState string `json:"PowerState"`
+	Actions    map[string]struct {
+		Target string `json:"target"`
+	} `json:"Actions"`
+}
+
+type redfishThermal struct {
+	Temperatures []struct {
+		Name           string        `json:"Name"`
+		ReadingCelsius *float64      `json:"ReadingCelsius"`
+		Status         redfishStatus `json:"Status"`
+	} `json:"Temperatures"`
+	Fans []struct {
+		Name         string        `json:"Name"`
+		Reading      *float64      `json:"Reading"`
+		ReadingUnits string        `json:"ReadingUnits"`
+		Status       redfishStatus `json:"Status"`
+	} `json:"Fans"`
+}
+
+type redfishPower struct {
+	Voltages []struct {
+		Name         string        `json:"Name"`
+		ReadingVolts *float64      `json:"ReadingVolts"`
+		Status       redfishStatus `json:"Status"`
+	} `json:"Voltages"`
+	PowerControl []struct {
+		Name               string        `json:"Name"`
+		PowerConsumedWatts *float64      `json:"PowerConsumedWatts"`
+		Status             redfishStatus `json:"Status"`
+	} `json:"PowerControl"`
+}
+
+// URL returns the base URL of the BMC.
+func (r *Redfish) URL() string {
+	host := ""
+	if r.Ipaddr != nil {
+		host = r.Ipaddr.String()
+	}
+	if r.Port != "" {
+		host = net.JoinHostPort(host, r.Port)
+	}
+	return r.scheme + "://" + host
+}
+
+func (r *Redfish) request(method, resource string, body interface{}, result interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, r.URL()+resource, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.UserName != "" {
+		req.SetBasicAuth(r.UserName, r.Password)
+	}
+
+	wwlog.Debug("redfish request: %s %s", method, req.URL)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("redfish %s %s: %s: %s", method, resource, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("redfish %s %s: could not decode response: %w", method, resource, err)
+	}
+	return nil
+}
+
+// firstMember returns the path of the first member of a collection.
+// Warewulf nodes are expected to have a single system and chassis.
+func (r *Redfish) firstMember(collection string) (string, error) {
+	var members redfishCollection
+	if err := r.request(http.MethodGet, collection, nil, &members); err != nil {
+		return "", err
+	}
+	if len(members.Members) == 0 {
+		return "", fmt.Errorf("redfish %s: no members found", collection)
+	}
+	return members.Members[0].ID, nil
+}
+
+func (r *Redfish) system() (resource string, system redfishSystem, err error) {
+	resource, err = r.firstMember("/redfish/v1/Systems")
+	if err != nil {
+		return
+	}
+	err = r.request(http.MethodGet, resource, nil, &system)
+	return
+}
+
+func (r *Redfish) reset(resetType string) error {
+	resource, system, err := r.system()
+	if err != nil {
+		return err
+	}
+	target := resource + "/Actions/ComputerSystem.Reset"
+	if action, ok := system.Actions["#ComputerSystem.Reset"]; ok && action.Target != "" {
+		target = action.Target
+	}
+	return r.request(http.MethodPost, target, map[string]string{"ResetType": resetType}, nil)
+}
+
+func (r *Redfish) PowerOn() error {
+	return r.reset(redfishOn)
+}
+
+func (r *Redfish) PowerOff() error {
+	return r.reset(redfishForceOff)
+}
+
+func (r *Redfish) PowerCycle() error {
+	return r.reset(redfishPowerCycle)
+}
+
+func (r *Redfish) PowerReset() error {
+	return r.reset(redfishForceRestart)
+}
+
+func (r *Redfish) PowerSoft() error {
+	return r.reset(redfishGracefulShutdown)
+}
+
+// BootDev sets the boot source override of the system to target, e.g.
+// "Pxe", "Hdd" or "BiosSetup", for the next boot or, if persistent, for
+// all following boots.
+func (r *Redfish) BootDev(target string, persistent bool) error {
+	resource, _, err := r.system()
+	if err != nil {
+		return err
+	}
+	enabled := "Once"
+	if persistent {
+		enabled = "Continuous"
+	}
+	boot := map[string]map[string]string{
+		"Boot": {
+			"BootSourceOverrideTarget":  target,
+			"BootSourceOverrideEnabled": enabled,
+		},
+	}
+	return r.request(http.MethodPatch, resource, boot, nil)
+}
+
+// PowerStatus returns the power state reported for the system. The
+// transitional states "PoweringOn" and "PoweringOff", and "Paused", are
+// unknown.
+func (r *Redfish) PowerStatus() (PowerState, error) {
+	_, system, err := r.system()
+	if err != nil {
+		return PowerStateUnknown, err
+	}
+	switch system.PowerState {
+	case "On":
+		return PowerStateOn, nil
+	case "Off":
+		return PowerStateOff, nil
+	default:
+		return PowerStateUnknown, nil
+	}
+}
+
+// SensorList returns the temperature, fan, voltage and power readings
+// of the chassis.
+func (r *Redfish) SensorList() (sensors []Sensor, err error) {
+	chassis, err := r.firstMember("/redfish/v1/Chassis")
+	if err != nil {
+		return nil, err
+	}
+
+	var thermal redfishThermal
+	if err := r.request(http.MethodGet, chassis+"/Thermal", nil, &thermal); err != nil {
+		return nil, err
+	}
+	for _, t := range thermal.Temperatures {
+		if t.ReadingCelsius != nil {
+			sensors = append(sensors, Sensor{Name: t.Name, Reading: *t.ReadingCelsius, Units: "degrees C", Health: t.Status.Health})
+		}
+	}
+	for _, f := range thermal.Fans {
+		if f.Reading != nil {
+			sensors = append(sensors, Sensor{Name: f.Name, Reading: *f.Reading, Units: f.ReadingUnits, Health: f.Status.Health})
+		}
+	}
+
+	var power redfishPower
+	if err := r.request(http.MethodGet, chassis+"/Power", nil, &power); err != nil {
+		return nil, err
+	}
+	for _, v := range power.Voltages {
+		if v.ReadingVolts != nil {
+			sensors = append(sensors, Sensor{Name: v.Name, Reading: *v.ReadingVolts, Units: "Volts", Health: v.Status.Health})
+		}
+	}
+	for _, p := range power.PowerControl {
+		if p.PowerConsumedWatts != nil {
+			sensors = append(sensors, Sensor{Name: p.Name, Reading: *p.PowerConsumedWatts, Units: "Watts", Health: p.Status.Health})
+		}
+	}
+	return sensors, nil
+}
+
+// redfishCommand runs a meta command with the Redfish client. The power
+// state and sensors are returned as reported by the BMC, and the output
+// describes the request that was made.
+func (tstruct *TemplateStruct) redfishCommand() (result CommandResult) {
+	r, err := NewRedfish(tstruct.IpmiConf)
+	if err != nil {
+		result.err = err
+		return
+	}
+	if tstruct.ShowOnly {
+		result.Output = fmt.Sprintf("redfish %s %s", tstruct.Cmd, r.URL())
+		return
+	}
+	resetTypes := map[string]string{
+		"PowerOn":    redfishOn,
+		"PowerOff":   redfishForceOff,
+		"PowerCycle": redfishPowerCycle,
+		"PowerReset": redfishForceRestart,
+		"PowerSoft":  redfishGracefulShutdown,
+	}
+	bootTargets := map[string]string{
+		"BootDevPXE":  "Pxe",
+		"BootDevDisk": "Hdd",
+		"BootDevBIOS": "BiosSetup",
+	}
+	switch tstruct.Cmd {
+	case "PowerOn", "PowerOff", "PowerCycle", "PowerReset", "PowerSoft":
+		resetType := resetTypes[tstruct.Cmd]
+		result.err = r.reset(resetType)
+		result.Output = "ResetType " + resetType
+	case "BootDevPXE", "BootDevDisk", "BootDevBIOS":
+		target := bootTargets[tstruct.Cmd]
+		result.err = r.BootDev(target, tstruct.Persistent)
+		result.Output = "BootSourceOverrideTarget " + target
+	case "PowerStatus":
+		result.State, result.err = r.PowerStatus()
+		result.Output = string(result.State)
+	case "SDRList", "SensorList":
+		result.Sensors, result.err = r.SensorList()
+		var lines []string
+		for _, s := range result.Sensors {
+			lines = append(lines, fmt.Sprintf("%-16s | %-10g %-10s | %s", s.Name, s.Reading, s.Units, s.Health))
+		}
+		result.Output = strings.Join(lines, "\n")
+	default:
+		result.err = fmt.Errorf("%s is not supported by the redfish interface", tstruct.Cmd)
+	}
+	if result.err != nil {
+		result.Output = ""
+		result.State = ""
+		result.Sensors = nil
+	}
+	return
+}
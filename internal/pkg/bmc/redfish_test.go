@@ -0,0 +1,267 @@
+package bmc
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+// fakeRedfish is a minimal Redfish service with a single system and
+// chassis.
+type fakeRedfish struct {
+	lock       sync.Mutex
+	powerState string
+	resets     []string
+	boot       map[string]string
+}
+
+func (f *fakeRedfish) handler() http.Handler {
+	mux := http.NewServeMux()
+	write := func(w http.ResponseWriter, data interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(data)
+	}
+	mux.HandleFunc("GET /redfish/v1/Systems", func(w http.ResponseWriter, r *http.Request) {
+		write(w, map[string]interface{}{"Members": []map[string]string{{"@odata.id": "/redfish/v1/Systems/1"}}})
+	})
+	mux.HandleFunc("GET /redfish/v1/Systems/1", func(w http.ResponseWriter, r *http.Request) {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		write(w, map[string]interface{}{
+			"PowerState": f.powerState,
+			"Actions": map[string]interface{}{
+				"#ComputerSystem.Reset": map[string]string{"target": "/redfish/v1/Systems/1/Actions/ComputerSystem.Reset"},
+			},
+		})
+	})
+	mux.HandleFunc("PATCH /redfish/v1/Systems/1", func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Boot map[string]string }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		f.boot = body.Boot
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /redfish/v1/Systems/1/Actions/ComputerSystem.Reset", func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ ResetType string }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		f.resets = append(f.resets, body.ResetType)
+		switch body.ResetType {
+		case "On", "PowerCycle", "ForceRestart":
+			f.powerState = "On"
+		case "ForceOff", "GracefulShutdown":
+			f.powerState = "Off"
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /redfish/v1/Chassis", func(w http.ResponseWriter, r *http.Request) {
+		write(w, map[string]interface{}{"Members": []map[string]string{{"@odata.id": "/redfish/v1/Chassis/1"}}})
+	})
+	mux.HandleFunc("GET /redfish/v1/Chassis/1/Thermal", func(w http.ResponseWriter, r *http.Request) {
+		write(w, map[string]interface{}{
+			"Temperatures": []map[string]interface{}{
+				{"Name": "CPU1 Temp", "ReadingCelsius": 45, "Status": map[string]string{"Health": "OK"}},
+				{"Name": "Absent Temp", "ReadingCelsius": nil},
+			},
+			"Fans": []map[string]interface{}{
+				{"Name": "Fan1", "Reading": 5400, "ReadingUnits": "RPM", "Status": map[string]string{"Health": "OK"}},
+			},
+		})
+	})
+	mux.HandleFunc("GET /redfish/v1/Chassis/1/Power", func(w http.ResponseWriter, r *http.Request) {
+		write(w, map[string]interface{}{
+			"Voltages": []map[string]interface{}{
+				{"Name": "12V", "ReadingVolts": 12.1, "Status": map[string]string{"Health": "OK"}},
+			},
+			"PowerControl": []map[string]interface{}{
+				{"Name": "System Power", "PowerConsumedWatts": 250, "Status": map[string]string{"Health": "Warning"}},
+			},
+		})
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// newFakeRedfish starts a fakeRedfish server and trusts its certificate
+// with redfish:ca cert.
+func newFakeRedfish(t *testing.T) (*fakeRedfish, node.IpmiConf, func()) {
+	fake := &fakeRedfish{powerState: "Off"}
+	server := httptest.NewTLSServer(fake.handler())
+	caCert := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caCert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+	warewulfconf.New().Redfish = &warewulfconf.RedfishConf{CACert: caCert}
+	serverURL, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+	host, port, err := net.SplitHostPort(serverURL.Host)
+	assert.NoError(t, err)
+	conf := node.IpmiConf{
+		Interface: RedfishInterface,
+		Ipaddr:    net.ParseIP(host),
+		Port:      port,
+		UserName:  "admin",
+		Password:  "secret",
+	}
+	return fake, conf, server.Close
+}
+
+func Test_Redfish(t *testing.T) {
+	fake, conf, cleanup := newFakeRedfish(t)
+	defer cleanup()
+	r, err := NewRedfish(conf)
+	assert.NoError(t, err)
+
+	state, err := r.PowerStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, PowerStateOff, state)
+
+	assert.NoError(t, r.PowerOn())
+	state, err = r.PowerStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, PowerStateOn, state)
+
+	fake.powerState = "PoweringOn"
+	state, err = r.PowerStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, PowerStateUnknown, state)
+	fake.powerState = "On"
+
+	assert.NoError(t, r.PowerCycle())
+	assert.NoError(t, r.PowerReset())
+	assert.NoError(t, r.PowerSoft())
+	assert.NoError(t, r.PowerOff())
+	assert.Equal(t, []string{"On", "PowerCycle", "ForceRestart", "GracefulShutdown", "ForceOff"}, fake.resets)
+
+	assert.NoError(t, r.BootDev("Pxe", false))
+	assert.Equal(t, map[string]string{"BootSourceOverrideTarget": "Pxe", "BootSourceOverrideEnabled": "Once"}, fake.boot)
+	assert.NoError(t, r.BootDev("Hdd", true))
+	assert.Equal(t, map[string]string{"BootSourceOverrideTarget": "Hdd", "BootSourceOverrideEnabled": "Continuous"}, fake.boot)
+
+	sensors, err := r.SensorList()
+	assert.NoError(t, err)
+	assert.Equal(t, []Sensor{
+		{Name: "CPU1 Temp", Reading: 45, Units: "degrees C", Health: "OK"},
+		{Name: "Fan1", Reading: 5400, Units: "RPM", Health: "OK"},
+		{Name: "12V", Reading: 12.1, Units: "Volts", Health: "OK"},
+		{Name: "System Power", Reading: 250, Units: "Watts", Health: "Warning"},
+	}, sensors)
+
+	conf.Password = "wrong"
+	r, err = NewRedfish(conf)
+	assert.NoError(t, err)
+	_, err = r.PowerStatus()
+	assert.Error(t, err)
+}
+
+func Test_RedfishVerify(t *testing.T) {
+	_, conf, cleanup := newFakeRedfish(t)
+	defer cleanup()
+
+	insecure := true
+	tests := map[string]struct {
+		redfish *warewulfconf.RedfishConf
+		err     bool
+	}{
+		"verify by default": {
+			redfish: nil,
+			err:     true,
+		},
+		"insecure": {
+			redfish: &warewulfconf.RedfishConf{InsecureP: &insecure},
+			err:     false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			warewulfconf.New().Redfish = tt.redfish
+			r, err := NewRedfish(conf)
+			assert.NoError(t, err)
+			_, err = r.PowerStatus()
+			if tt.err {
+				assert.ErrorContains(t, err, "certificate")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	warewulfconf.New().Redfish = &warewulfconf.RedfishConf{CACert: filepath.Join(t.TempDir(), "missing.pem")}
+	_, err := NewRedfish(conf)
+	assert.Error(t, err)
+}
+
+func Test_RedfishTemplateStruct(t *testing.T) {
+	fake, conf, cleanup := newFakeRedfish(t)
+	defer cleanup()
+
+	tests := map[string]struct {
+		cmd      string
+		showOnly bool
+		out      string
+		err      bool
+	}{
+		"show": {
+			cmd:      "PowerOn",
+			showOnly: true,
+			out:      "redfish PowerOn https://" + net.JoinHostPort(conf.Ipaddr.String(), conf.Port),
+		},
+		"power on": {
+			cmd: "PowerOn",
+			out: "ResetType On",
+		},
+		"unsupported": {
+			cmd: "Unsupported",
+			err: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			bmc := TemplateStruct{IpmiConf: conf, ShowOnly: tt.showOnly}
+			out, err := bmc.Command(tt.cmd)
+			if tt.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+
+	fake.powerState = "On"
+	bmc := TemplateStruct{IpmiConf: conf}
+	result := bmc.commandResult("PowerStatus")
+	assert.NoError(t, result.Err())
+	assert.Equal(t, PowerStateOn, result.State)
+	assert.Equal(t, "on", result.Output)
+
+	result = bmc.commandResult("SensorList")
+	assert.NoError(t, result.Err())
+	assert.Len(t, result.Sensors, 4)
+	assert.Equal(t, "CPU1 Temp", result.Sensors[0].Name)
+	assert.Error(t, bmc.Console())
+}
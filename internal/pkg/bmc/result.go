@@ -0,0 +1,112 @@
+package bmc
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/warewulf/warewulf/internal/pkg/batch"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+// PowerState is the normalized power state of a node.
+type PowerState string
+
+const (
+	PowerStateOn      PowerState = "on"
+	PowerStateOff     PowerState = "off"
+	PowerStateUnknown PowerState = "unknown"
+)
+
+// ParsePowerState normalizes the output of a PowerStatus command
+// template, e.g. "Chassis Power is on" from ipmitool or "ON" from the
+// nobmc template.
+func ParsePowerState(out string) PowerState {
+	fields := strings.Fields(strings.ToLower(out))
+	if len(fields) == 0 {
+		return PowerStateUnknown
+	}
+	switch fields[len(fields)-1] {
+	case "on":
+		return PowerStateOn
+	case "off":
+		return PowerStateOff
+	default:
+		return PowerStateUnknown
+	}
+}
+
+// CommandResult is the machine-readable result of running a meta
+// command against the BMC of a single node.
+type CommandResult struct {
+	Node     string     `json:"node"              yaml:"node"`
+	Ipaddr   string     `json:"ipaddr"            yaml:"ipaddr"`
+	Command  string     `json:"command"           yaml:"command"`
+	State    PowerState `json:"state,omitempty"   yaml:"state,omitempty"`
+	Output   string     `json:"output"            yaml:"output"`
+	Error    string     `json:"error,omitempty"   yaml:"error,omitempty"`
+	Sensors  []Sensor   `json:"sensors,omitempty" yaml:"sensors,omitempty"`
+	Skipped  bool       `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+	Duration float64    `json:"duration"          yaml:"duration"`
+
+	err error
+}
+
+// Err returns the error of the command, if any. Skipped nodes have no
+// error.
+func (result CommandResult) Err() error {
+	return result.err
+}
+
+// RunCommand runs the meta command cmd against the BMC of each node,
+// with at most fanout commands in parallel, and returns the results
+// sorted by node name. Nodes without an IPMI address are reported as
+// skipped without running the command.
+func RunCommand(nodes []node.Node, cmd string, fanout int, showOnly bool) []CommandResult {
+	return runCommand(nodes, cmd, fanout, TemplateStruct{ShowOnly: showOnly})
+}
+
+// RunBootDevCommand runs one of the BootDev meta commands like
+// RunCommand, setting the boot device for the next boot only or, if
+// persistent, for all following boots.
+func RunBootDevCommand(nodes []node.Node, cmd string, persistent bool, fanout int, showOnly bool) []CommandResult {
+	return runCommand(nodes, cmd, fanout, TemplateStruct{ShowOnly: showOnly, Persistent: persistent})
+}
+
+func runCommand(nodes []node.Node, cmd string, fanout int, options TemplateStruct) []CommandResult {
+	batchpool := batch.New(fanout)
+	results := make(chan CommandResult, len(nodes))
+
+	for _, n := range nodes {
+		if n.Ipmi == nil || n.Ipmi.Ipaddr == nil || n.Ipmi.Ipaddr.IsUnspecified() {
+			results <- CommandResult{Node: n.Id(), Command: cmd, Error: "no IPMI IP address", Skipped: true}
+			continue
+		}
+		ipmiCmd := TemplateStruct{
+			IpmiConf:   *n.Ipmi,
+			ShowOnly:   options.ShowOnly,
+			Persistent: options.Persistent,
+		}
+		nodeName := n.Id()
+		batchpool.Submit(func() {
+			start := time.Now()
+			result := ipmiCmd.commandResult(cmd)
+			result.Node = nodeName
+			result.Ipaddr = ipmiCmd.Ipaddr.String()
+			result.Duration = time.Since(start).Seconds()
+			results <- result
+		})
+	}
+
+	batchpool.Run()
+	close(results)
+
+	var ret []CommandResult
+	for result := range results {
+		ret = append(ret, result)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Node < ret[j].Node
+	})
+	return ret
+}
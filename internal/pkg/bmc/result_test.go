@@ -0,0 +1,52 @@
+package bmc
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+func Test_ParsePowerState(t *testing.T) {
+	tests := map[string]PowerState{
+		"Chassis Power is on":  PowerStateOn,
+		"Chassis Power is off": PowerStateOff,
+		"ON":                   PowerStateOn,
+		"OFF\n":                PowerStateOff,
+		"":                     PowerStateUnknown,
+		"Error: Unable to establish IPMI v2 / RMCP+ session": PowerStateUnknown,
+	}
+	for out, state := range tests {
+		t.Run(out, func(t *testing.T) {
+			assert.Equal(t, state, ParsePowerState(out))
+		})
+	}
+}
+
+func Test_RunCommand(t *testing.T) {
+	_, conf, cleanup := newFakeRedfish(t)
+	defer cleanup()
+
+	n1 := node.NewNode("n1")
+	n1.Ipmi = &conf
+	n2 := node.NewNode("n2")
+	n2.Ipmi = &node.IpmiConf{Ipaddr: net.IPv4zero}
+
+	results := RunCommand([]node.Node{n2, n1}, "PowerStatus", 2, false)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "n1", results[0].Node)
+		assert.Equal(t, conf.Ipaddr.String(), results[0].Ipaddr)
+		assert.Equal(t, "PowerStatus", results[0].Command)
+		assert.Equal(t, PowerStateOff, results[0].State)
+		assert.Equal(t, "off", results[0].Output)
+		assert.NoError(t, results[0].Err())
+		assert.Empty(t, results[0].Error)
+
+		assert.Equal(t, "n2", results[1].Node)
+		assert.NoError(t, results[1].Err())
+		assert.True(t, results[1].Skipped)
+		assert.Equal(t, "no IPMI IP address", results[1].Error)
+		assert.Empty(t, results[1].State)
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// APIToken is a bearer token for the REST API. Only the SHA-256 hash
+// of the token is stored.
+type APIToken struct {
+	Name      string    `json:"name"    yaml:"name"`
+	Role      Role      `json:"role"    yaml:"role"`
+	TokenHash string    `json:"-"       yaml:"token hash"`
+	Created   time.Time `json:"created" yaml:"created"`
+}
+
+type APITokens struct {
+	Tokens []APIToken `json:"tokens" yaml:"tokens"`
+}
+
+// ReadAPITokens reads the API tokens from fileName. A missing file
+// holds no tokens.
+func ReadAPITokens(fileName string) (*APITokens, error) {
+	tokens := new(APITokens)
+	data, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, tokens); err != nil {
+		return nil, err
+	}
+	for _, token := range tokens.Tokens {
+		if !token.Role.Valid() {
+			return nil, fmt.Errorf("unknown role for API token %s: %s", token.Name, token.Role)
+		}
+	}
+	return tokens, nil
+}
+
+// Write writes the API tokens to fileName, readable only by its owner.
+func (tokens *APITokens) Write(fileName string) error {
+	data, err := yaml.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path.Dir(fileName), 0755); err != nil {
+		return err
+	}
+	tmpFile := fileName + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, fileName)
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create adds a new token with the given name and role and returns the
+// token. The token itself is not stored and can't be shown again.
+func (tokens *APITokens) Create(name string, role Role) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("API token name must not be empty")
+	}
+	if !role.Valid() {
+		return "", fmt.Errorf("unknown role: %s", role)
+	}
+	for _, token := range tokens.Tokens {
+		if token.Name == name {
+			return "", fmt.Errorf("API token already exists: %s", name)
+		}
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	tokens.Tokens = append(tokens.Tokens, APIToken{
+		Name:      name,
+		Role:      role,
+		TokenHash: hashAPIToken(token),
+		Created:   time.Now().UTC().Truncate(time.Second),
+	})
+	return token, nil
+}
+
+// Revoke removes the token with the given name.
+func (tokens *APITokens) Revoke(name string) error {
+	for i, token := range tokens.Tokens {
+		if token.Name == name {
+			tokens.Tokens = append(tokens.Tokens[:i], tokens.Tokens[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("API token not found: %s", name)
+}
+
+// Authenticate returns the stored token matching token.
+func (tokens *APITokens) Authenticate(token string) (*APIToken, error) {
+	hash := []byte(hashAPIToken(token))
+	for i := range tokens.Tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(tokens.Tokens[i].TokenHash)) == 1 {
+			return &tokens.Tokens[i], nil
+		}
+	}
+	return nil, UnauthorizedError
+}
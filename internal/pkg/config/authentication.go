@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/bcrypt"
@@ -11,14 +13,59 @@ import (
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
 
+// Role is the level of access granted to an API user or token.
+type Role string
+
+const (
+	// RoleReadOnly may only read resources.
+	RoleReadOnly Role = "read-only"
+	// RoleOperator may additionally run operational tasks, like
+	// building overlays and images.
+	RoleOperator Role = "operator"
+	// RoleAdmin may do everything.
+	RoleAdmin Role = "admin"
+)
+
+var roleLevel = map[Role]int{
+	RoleReadOnly: 1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// Valid reports whether role is a known role.
+func (role Role) Valid() bool {
+	_, ok := roleLevel[role]
+	return ok
+}
+
+// Allows reports whether role includes the permissions of required.
+func (role Role) Allows(required Role) bool {
+	return role.Valid() && roleLevel[role] >= roleLevel[required]
+}
+
 type User struct {
-	Name         string `json:"name"          yaml:"name"`
-	PasswordHash string `json:"password hash" yaml:"password hash"`
+	Name         string `json:"name"           yaml:"name"`
+	PasswordHash string `json:"password hash"  yaml:"password hash"`
+	RoleName     Role   `json:"role,omitempty" yaml:"role,omitempty"`
+}
+
+// Role returns the role of the user. Users without a configured role
+// are administrators.
+func (user User) Role() Role {
+	if user.RoleName == "" {
+		return RoleAdmin
+	}
+	return user.RoleName
 }
 
 type Authentication struct {
 	Users   []User          `json:"users" yaml:"users"`
 	userMap map[string]User `json:"-"     yaml:"-"`
+
+	tokenLock    sync.Mutex
+	tokenFile    string
+	tokens       *APITokens
+	tokenModTime time.Time
 }
 
 func NewAuthentication() *Authentication {
@@ -39,6 +86,9 @@ func (auth *Authentication) ParseFromRaw(data []byte) error {
 		if _, ok := auth.userMap[user.Name]; ok {
 			return fmt.Errorf("duplicated user names")
 		}
+		if !user.Role().Valid() {
+			return fmt.Errorf("unknown role for user %s: %s", user.Name, user.RoleName)
+		}
 		auth.userMap[user.Name] = user
 	}
 	return nil
@@ -70,3 +120,35 @@ func (auth *Authentication) Authenticate(name, pass string) (*User, error) {
 		return &user, nil
 	}
 }
+
+// SetTokenFile configures the file with the API tokens accepted by
+// AuthenticateToken. The file is read again whenever it changes.
+func (auth *Authentication) SetTokenFile(fileName string) {
+	auth.tokenLock.Lock()
+	defer auth.tokenLock.Unlock()
+	auth.tokenFile = fileName
+	auth.tokens = nil
+}
+
+func (auth *Authentication) AuthenticateToken(token string) (*APIToken, error) {
+	auth.tokenLock.Lock()
+	defer auth.tokenLock.Unlock()
+	if auth.tokenFile == "" {
+		return nil, UnauthorizedError
+	}
+	if info, err := os.Stat(auth.tokenFile); err != nil {
+		if !os.IsNotExist(err) {
+			wwlog.Warn("could not read API tokens: %s", err)
+		}
+		return nil, UnauthorizedError
+	} else if auth.tokens == nil || !info.ModTime().Equal(auth.tokenModTime) {
+		tokens, err := ReadAPITokens(auth.tokenFile)
+		if err != nil {
+			wwlog.Warn("could not read API tokens: %s", err)
+			return nil, UnauthorizedError
+		}
+		auth.tokens = tokens
+		auth.tokenModTime = info.ModTime()
+	}
+	return auth.tokens.Authenticate(token)
+}
@@ -0,0 +1,13 @@
+package config
+
+// NodeDBConf selects the storage backend of nodes and profiles, and
+// whether problems of the node configuration prevent it from being
+// written.
+type NodeDBConf struct {
+	Backend string `yaml:"backend,omitempty" default:"yaml"`
+	StrictP *bool  `yaml:"strict,omitempty"`
+}
+
+func (conf NodeDBConf) Strict() bool {
+	return BoolP(conf.StrictP)
+}
@@ -0,0 +1,14 @@
+package config
+
+// RedfishConf configures the TLS connection of the native Redfish
+// client to BMCs. Certificates are verified against the system roots
+// and, if set, the certificates in the CA cert file. Verification is
+// only disabled if insecure is set.
+type RedfishConf struct {
+	InsecureP *bool  `yaml:"insecure,omitempty"`
+	CACert    string `yaml:"ca cert,omitempty"`
+}
+
+func (conf *RedfishConf) Insecure() bool {
+	return conf != nil && BoolP(conf.InsecureP)
+}
@@ -19,8 +19,12 @@ warewulf:
   autobuild overlays: true
   grubboot: false
   host overlay: true
+  ipxe https: false
   port: 9873
   secure: true
+  secure system overlay: false
+  tls: false
+  tls port: 9874
   update interval: 60
 nfs:
   enabled: true
@@ -51,6 +55,64 @@ api:
   allowed subnets:
   - 127.0.0.0/8
   - ::1/128
+nodedb:
+  backend: yaml
+`,
+		},
+		"signature": {
+			input: `
+signature:
+  verify: true
+  keys:
+  - /etc/warewulf/cosign.pub
+`,
+			result: `
+warewulf:
+  autobuild overlays: true
+  grubboot: false
+  host overlay: true
+  ipxe https: false
+  port: 9873
+  secure: true
+  secure system overlay: false
+  tls: false
+  tls port: 9874
+  update interval: 60
+nfs:
+  enabled: true
+  systemd name: nfsd
+dhcp:
+  enabled: true
+  systemd name: dhcpd
+  template: default
+image mounts:
+- dest: /etc/resolv.conf
+  source: /etc/resolv.conf
+ssh:
+  key types:
+  - ed25519
+  - ecdsa
+  - rsa
+  - dsa
+tftp:
+  enabled: true
+  ipxe:
+    "00:00": undionly.kpxe
+    "00:07": ipxe-snponly-x86_64.efi
+    "00:09": ipxe-snponly-x86_64.efi
+    "00:0B": arm64-efi/snponly.efi
+  systemd name: tftp
+api:
+  enabled: false
+  allowed subnets:
+  - 127.0.0.0/8
+  - ::1/128
+nodedb:
+  backend: yaml
+signature:
+  verify: true
+  keys:
+  - /etc/warewulf/cosign.pub
 `,
 		},
 		"cidr": {
@@ -65,8 +127,12 @@ warewulf:
   autobuild overlays: true
   grubboot: false
   host overlay: true
+  ipxe https: false
   port: 9873
   secure: true
+  secure system overlay: false
+  tls: false
+  tls port: 9874
   update interval: 60
 nfs:
   enabled: true
@@ -97,6 +163,8 @@ api:
   allowed subnets:
   - 127.0.0.0/8
   - ::1/128
+nodedb:
+  backend: yaml
 `,
 		},
 		"cidr with conflicts": {
@@ -113,8 +181,12 @@ warewulf:
   autobuild overlays: true
   grubboot: false
   host overlay: true
+  ipxe https: false
   port: 9873
   secure: true
+  secure system overlay: false
+  tls: false
+  tls port: 9874
   update interval: 60
 nfs:
   enabled: true
@@ -145,6 +217,8 @@ api:
   allowed subnets:
   - 127.0.0.0/8
   - ::1/128
+nodedb:
+  backend: yaml
 `,
 		},
 		"ipv6 cidr": {
@@ -158,8 +232,12 @@ warewulf:
   autobuild overlays: true
   grubboot: false
   host overlay: true
+  ipxe https: false
   port: 9873
   secure: true
+  secure system overlay: false
+  tls: false
+  tls port: 9874
   update interval: 60
 nfs:
   enabled: true
@@ -190,6 +268,8 @@ api:
   allowed subnets:
   - 127.0.0.0/8
   - ::1/128
+nodedb:
+  backend: yaml
 `,
 		},
 		"ipv6 cidr conflict": {
@@ -204,8 +284,12 @@ warewulf:
   autobuild overlays: true
   grubboot: false
   host overlay: true
+  ipxe https: false
   port: 9873
   secure: true
+  secure system overlay: false
+  tls: false
+  tls port: 9874
   update interval: 60
 nfs:
   enabled: true
@@ -236,6 +320,8 @@ api:
   allowed subnets:
   - 127.0.0.0/8
   - ::1/128
+nodedb:
+  backend: yaml
 `,
 		},
 		"example": {
@@ -278,8 +364,12 @@ warewulf:
   autobuild overlays: true
   grubboot: false
   host overlay: true
+  ipxe https: false
   port: 9873
   secure: false
+  secure system overlay: false
+  tls: false
+  tls port: 9874
   update interval: 60
 nfs:
   enabled: true
@@ -318,6 +408,8 @@ api:
   allowed subnets:
   - 127.0.0.0/8
   - ::1/128
+nodedb:
+  backend: yaml
 `,
 		},
 	}
@@ -0,0 +1,14 @@
+package config
+
+// SignatureConf selects how the signatures of imported images are
+// verified: with a containers-policy.json(5) file, or with sigstore
+// (cosign) public keys. If verify is set, every import is verified.
+type SignatureConf struct {
+	VerifyP *bool    `yaml:"verify,omitempty"`
+	Policy  string   `yaml:"policy,omitempty"`
+	Keys    []string `yaml:"keys,omitempty"`
+}
+
+func (conf *SignatureConf) Verify() bool {
+	return conf != nil && BoolP(conf.VerifyP)
+}
@@ -0,0 +1,232 @@
+/*
+Package discovery keeps the queue of unknown machines which requested
+to be provisioned but did not match a discoverable node. An
+administrator accepts a pending machine as a node, or rejects it.
+*/
+package discovery
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+var (
+	ErrNotFound  = errors.New("machine is not pending discovery")
+	ErrQueueFull = errors.New("discovery queue is full")
+)
+
+const (
+	// MaxPending is the number of machines which may be pending
+	// discovery at once, not counting rejected machines.
+	MaxPending = 1024
+	// PendingExpiry is how long a machine which is not seen again
+	// remains pending. Rejected machines don't expire.
+	PendingExpiry = 7 * 24 * time.Hour
+	// lastSeenInterval limits how often the queue is written only to
+	// update when a machine was last seen.
+	lastSeenInterval = time.Minute
+)
+
+// errUnchanged is returned by the functions passed to update if the
+// queue was not changed and needn't be written.
+var errUnchanged = errors.New("unchanged")
+
+// Pending is an unknown machine in the discovery queue.
+type Pending struct {
+	node.Machine
+	Ipaddr    string    `json:"ipaddr,omitempty"`
+	FirstSeen time.Time `json:"first seen"`
+	LastSeen  time.Time `json:"last seen"`
+	Rejected  bool      `json:"rejected,omitempty"`
+}
+
+// List returns the machines in the queue, oldest first.
+func List() (pending []Pending, err error) {
+	queue, err := read()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range queue {
+		pending = append(pending, *p)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].FirstSeen.Equal(pending[j].FirstSeen) {
+			return pending[i].Hwaddr < pending[j].Hwaddr
+		}
+		return pending[i].FirstSeen.Before(pending[j].FirstSeen)
+	})
+	return pending, nil
+}
+
+// Get returns the queued machine with the hardware address.
+func Get(hwaddr string) (Pending, error) {
+	queue, err := read()
+	if err != nil {
+		return Pending{}, err
+	}
+	if p, ok := queue[strings.ToLower(hwaddr)]; ok {
+		return *p, nil
+	}
+	return Pending{}, ErrNotFound
+}
+
+/*
+Record adds the machine to the queue, or updates when it was last seen
+and what it reported. It returns the queued machine and whether it was
+added. Machines which were not seen for PendingExpiry are removed, and
+ErrQueueFull is returned if MaxPending machines are already pending.
+*/
+func Record(machine node.Machine, ipaddr string) (pending Pending, added bool, err error) {
+	machine.Hwaddr = strings.ToLower(machine.Hwaddr)
+	err = update(func(queue map[string]*Pending) error {
+		now := time.Now().UTC().Truncate(time.Second)
+		expired := expire(queue, now)
+		p, ok := queue[machine.Hwaddr]
+		if !ok {
+			if countPending(queue) >= MaxPending {
+				return ErrQueueFull
+			}
+			p = &Pending{Machine: node.Machine{Hwaddr: machine.Hwaddr}, FirstSeen: now}
+			queue[machine.Hwaddr] = p
+			added = true
+		}
+		before := *p
+		// later stages may report more than iPXE did
+		if machine.UUID != "" {
+			p.UUID = machine.UUID
+		}
+		if machine.Serial != "" {
+			p.Serial = machine.Serial
+		}
+		if machine.AssetTag != "" {
+			p.AssetTag = machine.AssetTag
+		}
+		if machine.SwitchPort != "" {
+			p.SwitchPort = machine.SwitchPort
+		}
+		if ipaddr != "" {
+			p.Ipaddr = ipaddr
+		}
+		if added || expired || *p != before || now.Sub(p.LastSeen) >= lastSeenInterval {
+			p.LastSeen = now
+			pending = *p
+			return nil
+		}
+		pending = *p
+		return errUnchanged
+	})
+	return pending, added, err
+}
+
+// expire removes the machines which were not seen for PendingExpiry,
+// unless they were rejected, and reports whether any were removed.
+func expire(queue map[string]*Pending, now time.Time) (expired bool) {
+	for hwaddr, p := range queue {
+		if !p.Rejected && now.Sub(p.LastSeen) > PendingExpiry {
+			wwlog.Verbose("%s (machine pending discovery expired)", hwaddr)
+			delete(queue, hwaddr)
+			expired = true
+		}
+	}
+	return expired
+}
+
+// countPending returns the number of machines in the queue which were
+// not rejected.
+func countPending(queue map[string]*Pending) (count int) {
+	for _, p := range queue {
+		if !p.Rejected {
+			count++
+		}
+	}
+	return count
+}
+
+// Reject marks the queued machine as rejected, so that it is not
+// discovered as a node.
+func Reject(hwaddr string) error {
+	return update(func(queue map[string]*Pending) error {
+		p, ok := queue[strings.ToLower(hwaddr)]
+		if !ok {
+			return ErrNotFound
+		}
+		p.Rejected = true
+		return nil
+	})
+}
+
+// Remove removes the machine from the queue.
+func Remove(hwaddr string) error {
+	return update(func(queue map[string]*Pending) error {
+		if _, ok := queue[strings.ToLower(hwaddr)]; !ok {
+			return ErrNotFound
+		}
+		delete(queue, strings.ToLower(hwaddr))
+		return nil
+	})
+}
+
+func read() (map[string]*Pending, error) {
+	queue := make(map[string]*Pending)
+	data, err := os.ReadFile(warewulfconf.Get().Paths.DiscoveryQueueFile())
+	if os.IsNotExist(err) {
+		return queue, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return nil, err
+	}
+	return queue, nil
+}
+
+// update changes the queue with fn while holding a lock against
+// warewulfd and wwctl changing it at the same time.
+func update(fn func(map[string]*Pending) error) error {
+	fileName := warewulfconf.Get().Paths.DiscoveryQueueFile()
+	if err := os.MkdirAll(path.Dir(fileName), 0755); err != nil {
+		return err
+	}
+	lock, err := os.OpenFile(fileName+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return err
+	}
+	defer func() {
+		if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_UN); err != nil {
+			wwlog.Warn("could not unlock %s: %s", lock.Name(), err)
+		}
+	}()
+
+	queue, err := read()
+	if err != nil {
+		return err
+	}
+	if err := fn(queue); errors.Is(err, errUnchanged) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFile := fileName + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, fileName)
+}
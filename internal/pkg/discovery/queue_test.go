@@ -0,0 +1,131 @@
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Queue(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	pending, err := List()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	p, added, err := Record(node.Machine{Hwaddr: "00:00:00:00:00:0A", UUID: "uuid-a"}, "10.0.0.10")
+	assert.NoError(t, err)
+	assert.True(t, added)
+	assert.Equal(t, "00:00:00:00:00:0a", p.Hwaddr)
+
+	p, added, err = Record(node.Machine{Hwaddr: "00:00:00:00:00:0a", Serial: "S1", SwitchPort: "sw1:Ethernet1/1"}, "")
+	assert.NoError(t, err)
+	assert.False(t, added)
+	assert.Equal(t, "uuid-a", p.UUID)
+	assert.Equal(t, "S1", p.Serial)
+	assert.Equal(t, "sw1:Ethernet1/1", p.SwitchPort)
+	assert.Equal(t, "10.0.0.10", p.Ipaddr)
+
+	_, _, err = Record(node.Machine{Hwaddr: "00:00:00:00:00:0b"}, "10.0.0.11")
+	assert.NoError(t, err)
+
+	assert.NoError(t, Reject("00:00:00:00:00:0b"))
+	p, err = Get("00:00:00:00:00:0B")
+	assert.NoError(t, err)
+	assert.True(t, p.Rejected)
+	assert.ErrorIs(t, Reject("00:00:00:00:00:0c"), ErrNotFound)
+
+	pending, err = List()
+	assert.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	assert.NoError(t, Remove("00:00:00:00:00:0a"))
+	assert.ErrorIs(t, Remove("00:00:00:00:00:0a"), ErrNotFound)
+	_, err = Get("00:00:00:00:00:0a")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func Test_QueueExpiry(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	old := time.Now().UTC().Add(-PendingExpiry - time.Hour)
+	assert.NoError(t, update(func(queue map[string]*Pending) error {
+		queue["00:00:00:00:00:0a"] = &Pending{Machine: node.Machine{Hwaddr: "00:00:00:00:00:0a"}, FirstSeen: old, LastSeen: old}
+		queue["00:00:00:00:00:0b"] = &Pending{Machine: node.Machine{Hwaddr: "00:00:00:00:00:0b"}, FirstSeen: old, LastSeen: old, Rejected: true}
+		return nil
+	}))
+
+	_, added, err := Record(node.Machine{Hwaddr: "00:00:00:00:00:0c"}, "")
+	assert.NoError(t, err)
+	assert.True(t, added)
+
+	_, err = Get("00:00:00:00:00:0a")
+	assert.ErrorIs(t, err, ErrNotFound)
+	p, err := Get("00:00:00:00:00:0b")
+	assert.NoError(t, err)
+	assert.True(t, p.Rejected)
+}
+
+func Test_QueueFull(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	now := time.Now().UTC()
+	assert.NoError(t, update(func(queue map[string]*Pending) error {
+		for i := 0; i < MaxPending; i++ {
+			hwaddr := fmt.Sprintf("00:00:00:00:%02x:%02x", i/256, i%256)
+			queue[hwaddr] = &Pending{Machine: node.Machine{Hwaddr: hwaddr}, FirstSeen: now, LastSeen: now}
+		}
+		return nil
+	}))
+
+	_, _, err := Record(node.Machine{Hwaddr: "00:00:00:00:ff:ff"}, "")
+	assert.ErrorIs(t, err, ErrQueueFull)
+	_, err = Get("00:00:00:00:ff:ff")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// machines already in the queue are still updated
+	p, added, err := Record(node.Machine{Hwaddr: "00:00:00:00:00:01", Serial: "S1"}, "")
+	assert.NoError(t, err)
+	assert.False(t, added)
+	assert.Equal(t, "S1", p.Serial)
+
+	// rejected machines don't count
+	assert.NoError(t, Reject("00:00:00:00:00:01"))
+	_, added, err = Record(node.Machine{Hwaddr: "00:00:00:00:ff:ff"}, "")
+	assert.NoError(t, err)
+	assert.True(t, added)
+}
+
+func Test_QueueUnchanged(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	_, _, err := Record(node.Machine{Hwaddr: "00:00:00:00:00:0a", UUID: "uuid-a"}, "10.0.0.10")
+	assert.NoError(t, err)
+	fileName := warewulfconf.Get().Paths.DiscoveryQueueFile()
+	before, err := os.Stat(fileName)
+	assert.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+
+	_, _, err = Record(node.Machine{Hwaddr: "00:00:00:00:00:0a", UUID: "uuid-a"}, "10.0.0.10")
+	assert.NoError(t, err)
+	after, err := os.Stat(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, before.ModTime(), after.ModTime())
+
+	_, _, err = Record(node.Machine{Hwaddr: "00:00:00:00:00:0a", Serial: "S1"}, "")
+	assert.NoError(t, err)
+	after, err = os.Stat(fileName)
+	assert.NoError(t, err)
+	assert.NotEqual(t, before.ModTime(), after.ModTime())
+}
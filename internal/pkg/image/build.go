@@ -28,17 +28,12 @@ func Build(name string, buildForce bool) error {
 		}
 	}
 
-	ignore := []string{}
-	excludes_file := path.Join(rootfsPath, "./etc/warewulf/excludes")
-	if util.IsFile(excludes_file) {
-		var err error
-		ignore, err = util.ReadFile(excludes_file)
-		if err != nil {
-			return fmt.Errorf("failed creating directory: %s: %w", imagePath, err)
-		}
+	ignore, err := excludes(name)
+	if err != nil {
+		return fmt.Errorf("failed creating directory: %s: %w", imagePath, err)
 	}
 
-	err := util.BuildFsImage(
+	err = util.BuildFsImage(
 		"Image "+name,
 		rootfsPath,
 		imagePath,
@@ -50,3 +45,13 @@ func Build(name string, buildForce bool) error {
 
 	return err
 }
+
+// excludes returns the patterns of the files which are left out of an
+// image, as listed in its /etc/warewulf/excludes.
+func excludes(name string) ([]string, error) {
+	excludesFile := path.Join(RootFsDir(name), "./etc/warewulf/excludes")
+	if !util.IsFile(excludesFile) {
+		return []string{}, nil
+	}
+	return util.ReadFile(excludesFile)
+}
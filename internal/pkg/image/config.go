@@ -35,3 +35,15 @@ func ImageFile(name string) string {
 func CompressedImageFile(name string) string {
 	return ImageFile(name) + ".gz"
 }
+
+func MetadataFile(name string) string {
+	return path.Join(SourceDir(name), "metadata.json")
+}
+
+func SnapshotParentDir(name string) string {
+	return path.Join(SourceDir(name), "snapshots")
+}
+
+func SnapshotDir(name string, snapshot string) string {
+	return path.Join(SnapshotParentDir(name), snapshot)
+}
@@ -0,0 +1,131 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/containers/image/v5/types"
+	imgSpecs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/oci"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// Annotations which Export adds to the manifests and, as labels, to the
+// configs of exported images, besides the pre-defined OCI annotations.
+const (
+	AnnotationName       = "org.warewulf.image.name"
+	AnnotationSource     = "org.warewulf.image.source"
+	AnnotationDigest     = "org.warewulf.image.digest"
+	AnnotationParent     = "org.warewulf.image.parent"
+	AnnotationExportedBy = "org.warewulf.image.exported-by"
+	AnnotationVersion    = "org.warewulf.version"
+)
+
+/*
+Export packs the rootfs of an image into an OCI image with a single
+layer and writes it to dest, a docker://, docker-archive:, oci-archive:,
+or oci: uri. Files matching the patterns in /etc/warewulf/excludes of
+the image are left out, as they are when the image is built.
+
+The provenance of the image is recorded in annotations, and the commands
+run in it are recorded in the history of the exported image.
+*/
+func Export(name string, dest string, sCtx *types.SystemContext) error {
+	if !ValidSource(name) {
+		return fmt.Errorf("image does not exist: %s", name)
+	}
+	md, err := ReadMetadata(name)
+	if err != nil {
+		return err
+	}
+	ignore, err := excludes(name)
+	if err != nil {
+		return fmt.Errorf("could not read excludes of image %s: %w", name, err)
+	}
+	files, err := util.FindFilterFiles(RootFsDir(name), []string{"*"}, ignore, true)
+	if err != nil {
+		return fmt.Errorf("failed discovering files for %s: %w", name, err)
+	}
+
+	now := time.Now().UTC()
+	annotations := exportAnnotations(name, md, now)
+	config := exportConfig(md, now)
+	config.Config.Labels = annotations
+	wwlog.Info("Exporting image %s (%d files) to %s", name, len(files), dest)
+	return oci.Export(context.Background(), RootFsDir(name), files, dest, sCtx, config, annotations)
+}
+
+// exportAnnotations returns the annotations of an exported image.
+func exportAnnotations(name string, md *Metadata, created time.Time) map[string]string {
+	annotations := map[string]string{
+		imgSpecs.AnnotationCreated: created.Format(time.RFC3339),
+		imgSpecs.AnnotationTitle:   name,
+		AnnotationName:             name,
+		AnnotationExportedBy:       CurrentUser(),
+		AnnotationVersion:          warewulfconf.Version,
+	}
+	if md.Source != "" {
+		annotations[AnnotationSource] = md.Source
+		if strings.HasPrefix(md.Source, "docker://") {
+			annotations[imgSpecs.AnnotationBaseImageName] = strings.TrimPrefix(md.Source, "docker://")
+		}
+	}
+	if md.Digest != "" {
+		annotations[AnnotationDigest] = md.Digest
+		if strings.HasPrefix(md.Source, "docker://") {
+			annotations[imgSpecs.AnnotationBaseImageDigest] = md.Digest
+		}
+	}
+	if md.Parent != "" {
+		annotations[AnnotationParent] = md.Parent
+	}
+	return annotations
+}
+
+// exportConfig returns the config of an exported image. Its history
+// has an empty entry for each command run in the image, as they are all
+// part of its single layer.
+func exportConfig(md *Metadata, created time.Time) (config imgSpecs.Image) {
+	fields := strings.Split(platform(nil), "/")
+	if md.Platform != "" {
+		fields = strings.Split(md.Platform, "/")
+	}
+	config.OS = fields[0]
+	if len(fields) > 1 {
+		config.Architecture = fields[1]
+	}
+	if len(fields) > 2 {
+		config.Variant = fields[2]
+	}
+	config.Created = &created
+	config.Author = CurrentUser()
+	if md.Source != "" {
+		imported := md.Imported
+		config.History = append(config.History, imgSpecs.History{
+			Created:    &imported,
+			CreatedBy:  "wwctl image import " + md.Source,
+			EmptyLayer: true,
+		})
+	}
+	for _, record := range md.History {
+		start := record.Time
+		config.History = append(config.History, imgSpecs.History{
+			Created:    &start,
+			CreatedBy:  strings.Join(record.Command, " "),
+			Author:     record.User,
+			Comment:    fmt.Sprintf("wwctl image exec (exit code %d)", record.ExitCode),
+			EmptyLayer: true,
+		})
+	}
+	config.History = append(config.History, imgSpecs.History{
+		Created:   &created,
+		CreatedBy: "wwctl image export",
+		Author:    CurrentUser(),
+	})
+	return config
+}
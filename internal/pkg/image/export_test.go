@@ -0,0 +1,88 @@
+package image
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	imgSpecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+// readLayout returns the manifest and config of the single image in the
+// oci layout at dir.
+func readLayout(t *testing.T, dir string) (manifest imgSpecs.Manifest, config imgSpecs.Image) {
+	readBlob := func(desc imgSpecs.Descriptor, v interface{}) {
+		buf, err := os.ReadFile(filepath.Join(dir, "blobs", desc.Digest.Algorithm().String(), desc.Digest.Encoded()))
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(buf, v))
+	}
+	var index imgSpecs.Index
+	buf, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(buf, &index))
+	if assert.Len(t, index.Manifests, 1) {
+		readBlob(index.Manifests[0], &manifest)
+		readBlob(manifest.Config, &config)
+	}
+	return manifest, config
+}
+
+func Test_Export(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	archive := filepath.Join(t.TempDir(), "image.tar")
+	writeArchive(t, archive, map[string]string{
+		"bin/": "", "bin/sh": "#!", "etc/": "", "etc/os-release": "v1",
+		"etc/warewulf/": "", "etc/warewulf/excludes": "/var/cache/*\n",
+		"var/": "", "var/cache/": "", "var/cache/big": "big",
+	})
+	assert.NoError(t, ImportDocker(archive, "test", nil, false))
+	assert.NoError(t, RecordExec("test", time.Now(), []string{"dnf", "-y", "update"}, 0))
+	imported, err := ReadMetadata("test")
+	assert.NoError(t, err)
+
+	t.Run("oci layout", func(t *testing.T) {
+		layout := filepath.Join(t.TempDir(), "layout")
+		assert.NoError(t, Export("test", "oci:"+layout+":test", nil))
+		manifest, config := readLayout(t, layout)
+		assert.Len(t, manifest.Layers, 1)
+		assert.Equal(t, "test", manifest.Annotations[AnnotationName])
+		assert.Equal(t, "test", manifest.Annotations[imgSpecs.AnnotationTitle])
+		assert.Equal(t, archive, manifest.Annotations[AnnotationSource])
+		assert.Equal(t, imported.Digest, manifest.Annotations[AnnotationDigest])
+		assert.NotEmpty(t, manifest.Annotations[AnnotationVersion])
+		assert.NotContains(t, manifest.Annotations, imgSpecs.AnnotationBaseImageName)
+		assert.Equal(t, manifest.Annotations, config.Config.Labels)
+		assert.Equal(t, imported.Platform, config.OS+"/"+config.Architecture)
+		if assert.Len(t, config.History, 3) {
+			assert.Equal(t, "dnf -y update", config.History[1].CreatedBy)
+			assert.True(t, config.History[1].EmptyLayer)
+			assert.False(t, config.History[2].EmptyLayer)
+		}
+	})
+
+	t.Run("reimport", func(t *testing.T) {
+		exported := filepath.Join(t.TempDir(), "exported.tar")
+		assert.NoError(t, Export("test", "docker-archive:"+exported, nil))
+		assert.NoError(t, ImportDocker(exported, "reimported", nil, false))
+		rootfs := RootFsDir("reimported")
+		content, _ := os.ReadFile(filepath.Join(rootfs, "etc/os-release"))
+		assert.Equal(t, "v1", string(content))
+		assert.FileExists(t, filepath.Join(rootfs, "bin/sh"))
+		assert.DirExists(t, filepath.Join(rootfs, "var/cache"))
+		assert.NoFileExists(t, filepath.Join(rootfs, "var/cache/big"))
+	})
+
+	t.Run("nonexistent image", func(t *testing.T) {
+		assert.ErrorContains(t, Export("none", "oci:"+t.TempDir(), nil), "does not exist")
+	})
+
+	t.Run("invalid destination", func(t *testing.T) {
+		assert.ErrorContains(t, Export("test", "ftp://example.com/test", nil), "unknown uri scheme")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 
+	"github.com/containers/image/v5/signature"
 	"github.com/containers/image/v5/types"
 	"github.com/containers/storage/drivers/copy"
 	"github.com/containers/storage/pkg/reexec"
@@ -15,7 +16,13 @@ import (
 	"github.com/warewulf/warewulf/internal/pkg/util"
 )
 
-func ImportDocker(uri string, name string, sCtx *types.SystemContext) error {
+/*
+ImportDocker pulls the image at uri as the image name. If verify is set,
+or "signature: verify" is set in warewulf.conf, the signatures of the
+image are verified with the policy file in the system context, or else
+the policy or keys in warewulf.conf, or else the system default policy.
+*/
+func ImportDocker(uri string, name string, sCtx *types.SystemContext, verify bool) error {
 	OciBlobCacheDir := warewulfconf.Get().Paths.OciBlobCachedir()
 
 	err := os.MkdirAll(OciBlobCacheDir, 0755)
@@ -34,15 +41,22 @@ func ImportDocker(uri string, name string, sCtx *types.SystemContext) error {
 		return err
 	}
 
+	policy, err := signaturePolicy(sCtx, verify)
+	if err != nil {
+		return err
+	}
+
 	p, err := oci.NewPuller(
 		oci.OptSetBlobCachePath(OciBlobCacheDir),
 		oci.OptSetSystemContext(sCtx),
+		oci.OptSetSignaturePolicy(policy),
 	)
 	if err != nil {
 		return err
 	}
 
-	if _, err := p.GenerateID(context.Background(), uri); err != nil {
+	digest, err := p.GenerateID(context.Background(), uri)
+	if err != nil {
 		return err
 	}
 
@@ -50,7 +64,32 @@ func ImportDocker(uri string, name string, sCtx *types.SystemContext) error {
 		return err
 	}
 
-	return nil
+	return recordImport(name, &Metadata{
+		Source:   uri,
+		Digest:   digest,
+		Platform: platform(sCtx),
+	})
+}
+
+// signaturePolicy returns the policy with which images are verified, or
+// nil if they are not verified.
+func signaturePolicy(sCtx *types.SystemContext, verify bool) (*signature.Policy, error) {
+	conf := warewulfconf.Get().Signature
+	if !verify && !conf.Verify() {
+		return nil, nil
+	}
+	var policyPath string
+	var keys []string
+	if sCtx != nil {
+		policyPath = sCtx.SignaturePolicyPath
+	}
+	if conf != nil {
+		if policyPath == "" {
+			policyPath = conf.Policy
+		}
+		keys = conf.Keys
+	}
+	return oci.SignaturePolicy(policyPath, keys)
 }
 
 func ImportDirectory(uri string, name string) error {
@@ -76,5 +115,5 @@ func ImportDirectory(uri string, name string) error {
 		return err
 	}
 
-	return nil
+	return recordImport(name, &Metadata{Source: uri})
 }
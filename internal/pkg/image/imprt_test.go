@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/warewulf/warewulf/internal/pkg/oci"
 	"github.com/warewulf/warewulf/internal/pkg/testenv"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 )
@@ -53,3 +54,27 @@ func Test_ImportImageDir(t *testing.T) {
 		})
 	}
 }
+
+func Test_signaturePolicy(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	policy, err := signaturePolicy(nil, false)
+	assert.NoError(t, err)
+	assert.Nil(t, policy)
+
+	_, err = signaturePolicy(nil, true)
+	assert.ErrorIs(t, err, oci.ErrNoSignaturePolicy)
+
+	env.WriteFile("etc/warewulf/cosign.pub", "not a key")
+	env.WriteFile("etc/warewulf/warewulf.conf", `
+signature:
+  verify: true
+  keys:
+    - `+env.GetPath("etc/warewulf/cosign.pub")+`
+`)
+	_ = env.Configure()
+	policy, err = signaturePolicy(nil, false)
+	assert.NoError(t, err)
+	assert.NotNil(t, policy)
+}
@@ -0,0 +1,131 @@
+package image
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/containers/image/v5/types"
+)
+
+// Metadata records where an image came from and the commands which
+// were run in it since. It is stored next to the rootfs of the image.
+type Metadata struct {
+	Source   string       `json:"source,omitempty"`
+	Digest   string       `json:"digest,omitempty"`
+	Platform string       `json:"platform,omitempty"`
+	Imported time.Time    `json:"imported"`
+	User     string       `json:"user,omitempty"`
+	Parent   string       `json:"parent,omitempty"`
+	History  []ExecRecord `json:"history,omitempty"`
+}
+
+// ExecRecord is a command run in an image with `wwctl image exec` or
+// `wwctl image shell`.
+type ExecRecord struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user,omitempty"`
+	Command  []string  `json:"command"`
+	ExitCode int       `json:"exitcode"`
+}
+
+// ReadMetadata returns the metadata of an image. Images imported
+// before metadata was recorded have empty metadata.
+func ReadMetadata(name string) (*Metadata, error) {
+	md := new(Metadata)
+	buf, err := os.ReadFile(MetadataFile(name))
+	if os.IsNotExist(err) {
+		return md, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, md); err != nil {
+		return nil, err
+	}
+	return md, nil
+}
+
+// WriteMetadata replaces the metadata of an image.
+func WriteMetadata(name string, md *Metadata) error {
+	buf, err := json.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(SourceDir(name), ".metadata-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(buf, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), MetadataFile(name))
+}
+
+// UpdateMetadata applies update to the metadata of an image.
+func UpdateMetadata(name string, update func(*Metadata)) error {
+	md, err := ReadMetadata(name)
+	if err != nil {
+		return err
+	}
+	update(md)
+	return WriteMetadata(name, md)
+}
+
+// RecordExec adds a command, started at start, to the history of an
+// image.
+func RecordExec(name string, start time.Time, command []string, exitCode int) error {
+	return UpdateMetadata(name, func(md *Metadata) {
+		md.History = append(md.History, ExecRecord{
+			Time:     start.UTC(),
+			User:     CurrentUser(),
+			Command:  command,
+			ExitCode: exitCode,
+		})
+	})
+}
+
+// recordImport writes the metadata of a newly imported image.
+func recordImport(name string, md *Metadata) error {
+	md.Imported = time.Now().UTC()
+	md.User = CurrentUser()
+	return WriteMetadata(name, md)
+}
+
+// CurrentUser returns the unix user running the command, or the
+// invoking user for commands run with sudo.
+func CurrentUser() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		return sudoUser
+	} else if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return "unknown"
+}
+
+// platform returns the platform requested by sCtx, e.g. "linux/amd64".
+func platform(sCtx *types.SystemContext) string {
+	fields := []string{runtime.GOOS, runtime.GOARCH}
+	if sCtx != nil {
+		if sCtx.OSChoice != "" {
+			fields[0] = sCtx.OSChoice
+		}
+		if sCtx.ArchitectureChoice != "" {
+			fields[1] = sCtx.ArchitectureChoice
+		}
+		if sCtx.VariantChoice != "" {
+			fields = append(fields, sCtx.VariantChoice)
+		}
+	}
+	return strings.Join(fields, "/")
+}
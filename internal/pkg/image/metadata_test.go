@@ -0,0 +1,43 @@
+package image
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Metadata(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	t.Setenv("SUDO_USER", "admin")
+	env.CreateFile("/tmp/testImage/bin/sh")
+
+	md, err := ReadMetadata("missing")
+	assert.NoError(t, err)
+	assert.Equal(t, &Metadata{}, md)
+
+	assert.NoError(t, ImportDirectory(env.GetPath("/tmp/testImage"), "parent"))
+	md, err = ReadMetadata("parent")
+	assert.NoError(t, err)
+	assert.Equal(t, env.GetPath("/tmp/testImage"), md.Source)
+	assert.Equal(t, "admin", md.User)
+	assert.False(t, md.Imported.IsZero())
+
+	start := time.Now()
+	assert.NoError(t, RecordExec("parent", start, []string{"dnf", "-y", "update"}, 0))
+	assert.NoError(t, RecordExec("parent", start, []string{"/bin/false"}, 1))
+
+	assert.NoError(t, Duplicate("parent", "child"))
+	md, err = ReadMetadata("child")
+	assert.NoError(t, err)
+	assert.Equal(t, "parent", md.Parent)
+	assert.Equal(t, env.GetPath("/tmp/testImage"), md.Source)
+	if assert.Len(t, md.History, 2) {
+		assert.Equal(t, []string{"dnf", "-y", "update"}, md.History[0].Command)
+		assert.Equal(t, "admin", md.History[0].User)
+		assert.Equal(t, 1, md.History[1].ExitCode)
+	}
+}
@@ -0,0 +1,292 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/containers/image/v5/types"
+	"github.com/containers/storage/drivers/copy"
+	"github.com/containers/storage/pkg/reexec"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/oci"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// ErrLocalChanges is returned by Pull for an image with local
+// modifications which would be lost.
+var ErrLocalChanges = errors.New("image has local modifications")
+
+// PullOptions controls how Pull treats local modifications of an image.
+type PullOptions struct {
+	// Preserve applies the local modifications to the updated image.
+	Preserve bool
+	// Discard discards the local modifications.
+	Discard bool
+	// Verify verifies the signatures of the updated image.
+	Verify bool
+}
+
+// PullResult describes an update of an image by Pull.
+type PullResult struct {
+	Source    string
+	OldDigest string
+	NewDigest string
+	// Layers is the number of layers of the updated image, of which
+	// Applied were unpacked.
+	Layers  int
+	Applied int
+	// Changes are the local modifications of the image, and Conflicts
+	// those which were also changed by the update.
+	Changes   []oci.Change
+	Conflicts []oci.Change
+}
+
+// UpToDate reports whether the image already was at the latest
+// upstream version.
+func (result *PullResult) UpToDate() bool {
+	return result.OldDigest == result.NewDigest
+}
+
+// isOCISource reports whether uri is a source which images are pulled
+// from with the oci package.
+func isOCISource(uri string) bool {
+	return strings.HasPrefix(uri, "docker://") || strings.HasPrefix(uri, "docker-daemon://") ||
+		strings.HasPrefix(uri, "file://") || util.IsFile(uri)
+}
+
+/*
+Pull updates an image from the source it was imported from. If the
+digest of its manifest differs from the recorded one, the new layers are
+fetched into the blob cache and the rootfs is rebased onto them. If the
+image only adds layers to the previous one, just those layers are
+unpacked.
+
+Files of the rootfs which differ from the previous upstream image are
+local modifications. Unless opts preserves or discards them, an image
+with local modifications is not updated, and ErrLocalChanges is
+returned along with the modifications.
+*/
+func Pull(name string, sCtx *types.SystemContext, opts PullOptions) (result *PullResult, err error) {
+	if !ValidSource(name) {
+		return nil, fmt.Errorf("image does not exist: %s", name)
+	}
+	if util.IsDir(RunDir(name)) {
+		return nil, fmt.Errorf("run directory exists: another image command may already be running (otherwise, remove %s)", RunDir(name))
+	}
+	md, err := ReadMetadata(name)
+	if err != nil {
+		return nil, err
+	}
+	if md.Source == "" || md.Digest == "" {
+		return nil, fmt.Errorf("the OCI source of image %s is not recorded: import it again with --force", name)
+	} else if !isOCISource(md.Source) {
+		return nil, fmt.Errorf("image %s was not imported from an OCI source: %s", name, md.Source)
+	}
+	result = &PullResult{Source: md.Source, OldDigest: md.Digest}
+
+	// pull the same platform as before, unless another one is requested
+	if sCtx == nil {
+		sCtx = &types.SystemContext{}
+	}
+	if fields := strings.Split(md.Platform, "/"); sCtx.ArchitectureChoice == "" && len(fields) > 1 {
+		sCtx.ArchitectureChoice = fields[1]
+		if len(fields) > 2 {
+			sCtx.VariantChoice = fields[2]
+		}
+	}
+
+	blobCacheDir := warewulfconf.Get().Paths.OciBlobCachedir()
+	if err := os.MkdirAll(blobCacheDir, 0755); err != nil {
+		return result, err
+	}
+	policy, err := signaturePolicy(sCtx, opts.Verify)
+	if err != nil {
+		return result, err
+	}
+	p, err := oci.NewPuller(
+		oci.OptSetBlobCachePath(blobCacheDir),
+		oci.OptSetSystemContext(sCtx),
+		oci.OptSetSignaturePolicy(policy),
+	)
+	if err != nil {
+		return result, err
+	}
+
+	ctx := context.Background()
+	if result.NewDigest, err = p.GenerateID(ctx, md.Source); err != nil {
+		return result, err
+	}
+	if result.UpToDate() {
+		return result, nil
+	}
+
+	// the previous upstream image is needed to find local modifications
+	oldManifest, err := p.CachedManifest(ctx, md.Digest)
+	oldKnown := err == nil
+	if !oldKnown {
+		if !opts.Discard {
+			return result, fmt.Errorf("previous version of image %s is not in the blob cache, so local modifications cannot be detected: %w", name, err)
+		}
+		wwlog.Warn("Previous version of image %s is not in the blob cache", name)
+	}
+
+	newManifest, err := p.Fetch(ctx, md.Source)
+	if err != nil {
+		return result, err
+	}
+	result.Layers = len(newManifest.Layers)
+
+	rootfs := RootFsDir(name)
+	common := 0
+	if oldKnown {
+		common = oci.CommonLayers(oldManifest, newManifest)
+		lower := make(oci.FileState)
+		if err := p.ApplyLayers(ctx, lower, oldManifest.Layers[:common]); err != nil {
+			return result, err
+		}
+		oldState, newState := lower.Clone(), lower
+		if err := p.ApplyLayers(ctx, oldState, oldManifest.Layers[common:]); err != nil {
+			return result, err
+		}
+		if err := p.ApplyLayers(ctx, newState, newManifest.Layers[common:]); err != nil {
+			return result, err
+		}
+		if result.Changes, err = oci.DiffRootfs(rootfs, oldState); err != nil {
+			return result, err
+		}
+		result.Conflicts = conflicts(result.Changes, oldState.Diff(newState))
+	}
+	if len(result.Changes) > 0 && !opts.Preserve && !opts.Discard {
+		return result, ErrLocalChanges
+	}
+
+	staging := path.Join(SourceDir(name), "rootfs.pull")
+	if err := os.RemoveAll(staging); err != nil {
+		return result, err
+	}
+	defer os.RemoveAll(staging)
+	if reexec.Init() {
+		return result, errors.New("couldn't init reexec")
+	}
+	// the rootfs of an unmodified image is the previous upstream image,
+	// so only the new layers need to be unpacked onto it; this also keeps
+	// local modifications which the new layers do not replace
+	if oldKnown && common > 0 && (len(result.Changes) == 0 || opts.Preserve) && common == len(oldManifest.Layers) {
+		wwlog.Info("Applying %d of %d layers", len(newManifest.Layers)-common, len(newManifest.Layers))
+		if err := copy.DirCopy(rootfs, staging, copy.Hardlink, true); err != nil {
+			return result, err
+		}
+		if err := p.Unpack(ctx, staging, common); err != nil {
+			return result, err
+		}
+		result.Applied = len(newManifest.Layers) - common
+	} else {
+		wwlog.Info("Unpacking %d layers", len(newManifest.Layers))
+		if err := p.Unpack(ctx, staging, 0); err != nil {
+			return result, err
+		}
+		result.Applied = len(newManifest.Layers)
+	}
+	if opts.Preserve {
+		if err := applyChanges(rootfs, staging, result.Changes); err != nil {
+			return result, fmt.Errorf("could not preserve local modifications: %w", err)
+		}
+	}
+
+	previous := path.Join(SourceDir(name), "rootfs.previous")
+	if err := os.RemoveAll(previous); err != nil {
+		return result, err
+	}
+	if err := os.Rename(rootfs, previous); err != nil {
+		return result, err
+	}
+	if err := os.Rename(staging, rootfs); err != nil {
+		_ = os.Rename(previous, rootfs)
+		return result, err
+	}
+	if err := os.RemoveAll(previous); err != nil {
+		wwlog.Warn("could not remove previous rootfs: %s", err)
+	}
+
+	err = UpdateMetadata(name, func(md *Metadata) {
+		md.Digest = result.NewDigest
+		md.Platform = platform(sCtx)
+		md.Imported = time.Now().UTC()
+		md.User = CurrentUser()
+		// the commands run in the image are only reflected by its
+		// rootfs if its local modifications were preserved
+		if !opts.Preserve {
+			md.History = nil
+		}
+	})
+	return result, err
+}
+
+// conflicts returns the local changes of files which were also changed
+// upstream, or, for added and deleted directories, files below them.
+func conflicts(changes []oci.Change, upstream map[string]bool) (ret []oci.Change) {
+	parents := make(map[string]bool)
+	for name := range upstream {
+		for dir := path.Dir(name); dir != "/" && !parents[dir]; dir = path.Dir(dir) {
+			parents[dir] = true
+		}
+	}
+	for _, change := range changes {
+		if upstream[change.Path] || (change.Kind != oci.ChangeModified && parents[change.Path]) {
+			ret = append(ret, change)
+		}
+	}
+	return ret
+}
+
+// applyChanges applies the changes of the rootfs at from to the rootfs
+// at to. Changed files are hard linked rather than copied.
+func applyChanges(from, to string, changes []oci.Change) error {
+	for _, change := range changes {
+		src := filepath.Join(from, change.Path)
+		dst := filepath.Join(to, change.Path)
+		if change.Kind == oci.ChangeDeleted {
+			if err := os.RemoveAll(dst); err != nil {
+				return err
+			}
+			continue
+		}
+		srcInfo, err := os.Lstat(src)
+		if err != nil {
+			return err
+		}
+		if dstInfo, err := os.Lstat(dst); err == nil && dstInfo.IsDir() && srcInfo.IsDir() && change.Kind == oci.ChangeModified {
+			// only the ownership or permissions of the directory changed
+			if err := util.CopyUIDGID(src, dst); err != nil {
+				return err
+			}
+			if err := os.Chmod(dst, srcInfo.Mode()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if srcInfo.IsDir() {
+			err = copy.DirCopy(src, dst, copy.Hardlink, true)
+		} else {
+			err = os.Link(src, dst)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/oci"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+// writeArchive writes a docker-archive with a layer for each map of
+// file names to contents. Names ending in "/" are directories.
+func writeArchive(t *testing.T, fileName string, layers ...map[string]string) {
+	mtime := time.Unix(1700000000, 0)
+	var layerNames, diffIDs []string
+	var files []struct {
+		name string
+		data []byte
+	}
+	for i, layer := range layers {
+		var buf bytes.Buffer
+		lw := tar.NewWriter(&buf)
+		for _, name := range sortedKeys(layer) {
+			hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(layer[name])), Typeflag: tar.TypeReg, ModTime: mtime}
+			if strings.HasSuffix(name, "/") {
+				hdr.Mode, hdr.Size, hdr.Typeflag = 0755, 0, tar.TypeDir
+			}
+			assert.NoError(t, lw.WriteHeader(hdr))
+			_, err := lw.Write([]byte(layer[name]))
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, lw.Close())
+		name := fmt.Sprintf("layer%d.tar", i)
+		layerNames = append(layerNames, `"`+name+`"`)
+		diffIDs = append(diffIDs, fmt.Sprintf(`"sha256:%x"`, sha256.Sum256(buf.Bytes())))
+		files = append(files, struct {
+			name string
+			data []byte
+		}{name, buf.Bytes()})
+	}
+	config := fmt.Sprintf(`{"architecture": "%s", "os": "linux", "rootfs": {"type": "layers", "diff_ids": [%s]}}`,
+		runtime.GOARCH, strings.Join(diffIDs, ", "))
+	manifest := fmt.Sprintf(`[{"Config": "config.json", "RepoTags": ["test:latest"], "Layers": [%s]}]`, strings.Join(layerNames, ", "))
+	files = append(files, struct {
+		name string
+		data []byte
+	}{"config.json", []byte(config)}, struct {
+		name string
+		data []byte
+	}{"manifest.json", []byte(manifest)})
+
+	f, err := os.Create(fileName)
+	assert.NoError(t, err)
+	defer f.Close()
+	w := tar.NewWriter(f)
+	for _, file := range files {
+		assert.NoError(t, w.WriteHeader(&tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.data)), Typeflag: tar.TypeReg}))
+		_, err := w.Write(file.data)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+}
+
+func sortedKeys(m map[string]string) (keys []string) {
+	for key := range m {
+		keys = append(keys, key)
+	}
+	for i := range keys {
+		for j := i + 1; j < len(keys); j++ {
+			if keys[j] < keys[i] {
+				keys[i], keys[j] = keys[j], keys[i]
+			}
+		}
+	}
+	return keys
+}
+
+func Test_Pull(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	archive := filepath.Join(t.TempDir(), "image.tar")
+	rootfs := env.GetPath("/var/lib/warewulf/chroots/test/rootfs")
+	base := map[string]string{"bin/": "", "bin/sh": "#!", "etc/": "", "etc/os-release": "v1"}
+	update := map[string]string{"etc/os-release": "v2", "usr/": "", "usr/bin/": "", "usr/bin/new": "new"}
+
+	writeArchive(t, archive, base)
+	assert.NoError(t, ImportDocker(archive, "test", nil, false))
+	imported, err := ReadMetadata("test")
+	assert.NoError(t, err)
+
+	t.Run("up to date", func(t *testing.T) {
+		result, err := Pull("test", nil, PullOptions{})
+		assert.NoError(t, err)
+		assert.True(t, result.UpToDate())
+		assert.Equal(t, imported.Digest, result.NewDigest)
+	})
+
+	t.Run("new layer", func(t *testing.T) {
+		writeArchive(t, archive, base, update)
+		result, err := Pull("test", nil, PullOptions{})
+		assert.NoError(t, err)
+		assert.False(t, result.UpToDate())
+		assert.Equal(t, 2, result.Layers)
+		assert.Equal(t, 1, result.Applied)
+		assert.Empty(t, result.Changes)
+		assert.FileExists(t, filepath.Join(rootfs, "usr/bin/new"))
+		content, _ := os.ReadFile(filepath.Join(rootfs, "etc/os-release"))
+		assert.Equal(t, "v2", string(content))
+		md, err := ReadMetadata("test")
+		assert.NoError(t, err)
+		assert.Equal(t, result.NewDigest, md.Digest)
+		assert.NoDirExists(t, env.GetPath("/var/lib/warewulf/chroots/test/rootfs.pull"))
+	})
+
+	t.Run("local modifications", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(filepath.Join(rootfs, "etc/os-release"), []byte("local"), 0644))
+		assert.NoError(t, os.WriteFile(filepath.Join(rootfs, "etc/local"), []byte("local"), 0644))
+		assert.NoError(t, os.Remove(filepath.Join(rootfs, "usr/bin/new")))
+		assert.NoError(t, RecordExec("test", time.Now(), []string{"vi", "/etc/os-release"}, 0))
+		writeArchive(t, archive, base, update, map[string]string{"etc/os-release": "v3", "opt/": "", "opt/app": "app"})
+		changes := []oci.Change{
+			{Path: "/etc/local", Kind: oci.ChangeAdded},
+			{Path: "/etc/os-release", Kind: oci.ChangeModified},
+			{Path: "/usr/bin/new", Kind: oci.ChangeDeleted},
+		}
+
+		result, err := Pull("test", nil, PullOptions{})
+		assert.ErrorIs(t, err, ErrLocalChanges)
+		assert.Equal(t, changes, result.Changes)
+		assert.Equal(t, changes[1:2], result.Conflicts)
+		assert.NoFileExists(t, filepath.Join(rootfs, "opt/app"))
+
+		result, err = Pull("test", nil, PullOptions{Preserve: true})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Applied)
+		assert.Equal(t, changes, result.Changes)
+		assert.FileExists(t, filepath.Join(rootfs, "opt/app"))
+		assert.FileExists(t, filepath.Join(rootfs, "etc/local"))
+		assert.NoFileExists(t, filepath.Join(rootfs, "usr/bin/new"))
+		content, _ := os.ReadFile(filepath.Join(rootfs, "etc/os-release"))
+		assert.Equal(t, "local", string(content))
+		md, err := ReadMetadata("test")
+		assert.NoError(t, err)
+		assert.Len(t, md.History, 1)
+	})
+
+	t.Run("discard local modifications", func(t *testing.T) {
+		writeArchive(t, archive, map[string]string{"bin/": "", "bin/sh": "#!", "etc/": "", "etc/os-release": "v4"})
+		result, err := Pull("test", nil, PullOptions{Discard: true})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Applied)
+		assert.Len(t, result.Changes, 3)
+		assert.NoFileExists(t, filepath.Join(rootfs, "etc/local"))
+		assert.NoFileExists(t, filepath.Join(rootfs, "opt/app"))
+		content, _ := os.ReadFile(filepath.Join(rootfs, "etc/os-release"))
+		assert.Equal(t, "v4", string(content))
+		md, err := ReadMetadata("test")
+		assert.NoError(t, err)
+		assert.Empty(t, md.History)
+	})
+
+	t.Run("not imported from an OCI source", func(t *testing.T) {
+		env.CreateFile("/tmp/dir/bin/sh")
+		assert.NoError(t, ImportDirectory(env.GetPath("/tmp/dir"), "dir"))
+		_, err := Pull("dir", nil, PullOptions{})
+		assert.ErrorContains(t, err, "not recorded")
+	})
+}
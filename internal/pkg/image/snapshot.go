@@ -0,0 +1,213 @@
+package image
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/containers/storage/drivers/copy"
+	"github.com/containers/storage/pkg/reexec"
+
+	"github.com/warewulf/warewulf/internal/pkg/util"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+const snapshotFile = "snapshot.json"
+
+// Snapshot is a copy of the rootfs and metadata of an image, which the
+// image can be restored to.
+type Snapshot struct {
+	Name    string    `json:"-"`
+	Created time.Time `json:"created"`
+	User    string    `json:"user,omitempty"`
+	// Command is the command which the snapshot was taken before, for
+	// snapshots taken by `wwctl image exec --snapshot`.
+	Command []string `json:"command,omitempty"`
+}
+
+/*
+CreateSnapshot copies the rootfs and metadata of an image to a new
+snapshot. If snapshot is empty, the snapshot is named after the current
+time. command is recorded as the command which the snapshot is taken
+before, if any.
+
+Files are copied with reflinks on file systems which support them, e.g.
+btrfs and xfs, and copied in full otherwise. Hard links are not used, as
+changes of a file in place would also change the snapshot.
+*/
+func CreateSnapshot(name string, snapshot string, command []string) (*Snapshot, error) {
+	if !ValidSource(name) {
+		return nil, fmt.Errorf("image does not exist: %s", name)
+	}
+	if util.IsDir(RunDir(name)) {
+		return nil, fmt.Errorf("run directory exists: another image command may already be running (otherwise, remove %s)", RunDir(name))
+	}
+	now := time.Now().UTC()
+	if snapshot == "" {
+		snapshot = snapshotName(name, now)
+	}
+	if !ValidName(snapshot) {
+		return nil, fmt.Errorf("snapshot name contains illegal characters: %s", snapshot)
+	}
+	if util.IsDir(SnapshotDir(name, snapshot)) {
+		return nil, fmt.Errorf("snapshot %s of image %s already exists", snapshot, name)
+	}
+	if err := os.MkdirAll(SnapshotParentDir(name), 0755); err != nil {
+		return nil, err
+	}
+
+	// the snapshot only appears once it is complete
+	staging := path.Join(SnapshotParentDir(name), "."+snapshot)
+	if err := os.RemoveAll(staging); err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+	if err := os.Mkdir(staging, 0755); err != nil {
+		return nil, err
+	}
+	wwlog.Info("Creating snapshot %s of image %s", snapshot, name)
+	if err := copyRootfs(RootFsDir(name), path.Join(staging, "rootfs")); err != nil {
+		return nil, fmt.Errorf("could not copy rootfs: %w", err)
+	}
+	if util.IsFile(MetadataFile(name)) {
+		if err := util.CopyFile(MetadataFile(name), path.Join(staging, "metadata.json")); err != nil {
+			return nil, err
+		}
+	}
+	s := &Snapshot{Name: snapshot, Created: now, User: CurrentUser(), Command: command}
+	buf, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path.Join(staging, snapshotFile), append(buf, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return s, os.Rename(staging, SnapshotDir(name, snapshot))
+}
+
+// snapshotName returns an unused name for a snapshot of an image taken
+// at t.
+func snapshotName(name string, t time.Time) string {
+	base := t.Format("20060102-150405")
+	snapshot := base
+	for i := 2; util.IsDir(SnapshotDir(name, snapshot)); i++ {
+		snapshot = fmt.Sprintf("%s-%d", base, i)
+	}
+	return snapshot
+}
+
+// ListSnapshots returns the snapshots of an image, oldest first.
+func ListSnapshots(name string) (snapshots []*Snapshot, err error) {
+	if !ValidSource(name) {
+		return nil, fmt.Errorf("image does not exist: %s", name)
+	}
+	entries, err := os.ReadDir(SnapshotParentDir(name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !ValidName(entry.Name()) {
+			continue
+		}
+		s, err := readSnapshot(name, entry.Name())
+		if err != nil {
+			wwlog.Warn("could not read snapshot %s of image %s: %s", entry.Name(), name, err)
+			continue
+		}
+		snapshots = append(snapshots, s)
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].Created.Before(snapshots[j].Created) })
+	return snapshots, nil
+}
+
+func readSnapshot(name string, snapshot string) (*Snapshot, error) {
+	buf, err := os.ReadFile(path.Join(SnapshotDir(name, snapshot), snapshotFile))
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{Name: snapshot}
+	if err := json.Unmarshal(buf, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+/*
+RestoreSnapshot replaces the rootfs and metadata of an image with those
+of a snapshot. The snapshot is kept, so that the image can be restored to
+it again.
+*/
+func RestoreSnapshot(name string, snapshot string) error {
+	if !ValidSource(name) {
+		return fmt.Errorf("image does not exist: %s", name)
+	}
+	if util.IsDir(RunDir(name)) {
+		return fmt.Errorf("run directory exists: another image command may already be running (otherwise, remove %s)", RunDir(name))
+	}
+	if !ValidName(snapshot) || !util.IsDir(path.Join(SnapshotDir(name, snapshot), "rootfs")) {
+		return fmt.Errorf("snapshot %s of image %s does not exist", snapshot, name)
+	}
+
+	wwlog.Info("Restoring image %s to snapshot %s", name, snapshot)
+	rootfs := RootFsDir(name)
+	staging := path.Join(SourceDir(name), "rootfs.restore")
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+	if err := copyRootfs(path.Join(SnapshotDir(name, snapshot), "rootfs"), staging); err != nil {
+		return fmt.Errorf("could not copy rootfs: %w", err)
+	}
+
+	previous := path.Join(SourceDir(name), "rootfs.previous")
+	if err := os.RemoveAll(previous); err != nil {
+		return err
+	}
+	if err := os.Rename(rootfs, previous); err != nil {
+		return err
+	}
+	if err := os.Rename(staging, rootfs); err != nil {
+		_ = os.Rename(previous, rootfs)
+		return err
+	}
+	if err := os.RemoveAll(previous); err != nil {
+		wwlog.Warn("could not remove previous rootfs: %s", err)
+	}
+
+	// the metadata describes the restored rootfs
+	metadata := path.Join(SnapshotDir(name, snapshot), "metadata.json")
+	if util.IsFile(metadata) {
+		return util.CopyFile(metadata, MetadataFile(name))
+	}
+	if err := os.Remove(MetadataFile(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// DeleteSnapshot deletes a snapshot of an image.
+func DeleteSnapshot(name string, snapshot string) error {
+	if !ValidSource(name) {
+		return fmt.Errorf("image does not exist: %s", name)
+	}
+	if !ValidName(snapshot) || !util.IsDir(SnapshotDir(name, snapshot)) {
+		return fmt.Errorf("snapshot %s of image %s does not exist", snapshot, name)
+	}
+	wwlog.Verbose("Removing path: %s", SnapshotDir(name, snapshot))
+	return os.RemoveAll(SnapshotDir(name, snapshot))
+}
+
+// copyRootfs copies the rootfs at src to dst, preserving ownership,
+// permissions, hard links, and extended attributes.
+func copyRootfs(src string, dst string) error {
+	if reexec.Init() {
+		return errors.New("couldn't init reexec")
+	}
+	return copy.DirCopy(src, dst, copy.Content, true)
+}
@@ -0,0 +1,107 @@
+package image
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Snapshot(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/var/lib/warewulf/chroots/test/rootfs/etc/os-release", "v1")
+	env.WriteFile("/var/lib/warewulf/chroots/test/rootfs/usr/bin/app", "app")
+	rootfs := RootFsDir("test")
+	assert.NoError(t, os.Link(filepath.Join(rootfs, "usr/bin/app"), filepath.Join(rootfs, "usr/bin/app-link")))
+	assert.NoError(t, WriteMetadata("test", &Metadata{Source: "docker://example.com/test"}))
+
+	t.Run("create", func(t *testing.T) {
+		s, err := CreateSnapshot("test", "before", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "before", s.Name)
+		assert.FileExists(t, filepath.Join(SnapshotDir("test", "before"), "rootfs/etc/os-release"))
+		assert.FileExists(t, filepath.Join(SnapshotDir("test", "before"), "metadata.json"))
+		fi1, _ := os.Stat(filepath.Join(SnapshotDir("test", "before"), "rootfs/usr/bin/app"))
+		fi2, _ := os.Stat(filepath.Join(SnapshotDir("test", "before"), "rootfs/usr/bin/app-link"))
+		assert.True(t, os.SameFile(fi1, fi2))
+		fi3, _ := os.Stat(filepath.Join(rootfs, "usr/bin/app"))
+		assert.False(t, os.SameFile(fi1, fi3))
+
+		_, err = CreateSnapshot("test", "before", nil)
+		assert.ErrorContains(t, err, "already exists")
+		_, err = CreateSnapshot("test", "a/b", nil)
+		assert.ErrorContains(t, err, "illegal characters")
+		_, err = CreateSnapshot("none", "", nil)
+		assert.ErrorContains(t, err, "does not exist")
+	})
+
+	t.Run("generated name", func(t *testing.T) {
+		s1, err := CreateSnapshot("test", "", []string{"dnf", "-y", "update"})
+		assert.NoError(t, err)
+		s2, err := CreateSnapshot("test", "", nil)
+		assert.NoError(t, err)
+		assert.NotEqual(t, s1.Name, s2.Name)
+		assert.Equal(t, []string{"dnf", "-y", "update"}, s1.Command)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		snapshots, err := ListSnapshots("test")
+		assert.NoError(t, err)
+		if assert.Len(t, snapshots, 3) {
+			assert.Equal(t, "before", snapshots[0].Name)
+			assert.Equal(t, []string{"dnf", "-y", "update"}, snapshots[1].Command)
+			assert.NotEmpty(t, snapshots[0].User)
+		}
+		env.MkdirAll("/var/lib/warewulf/chroots/other/rootfs")
+		snapshots, err = ListSnapshots("other")
+		assert.NoError(t, err)
+		assert.Empty(t, snapshots)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		env.WriteFile("/var/lib/warewulf/chroots/test/rootfs/etc/os-release", "v2")
+		env.WriteFile("/var/lib/warewulf/chroots/test/rootfs/etc/new", "new")
+		assert.NoError(t, RecordExec("test", time.Now(), []string{"dnf", "-y", "update"}, 1))
+
+		assert.NoError(t, RestoreSnapshot("test", "before"))
+		content, _ := os.ReadFile(filepath.Join(rootfs, "etc/os-release"))
+		assert.Equal(t, "v1", string(content))
+		assert.NoFileExists(t, filepath.Join(rootfs, "etc/new"))
+		md, err := ReadMetadata("test")
+		assert.NoError(t, err)
+		assert.Empty(t, md.History)
+		assert.Equal(t, "docker://example.com/test", md.Source)
+		assert.DirExists(t, SnapshotDir("test", "before"))
+		assert.NoDirExists(t, filepath.Join(SourceDir("test"), "rootfs.previous"))
+
+		assert.ErrorContains(t, RestoreSnapshot("test", "unknown"), "does not exist")
+	})
+
+	t.Run("run directory", func(t *testing.T) {
+		env.MkdirAll("/var/lib/warewulf/chroots/test/run")
+		defer os.RemoveAll(RunDir("test"))
+		_, err := CreateSnapshot("test", "", nil)
+		assert.ErrorContains(t, err, "run directory exists")
+		assert.ErrorContains(t, RestoreSnapshot("test", "before"), "run directory exists")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		assert.NoError(t, DeleteSnapshot("test", "before"))
+		assert.NoDirExists(t, SnapshotDir("test", "before"))
+		assert.ErrorContains(t, DeleteSnapshot("test", "before"), "does not exist")
+		snapshots, err := ListSnapshots("test")
+		assert.NoError(t, err)
+		assert.Len(t, snapshots, 2)
+	})
+
+	t.Run("not listed as an image", func(t *testing.T) {
+		sources, err := ListSources()
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"test", "other"}, sources)
+	})
+}
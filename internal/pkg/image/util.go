@@ -78,6 +78,10 @@ func DeleteSource(name string) error {
 	return os.RemoveAll(fullPath)
 }
 
+/*
+Duplicate copies the rootfs of an image to destination, which keeps the
+metadata of its parent.
+*/
 func Duplicate(name string, destination string) error {
 	fullPathImageSource := RootFsDir(name)
 
@@ -88,7 +92,12 @@ func Duplicate(name string, destination string) error {
 		return err
 	}
 
-	return nil
+	md, err := ReadMetadata(name)
+	if err != nil {
+		return err
+	}
+	md.Parent = name
+	return recordImport(destination, md)
 }
 
 /*
@@ -0,0 +1,265 @@
+package inventory
+
+import (
+	"bufio"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/talos-systems/go-smbios/smbios"
+
+	"github.com/warewulf/warewulf/internal/pkg/util"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// CollectLocal returns the inventory of the running system, including the
+// DIMMs from SMBIOS and the BMC firmware version from ipmitool, if they
+// are available.
+func CollectLocal() *Inventory {
+	inv := Collect("/")
+	if smbiosDump, err := smbios.New(); err == nil {
+		inv.DIMMs = dimms(smbiosDump)
+	} else {
+		wwlog.Debug("could not read SMBIOS: %s", err)
+	}
+	inv.Firmware.BMCVersion = bmcVersion()
+	return inv
+}
+
+// Collect returns the inventory of the system whose /proc and /sys are
+// below root. Components which can't be read are left out.
+func Collect(root string) *Inventory {
+	inv := &Inventory{Collected: time.Now().UTC().Truncate(time.Second)}
+	dmi := path.Join(root, "sys/class/dmi/id")
+	inv.System = System{
+		Manufacturer: readValue(dmi, "sys_vendor"),
+		Product:      readValue(dmi, "product_name"),
+		Serial:       readValue(dmi, "product_serial"),
+		UUID:         readValue(dmi, "product_uuid"),
+	}
+	inv.Firmware = Firmware{
+		BIOSVendor:  readValue(dmi, "bios_vendor"),
+		BIOSVersion: readValue(dmi, "bios_version"),
+		BIOSDate:    readValue(dmi, "bios_date"),
+	}
+	inv.CPU = cpu(path.Join(root, "proc/cpuinfo"))
+	inv.Memory = memory(path.Join(root, "proc/meminfo"))
+	inv.NICs = nics(path.Join(root, "sys/class/net"))
+	inv.Disks = disks(path.Join(root, "sys/block"))
+	inv.PCI = pciDevices(path.Join(root, "sys/bus/pci/devices"))
+	return inv
+}
+
+// readValue returns the trimmed content of a file, or "" if it can't be
+// read.
+func readValue(elem ...string) string {
+	content, err := os.ReadFile(path.Join(elem...))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// readInt returns the integer content of a file, or 0.
+func readInt(elem ...string) int64 {
+	i, err := strconv.ParseInt(readValue(elem...), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return i
+}
+
+// listDir returns the names of the entries of a directory, sorted.
+func listDir(dir string) (names []string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func cpu(cpuinfo string) (c CPU) {
+	file, err := os.Open(cpuinfo)
+	if err != nil {
+		return c
+	}
+	defer file.Close()
+	sockets := make(map[string]bool)
+	cores := make(map[string]bool)
+	var physicalID string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "processor":
+			c.Threads++
+		case "model name":
+			if c.Model == "" {
+				c.Model = value
+			}
+		case "physical id":
+			physicalID = value
+			sockets[value] = true
+		case "core id":
+			cores[physicalID+"/"+value] = true
+		}
+	}
+	c.Sockets = len(sockets)
+	c.Cores = len(cores)
+	if c.Threads > 0 && c.Sockets == 0 {
+		// e.g. on arm64, which doesn't report sockets and cores
+		c.Sockets = 1
+		c.Cores = c.Threads
+	}
+	return c
+}
+
+func memory(meminfo string) (m Memory) {
+	file, err := os.Open(meminfo)
+	if err != nil {
+		return m
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kib, _ := strconv.Atoi(fields[1])
+			m.TotalMiB = kib / 1024
+		}
+	}
+	return m
+}
+
+// nics returns the network interfaces which are backed by a device.
+func nics(dir string) (nics []NIC) {
+	for _, name := range listDir(dir) {
+		if !util.IsDir(path.Join(dir, name, "device")) {
+			continue
+		}
+		nic := NIC{
+			Name:   name,
+			Hwaddr: readValue(dir, name, "address"),
+		}
+		// the speed is unknown (and can't be read) while the link is down
+		if speed := readInt(dir, name, "speed"); speed > 0 {
+			nic.SpeedMbps = int(speed)
+		}
+		if driver, err := filepath.EvalSymlinks(path.Join(dir, name, "device", "driver")); err == nil {
+			nic.Driver = path.Base(driver)
+		}
+		nics = append(nics, nic)
+	}
+	return nics
+}
+
+// disks returns the block devices which are backed by a device.
+func disks(dir string) (disks []Disk) {
+	for _, name := range listDir(dir) {
+		if !util.IsDir(path.Join(dir, name, "device")) {
+			continue
+		}
+		disk := Disk{
+			Name:      name,
+			Model:     readValue(dir, name, "device", "model"),
+			Serial:    readValue(dir, name, "device", "serial"),
+			SizeBytes: readInt(dir, name, "size") * 512,
+		}
+		if disk.Serial == "" {
+			disk.Serial = readValue(dir, name, "serial")
+		}
+		disks = append(disks, disk)
+	}
+	return disks
+}
+
+func pciDevices(dir string) (devices []PCIDevice) {
+	for _, address := range listDir(dir) {
+		devices = append(devices, PCIDevice{
+			Address: address,
+			Class:   readValue(dir, address, "class"),
+			Vendor:  readValue(dir, address, "vendor"),
+			Device:  readValue(dir, address, "device"),
+		})
+	}
+	return devices
+}
+
+// dimms returns the populated memory devices (SMBIOS type 17).
+func dimms(smbiosDump *smbios.SMBIOS) (dimms []DIMM) {
+	for _, structure := range smbiosDump.Structures {
+		// the fields up to the part number exist since SMBIOS 2.3
+		if structure.Header.Type != 17 || len(structure.Formatted) < 0x1B-4 {
+			continue
+		}
+		device := smbios.MemoryDeviceStructure{Structure: structure}
+		size := int(device.Size())
+		switch {
+		case size == 0 || size == 0xFFFF:
+			// not installed, or unknown
+			continue
+		case size == 0x7FFF && len(structure.Formatted) >= 0x20-4:
+			size = int(binary.LittleEndian.Uint32(structure.Formatted[0x1C-4:0x20-4]) & 0x7FFFFFFF)
+		case size&0x8000 != 0:
+			size = (size & 0x7FFF) / 1024
+		}
+		dimms = append(dimms, DIMM{
+			Locator:      device.Locator(),
+			SizeMiB:      size,
+			Type:         device.MemoryType().String(),
+			SpeedMTs:     int(device.Speed()),
+			Manufacturer: strings.TrimSpace(device.Manufacturer()),
+			Serial:       strings.TrimSpace(device.SerialNumber()),
+			PartNumber:   strings.TrimSpace(device.PartNumber()),
+		})
+	}
+	return dimms
+}
+
+// ipmiDevices are the device files of the IPMI driver.
+var ipmiDevices = []string{"/dev/ipmi0", "/dev/ipmi/0", "/dev/ipmidev/0"}
+
+// bmcVersion returns the firmware version of the local BMC, as reported
+// by ipmitool, or "" if there is no BMC.
+func bmcVersion() string {
+	found := false
+	for _, device := range ipmiDevices {
+		if _, err := os.Stat(device); err == nil {
+			found = true
+		}
+	}
+	if !found {
+		return ""
+	}
+	out, err := exec.Command("ipmitool", "mc", "info").Output()
+	if err != nil {
+		wwlog.Debug("could not get BMC info: %s", err)
+		return ""
+	}
+	return parseBMCVersion(string(out))
+}
+
+// parseBMCVersion returns the firmware revision from the output of
+// "ipmitool mc info".
+func parseBMCVersion(mcInfo string) string {
+	for _, line := range strings.Split(mcInfo, "\n") {
+		if key, value, found := strings.Cut(line, ":"); found && strings.TrimSpace(key) == "Firmware Revision" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Collect(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("sys/class/dmi/id/sys_vendor", "Vendor\n")
+	env.WriteFile("sys/class/dmi/id/product_name", "Server 1000\n")
+	env.WriteFile("sys/class/dmi/id/bios_version", "2.1.0\n")
+	env.WriteFile("proc/cpuinfo", `processor	: 0
+physical id	: 0
+core id		: 0
+model name	: Example CPU @ 2.00GHz
+
+processor	: 1
+physical id	: 0
+core id		: 0
+model name	: Example CPU @ 2.00GHz
+
+processor	: 2
+physical id	: 1
+core id		: 0
+model name	: Example CPU @ 2.00GHz
+
+processor	: 3
+physical id	: 1
+core id		: 1
+model name	: Example CPU @ 2.00GHz
+`)
+	env.WriteFile("proc/meminfo", "MemTotal:       16384000 kB\nMemFree:         1024 kB\n")
+	env.WriteFile("sys/class/net/eth0/address", "00:00:00:00:00:01\n")
+	env.WriteFile("sys/class/net/eth0/speed", "1000\n")
+	env.MkdirAll("sys/bus/pci/drivers/e1000e")
+	env.MkdirAll("sys/class/net/eth0/device")
+	env.Symlink("../../../../bus/pci/drivers/e1000e", "sys/class/net/eth0/device/driver")
+	env.WriteFile("sys/class/net/eth1/address", "00:00:00:00:00:02\n")
+	env.WriteFile("sys/class/net/eth1/speed", "-1\n")
+	env.MkdirAll("sys/class/net/eth1/device")
+	env.WriteFile("sys/class/net/lo/address", "00:00:00:00:00:00\n")
+	env.WriteFile("sys/block/sda/size", "2048\n")
+	env.WriteFile("sys/block/sda/device/model", "DISK 1\n")
+	env.WriteFile("sys/block/sda/device/serial", "S1\n")
+	env.WriteFile("sys/block/nvme0n1/size", "4096\n")
+	env.WriteFile("sys/block/nvme0n1/device/serial", "N1\n")
+	env.WriteFile("sys/block/loop0/size", "4096\n")
+	env.WriteFile("sys/bus/pci/devices/0000:00:1f.2/class", "0x010601\n")
+	env.WriteFile("sys/bus/pci/devices/0000:00:1f.2/vendor", "0x8086\n")
+	env.WriteFile("sys/bus/pci/devices/0000:00:1f.2/device", "0xa102\n")
+
+	inv := Collect(env.GetPath("."))
+	assert.Equal(t, System{Manufacturer: "Vendor", Product: "Server 1000"}, inv.System)
+	assert.Equal(t, Firmware{BIOSVersion: "2.1.0"}, inv.Firmware)
+	assert.Equal(t, CPU{Model: "Example CPU @ 2.00GHz", Sockets: 2, Cores: 3, Threads: 4}, inv.CPU)
+	assert.Equal(t, Memory{TotalMiB: 16000}, inv.Memory)
+	assert.Equal(t, []NIC{
+		{Name: "eth0", Hwaddr: "00:00:00:00:00:01", SpeedMbps: 1000, Driver: "e1000e"},
+		{Name: "eth1", Hwaddr: "00:00:00:00:00:02"},
+	}, inv.NICs)
+	assert.Equal(t, []Disk{
+		{Name: "nvme0n1", Serial: "N1", SizeBytes: 4096 * 512},
+		{Name: "sda", Model: "DISK 1", Serial: "S1", SizeBytes: 2048 * 512},
+	}, inv.Disks)
+	assert.Equal(t, []PCIDevice{{Address: "0000:00:1f.2", Class: "0x010601", Vendor: "0x8086", Device: "0xa102"}}, inv.PCI)
+}
+
+func Test_ParseBMCVersion(t *testing.T) {
+	assert.Equal(t, "1.23", parseBMCVersion(`Device ID                 : 32
+Device Revision           : 1
+Firmware Revision         : 1.23
+IPMI Version              : 2.0
+`))
+	assert.Equal(t, "", parseBMCVersion(""))
+}
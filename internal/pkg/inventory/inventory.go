@@ -0,0 +1,202 @@
+// Package inventory collects the hardware inventory of a node, which
+// wwclient reports to warewulfd, and stores the inventory reported by
+// each node.
+package inventory
+
+import (
+	"fmt"
+	"time"
+)
+
+// Inventory is the hardware of a node at the time it was collected.
+type Inventory struct {
+	Collected time.Time   `json:"collected"`
+	System    System      `json:"system"`
+	Firmware  Firmware    `json:"firmware"`
+	CPU       CPU         `json:"cpu"`
+	Memory    Memory      `json:"memory"`
+	DIMMs     []DIMM      `json:"dimms,omitempty"`
+	NICs      []NIC       `json:"nics,omitempty"`
+	Disks     []Disk      `json:"disks,omitempty"`
+	PCI       []PCIDevice `json:"pci,omitempty"`
+}
+
+type System struct {
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Product      string `json:"product,omitempty"`
+	Serial       string `json:"serial,omitempty"`
+	UUID         string `json:"uuid,omitempty"`
+}
+
+type Firmware struct {
+	BIOSVendor  string `json:"bios vendor,omitempty"`
+	BIOSVersion string `json:"bios version,omitempty"`
+	BIOSDate    string `json:"bios date,omitempty"`
+	BMCVersion  string `json:"bmc version,omitempty"`
+}
+
+type CPU struct {
+	Model   string `json:"model,omitempty"`
+	Sockets int    `json:"sockets,omitempty"`
+	Cores   int    `json:"cores,omitempty"`
+	Threads int    `json:"threads,omitempty"`
+}
+
+type Memory struct {
+	TotalMiB int `json:"total mib,omitempty"`
+}
+
+type DIMM struct {
+	Locator      string `json:"locator"`
+	SizeMiB      int    `json:"size mib,omitempty"`
+	Type         string `json:"type,omitempty"`
+	SpeedMTs     int    `json:"speed mts,omitempty"`
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Serial       string `json:"serial,omitempty"`
+	PartNumber   string `json:"part number,omitempty"`
+}
+
+type NIC struct {
+	Name      string `json:"name"`
+	Hwaddr    string `json:"hwaddr,omitempty"`
+	SpeedMbps int    `json:"speed mbps,omitempty"`
+	Driver    string `json:"driver,omitempty"`
+}
+
+type Disk struct {
+	Name      string `json:"name"`
+	Model     string `json:"model,omitempty"`
+	Serial    string `json:"serial,omitempty"`
+	SizeBytes int64  `json:"size bytes,omitempty"`
+}
+
+type PCIDevice struct {
+	Address string `json:"address"`
+	Class   string `json:"class,omitempty"`
+	Vendor  string `json:"vendor,omitempty"`
+	Device  string `json:"device,omitempty"`
+}
+
+// Item is a single value of an inventory, e.g. Field "NICs[eth0].Hwaddr".
+// LinkState is set for values which depend on the state of a link
+// rather than on the hardware, such as the speed of a NIC.
+type Item struct {
+	Field     string
+	Value     string
+	LinkState bool
+}
+
+// Items returns the values of the inventory which are set, in a stable
+// order. Components in lists are identified by their name, locator, or
+// address.
+func (inv *Inventory) Items() (items []Item) {
+	add := func(field string, value interface{}) {
+		switch v := value.(type) {
+		case string:
+			if v == "" {
+				return
+			}
+		case int:
+			if v == 0 {
+				return
+			}
+		case int64:
+			if v == 0 {
+				return
+			}
+		}
+		items = append(items, Item{Field: field, Value: fmt.Sprint(value)})
+	}
+	add("System.Manufacturer", inv.System.Manufacturer)
+	add("System.Product", inv.System.Product)
+	add("System.Serial", inv.System.Serial)
+	add("System.UUID", inv.System.UUID)
+	add("Firmware.BIOSVendor", inv.Firmware.BIOSVendor)
+	add("Firmware.BIOSVersion", inv.Firmware.BIOSVersion)
+	add("Firmware.BIOSDate", inv.Firmware.BIOSDate)
+	add("Firmware.BMCVersion", inv.Firmware.BMCVersion)
+	add("CPU.Model", inv.CPU.Model)
+	add("CPU.Sockets", inv.CPU.Sockets)
+	add("CPU.Cores", inv.CPU.Cores)
+	add("CPU.Threads", inv.CPU.Threads)
+	add("Memory.TotalMiB", inv.Memory.TotalMiB)
+	for _, dimm := range inv.DIMMs {
+		prefix := "DIMMs[" + dimm.Locator + "]."
+		add(prefix+"SizeMiB", dimm.SizeMiB)
+		add(prefix+"Type", dimm.Type)
+		add(prefix+"SpeedMTs", dimm.SpeedMTs)
+		add(prefix+"Manufacturer", dimm.Manufacturer)
+		add(prefix+"Serial", dimm.Serial)
+		add(prefix+"PartNumber", dimm.PartNumber)
+	}
+	for _, nic := range inv.NICs {
+		prefix := "NICs[" + nic.Name + "]."
+		add(prefix+"Hwaddr", nic.Hwaddr)
+		if nic.SpeedMbps != 0 {
+			items = append(items, Item{Field: prefix + "SpeedMbps", Value: fmt.Sprint(nic.SpeedMbps), LinkState: true})
+		}
+		add(prefix+"Driver", nic.Driver)
+	}
+	for _, disk := range inv.Disks {
+		prefix := "Disks[" + disk.Name + "]."
+		add(prefix+"Model", disk.Model)
+		add(prefix+"Serial", disk.Serial)
+		add(prefix+"SizeBytes", disk.SizeBytes)
+	}
+	for _, device := range inv.PCI {
+		prefix := "PCI[" + device.Address + "]."
+		add(prefix+"Class", device.Class)
+		add(prefix+"Vendor", device.Vendor)
+		add(prefix+"Device", device.Device)
+	}
+	return items
+}
+
+// Change is a value which differs between two inventories. Old or New
+// is empty if the value was added or removed.
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Diff returns the values which differ between old and new, ignoring
+// when they were collected and values which depend on the state of a
+// link: first the values of new, then those which were removed. A nil
+// inventory has no values.
+func Diff(old, new *Inventory) (changes []Change) {
+	var oldItems, newItems []Item
+	if old != nil {
+		oldItems = hardwareItems(old.Items())
+	}
+	if new != nil {
+		newItems = hardwareItems(new.Items())
+	}
+	oldValues, newValues := make(map[string]string), make(map[string]string)
+	for _, item := range oldItems {
+		oldValues[item.Field] = item.Value
+	}
+	for _, item := range newItems {
+		newValues[item.Field] = item.Value
+		if oldValues[item.Field] != item.Value {
+			changes = append(changes, Change{Field: item.Field, Old: oldValues[item.Field], New: item.Value})
+		}
+	}
+	for _, item := range oldItems {
+		if _, ok := newValues[item.Field]; !ok {
+			changes = append(changes, Change{Field: item.Field, Old: item.Value})
+		}
+	}
+	return changes
+}
+
+// hardwareItems returns the items which don't depend on the state of a
+// link.
+func hardwareItems(items []Item) (hardware []Item) {
+	for _, item := range items {
+		if !item.LinkState {
+			hardware = append(hardware, item)
+		}
+	}
+	return hardware
+}
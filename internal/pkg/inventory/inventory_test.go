@@ -0,0 +1,86 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Diff(t *testing.T) {
+	old := &Inventory{
+		Collected: time.Unix(1000, 0),
+		CPU:       CPU{Model: "Example CPU", Sockets: 2},
+		DIMMs: []DIMM{
+			{Locator: "A1", SizeMiB: 16384, Serial: "D1"},
+			{Locator: "A2", SizeMiB: 16384, Serial: "D2"},
+		},
+		Disks: []Disk{{Name: "sda", Serial: "S1"}},
+	}
+	new := &Inventory{
+		Collected: time.Unix(2000, 0),
+		CPU:       CPU{Model: "Example CPU", Sockets: 2},
+		DIMMs: []DIMM{
+			{Locator: "A1", SizeMiB: 16384, Serial: "D3"},
+		},
+		Disks: []Disk{{Name: "sda", Serial: "S1"}},
+	}
+	assert.Empty(t, Diff(old, old))
+	assert.Equal(t, []Change{
+		{Field: "DIMMs[A1].Serial", Old: "D1", New: "D3"},
+		{Field: "DIMMs[A2].SizeMiB", Old: "16384"},
+		{Field: "DIMMs[A2].Serial", Old: "D2"},
+	}, Diff(old, new))
+	assert.Equal(t, []Change{
+		{Field: "CPU.Model", New: "Example CPU"},
+		{Field: "CPU.Sockets", New: "2"},
+		{Field: "Disks[sda].Serial", New: "S1"},
+	}, Diff(nil, &Inventory{CPU: old.CPU, Disks: old.Disks}))
+
+	// the speed of a NIC is unknown while its link is down
+	up := &Inventory{NICs: []NIC{{Name: "eth0", Hwaddr: "00:00:00:00:00:01", SpeedMbps: 25000}}}
+	down := &Inventory{NICs: []NIC{{Name: "eth0", Hwaddr: "00:00:00:00:00:01"}}}
+	assert.Empty(t, Diff(up, down))
+	assert.Empty(t, Diff(down, up))
+	assert.Contains(t, up.Items(), Item{Field: "NICs[eth0].SpeedMbps", Value: "25000", LinkState: true})
+}
+
+func Test_Update(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	_, err := Read("n1")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	first := &Inventory{Collected: time.Unix(1000, 0).UTC(), Disks: []Disk{{Name: "sda", Serial: "S1"}}}
+	changes, err := Update("n1", first)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// reporting the same hardware only updates the current inventory
+	same := &Inventory{Collected: time.Unix(2000, 0).UTC(), Disks: []Disk{{Name: "sda", Serial: "S1"}}}
+	changes, err = Update("n1", same)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+	record, err := Read("n1")
+	assert.NoError(t, err)
+	assert.Equal(t, &Record{Node: "n1", Current: same}, record)
+
+	replaced := &Inventory{Collected: time.Unix(3000, 0).UTC(), Disks: []Disk{{Name: "sda", Serial: "S2"}}}
+	changes, err = Update("n1", replaced)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{{Field: "Disks[sda].Serial", Old: "S1", New: "S2"}}, changes)
+	record, err = Read("n1")
+	assert.NoError(t, err)
+	assert.Equal(t, &Record{Node: "n1", Current: replaced, Previous: same}, record)
+
+	assert.NoError(t, Delete("n1"))
+	_, err = Read("n1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, Delete("n1"))
+
+	_, err = Update("../n1", first)
+	assert.EqualError(t, err, `invalid node name: "../n1"`)
+}
@@ -0,0 +1,98 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+)
+
+var ErrNotFound = errors.New("no inventory")
+
+// Record is the stored inventory of a node: the inventory it reported
+// last, and the inventory before its hardware last changed.
+type Record struct {
+	Node     string     `json:"node"`
+	Current  *Inventory `json:"current"`
+	Previous *Inventory `json:"previous,omitempty"`
+}
+
+func recordFile(nodeID string) (string, error) {
+	if nodeID == "" || strings.ContainsAny(nodeID, "/\x00") || nodeID == "." || nodeID == ".." {
+		return "", fmt.Errorf("invalid node name: %q", nodeID)
+	}
+	return path.Join(warewulfconf.Get().Paths.NodeInventoryDir(), nodeID+".json"), nil
+}
+
+// Read returns the stored inventory of a node, or ErrNotFound.
+func Read(nodeID string) (*Record, error) {
+	fileName, err := recordFile(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	record := new(Record)
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", fileName, err)
+	}
+	return record, nil
+}
+
+/*
+Update stores the inventory reported by a node. If its hardware differs
+from the stored inventory, the stored inventory is kept as the previous
+one, and the changes are returned.
+*/
+func Update(nodeID string, inv *Inventory) (changes []Change, err error) {
+	fileName, err := recordFile(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	record, err := Read(nodeID)
+	if errors.Is(err, ErrNotFound) {
+		record = &Record{Node: nodeID}
+	} else if err != nil {
+		return nil, err
+	}
+	if record.Current != nil {
+		changes = Diff(record.Current, inv)
+		if len(changes) > 0 {
+			record.Previous = record.Current
+		}
+	}
+	record.Current = inv
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(path.Dir(fileName), 0755); err != nil {
+		return nil, err
+	}
+	tmpFile := fileName + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return nil, err
+	}
+	return changes, os.Rename(tmpFile, fileName)
+}
+
+// Delete removes the stored inventory of a node, if there is one.
+func Delete(nodeID string) error {
+	fileName, err := recordFile(nodeID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package node
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// AuditSource describes where a change of the node configuration
+// originated.
+type AuditSource string
+
+const (
+	AuditSourceCLI       AuditSource = "cli"
+	AuditSourceAPI       AuditSource = "api"
+	AuditSourceDiscovery AuditSource = "discovery"
+)
+
+// AuditActor is who made a change of the node configuration.
+type AuditActor struct {
+	Name   string
+	Source AuditSource
+}
+
+// AuditChange is the change of a single field of a node or profile.
+type AuditChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old,omitempty"`
+	New   interface{} `json:"new,omitempty"`
+}
+
+// AuditEntry records the changes of a single node or profile.
+type AuditEntry struct {
+	Time    time.Time     `json:"time"`
+	Actor   string        `json:"actor"`
+	Source  AuditSource   `json:"source"`
+	Kind    string        `json:"kind"`
+	Entity  string        `json:"entity"`
+	Action  string        `json:"action"`
+	Changes []AuditChange `json:"changes,omitempty"`
+}
+
+const redacted = "********"
+
+var auditActor *AuditActor
+
+// SetAuditActor sets the actor recorded for changes written with
+// Persist.
+func SetAuditActor(actor AuditActor) {
+	auditActor = &actor
+}
+
+// defaultAuditActor returns the actor set with SetAuditActor, or the
+// unix user running the command. The invoking user is recorded for
+// commands run with sudo.
+func defaultAuditActor() AuditActor {
+	if auditActor != nil {
+		return *auditActor
+	}
+	actor := AuditActor{Name: "unknown", Source: AuditSourceCLI}
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		actor.Name = sudoUser
+	} else if current, err := user.Current(); err == nil {
+		actor.Name = current.Username
+	}
+	return actor
+}
+
+// auditEntities is the on-disk node configuration without types, so
+// that nodes, profiles, and networks can be compared field by field.
+type auditEntities struct {
+	NodeProfiles map[string]map[string]interface{} `yaml:"nodeprofiles"`
+	Nodes        map[string]map[string]interface{} `yaml:"nodes"`
+	Networks     map[string]map[string]interface{} `yaml:"networks"`
+}
+
+// AuditDiff returns an audit entry for each node, profile, and network which
+// differs between the before and after node configuration documents.
+func AuditDiff(before, after []byte, actor AuditActor) (entries []AuditEntry, err error) {
+	var oldDoc, newDoc auditEntities
+	if err = yaml.Unmarshal(before, &oldDoc); err != nil {
+		return nil, err
+	}
+	if err = yaml.Unmarshal(after, &newDoc); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for _, kind := range []struct {
+		name          string
+		before, after map[string]map[string]interface{}
+	}{
+		{"profile", oldDoc.NodeProfiles, newDoc.NodeProfiles},
+		{"node", oldDoc.Nodes, newDoc.Nodes},
+		{"network", oldDoc.Networks, newDoc.Networks},
+	} {
+		for _, id := range auditIds(kind.before, kind.after) {
+			oldEntity, inOld := kind.before[id]
+			newEntity, inNew := kind.after[id]
+			entry := AuditEntry{
+				Time:    now,
+				Actor:   actor.Name,
+				Source:  actor.Source,
+				Kind:    kind.name,
+				Entity:  id,
+				Action:  "update",
+				Changes: auditChanges(oldEntity, newEntity),
+			}
+			if !inOld {
+				entry.Action = "add"
+			} else if !inNew {
+				entry.Action = "delete"
+			} else if len(entry.Changes) == 0 {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func auditIds(before, after map[string]map[string]interface{}) (ids []string) {
+	for id := range before {
+		ids = append(ids, id)
+	}
+	for id := range after {
+		if _, ok := before[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func auditChanges(before, after map[string]interface{}) (changes []AuditChange) {
+	oldFields := make(map[string]interface{})
+	newFields := make(map[string]interface{})
+	flattenFields("", before, oldFields)
+	flattenFields("", after, newFields)
+	var fields []string
+	for field := range oldFields {
+		fields = append(fields, field)
+	}
+	for field := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		oldValue, newValue := oldFields[field], newFields[field]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if strings.HasSuffix(field, "password") {
+			if oldValue != nil {
+				oldValue = redacted
+			}
+			if newValue != nil {
+				newValue = redacted
+			}
+		}
+		changes = append(changes, AuditChange{Field: field, Old: oldValue, New: newValue})
+	}
+	return changes
+}
+
+// flattenFields adds all values of fields to flat, keyed by their
+// dot-separated path, e.g. "network devices.default.ipaddr".
+func flattenFields(prefix string, fields map[string]interface{}, flat map[string]interface{}) {
+	for key, value := range fields {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenFields(field, nested, flat)
+		} else {
+			flat[field] = value
+		}
+	}
+}
+
+// AuditValueString formats an old or new value of an AuditChange for
+// display.
+func AuditValueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "--"
+	case string:
+		return v
+	default:
+		out, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(out)
+	}
+}
+
+// WriteAuditLog appends entries to the audit log.
+func WriteAuditLog(entries []AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	auditLog := warewulfconf.Get().Paths.AuditLog()
+	if err := os.MkdirAll(path.Dir(auditLog), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(auditLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(buf.Bytes())
+	return err
+}
+
+// ReadAuditLog returns all entries of the audit log. A missing audit
+// log has no entries.
+func ReadAuditLog() (entries []AuditEntry, err error) {
+	file, err := os.Open(warewulfconf.Get().Paths.AuditLog())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return entries, fmt.Errorf("audit log line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,92 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_AuditDiff(t *testing.T) {
+	before := `
+nodeprofiles:
+  default:
+    comment: old
+nodes:
+  n1:
+    profiles:
+    - default
+    ipmi:
+      password: secret
+    network devices:
+      default:
+        ipaddr: 10.0.0.1
+  n2: {}`
+	after := `
+nodeprofiles:
+  default:
+    comment: new
+nodes:
+  n1:
+    profiles:
+    - default
+    ipmi:
+      password: other
+    network devices:
+      default:
+        ipaddr: 10.0.0.2
+        hwaddr: 00:00:00:00:00:01
+  n3:
+    comment: added`
+	actor := AuditActor{Name: "admin", Source: AuditSourceAPI}
+	entries, err := AuditDiff([]byte(before), []byte(after), actor)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 4)
+
+	assert.Equal(t, "profile", entries[0].Kind)
+	assert.Equal(t, "default", entries[0].Entity)
+	assert.Equal(t, "update", entries[0].Action)
+	assert.Equal(t, []AuditChange{{Field: "comment", Old: "old", New: "new"}}, entries[0].Changes)
+
+	assert.Equal(t, "n1", entries[1].Entity)
+	assert.Equal(t, "admin", entries[1].Actor)
+	assert.Equal(t, AuditSourceAPI, entries[1].Source)
+	assert.Equal(t, []AuditChange{
+		{Field: "ipmi.password", Old: redacted, New: redacted},
+		{Field: "network devices.default.hwaddr", New: "00:00:00:00:00:01"},
+		{Field: "network devices.default.ipaddr", Old: "10.0.0.1", New: "10.0.0.2"},
+	}, entries[1].Changes)
+
+	assert.Equal(t, "n2", entries[2].Entity)
+	assert.Equal(t, "delete", entries[2].Action)
+	assert.Empty(t, entries[2].Changes)
+
+	assert.Equal(t, "n3", entries[3].Entity)
+	assert.Equal(t, "add", entries[3].Action)
+	assert.Equal(t, []AuditChange{{Field: "comment", New: "added"}}, entries[3].Changes)
+}
+
+func Test_PersistAs(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles: {}
+nodes:
+  n1: {}`)
+
+	registry, err := New()
+	assert.NoError(t, err)
+	registry.Nodes["n1"].Comment = "changed"
+	assert.NoError(t, registry.PersistAs(AuditActor{Name: "00:00:00:00:00:01", Source: AuditSourceDiscovery}))
+	// persisting without changes is not recorded
+	assert.NoError(t, registry.PersistAs(AuditActor{Name: "admin", Source: AuditSourceCLI}))
+
+	entries, err := ReadAuditLog()
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "00:00:00:00:00:01", entries[0].Actor)
+		assert.Equal(t, AuditSourceDiscovery, entries[0].Source)
+		assert.Equal(t, "n1", entries[0].Entity)
+		assert.Equal(t, []AuditChange{{Field: "comment", New: "changed"}}, entries[0].Changes)
+	}
+}
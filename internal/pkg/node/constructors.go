@@ -1,31 +1,43 @@
 package node
 
 import (
-	"os"
 	"sort"
 	"syscall"
 
-	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 
 	"gopkg.in/yaml.v3"
 )
 
 func CanWriteConfig() bool {
-	return syscall.Access(warewulfconf.Get().Paths.NodesConf(), syscall.O_RDWR) == nil
+	store, err := OpenStore()
+	if err != nil {
+		return false
+	}
+	return syscall.Access(store.File(), syscall.O_RDWR) == nil
 }
 
 /*
-Creates a new nodeDb object from the on-disk configuration
+Creates a new nodeDb object from the configured storage backend
 */
 func New() (NodesYaml, error) {
-	nodesConf := warewulfconf.Get().Paths.NodesConf()
-	wwlog.Verbose("Opening node configuration file: %s", nodesConf)
-	data, err := os.ReadFile(nodesConf)
+	store, err := OpenStore()
 	if err != nil {
 		return NodesYaml{}, err
 	}
-	return Parse(data)
+	wwlog.Verbose("Opening node configuration: %s", store.File())
+	registry, err := store.Load()
+	if err != nil {
+		return registry, err
+	}
+	// the hash is calculated from a copy, as hashing flattens the
+	// nodes and profiles
+	data, err := yaml.Marshal(&registry)
+	if err != nil {
+		return registry, err
+	}
+	registry.rev = Revision(data)
+	return registry, nil
 }
 
 // Parse constructs a new nodeDb object from an input YAML
@@ -45,6 +57,9 @@ func Parse(data []byte) (nodeList NodesYaml, err error) {
 	if nodeList.NodeProfiles == nil {
 		nodeList.NodeProfiles = map[string]*Profile{}
 	}
+	if nodeList.Networks == nil {
+		nodeList.Networks = map[string]*Network{}
+	}
 	wwlog.Debug("returning node object")
 	return nodeList, nil
 }
@@ -166,35 +181,6 @@ func (config *NodesYaml) FindAllProfiles(nodes ...string) (profileList []Profile
 	return profileList, nil
 }
 
-/*
-FindDiscoverableNode returns the first discoverable node and an
-interface to associate with the discovered interface. If the nodUNDEFe has
-a primary interface, it is returned; otherwise, the first interface
-without a hardware address is returned.
-
-If no unconfigured node is found, an error is returned.
-*/
-func (config *NodesYaml) FindDiscoverableNode() (Node, string, error) {
-
-	nodes, _ := config.FindAllNodes()
-
-	for _, node := range nodes {
-		if !(node.Discoverable.Bool()) {
-			continue
-		}
-		if _, ok := node.NetDevs[node.PrimaryNetDev]; ok {
-			return node, node.PrimaryNetDev, nil
-		}
-		for netdev, dev := range node.NetDevs {
-			if dev.Hwaddr != "" {
-				return node, netdev, nil
-			}
-		}
-	}
-
-	return EmptyNode(), "", ErrNoUnconfigured
-}
-
 func (node *Node) setIds(id string) {
 	node.id = id
 	for diskId, disk := range node.Disks {
@@ -142,7 +142,7 @@ func Test_FindDiscoverableNode(t *testing.T) {
 			for _, node := range tt.discoverable_nodes {
 				config.Nodes[node].Discoverable = "true"
 			}
-			discovered_node, discovered_interface, err := config.FindDiscoverableNode()
+			discovered_node, discovered_interface, err := config.FindDiscoverableNode(Machine{Hwaddr: "00:00:00:00:00:01"})
 			if !tt.succeed {
 				assert.Error(t, err)
 			} else {
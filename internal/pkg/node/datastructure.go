@@ -15,6 +15,9 @@ Structure of which goes to disk
 type NodesYaml struct {
 	NodeProfiles map[string]*Profile `yaml:"nodeprofiles"`
 	Nodes        map[string]*Node    `yaml:"nodes"`
+	Networks     map[string]*Network `yaml:"networks,omitempty"`
+	// revision of nodes.conf when it was read, or last written
+	rev string
 }
 
 /*
@@ -26,6 +29,7 @@ type Node struct {
 	// exported values
 	Discoverable wwtype.WWbool     `yaml:"discoverable,omitempty" json:"discoverable,omitempty" lopt:"discoverable" sopt:"e" comment:"Make discoverable in given network (true/false)"`
 	AssetKey     string            `yaml:"asset key,omitempty"    json:"asset key,omitempty"    lopt:"asset"                 comment:"Set the node's Asset tag (key)"`
+	Discover     *DiscoverConf     `yaml:"discover,omitempty"     json:"discover,omitempty"`
 	Profile      `yaml:"-,inline"` // include all values set in the profile, but inline them in yaml output if these are part of Node
 }
 
@@ -68,6 +72,16 @@ type IpmiConf struct {
 	Tags       map[string]string `yaml:"tags,omitempty"       json:"tags,omitempty"`
 }
 
+/*
+DiscoverConf holds the rules which a machine must match to be
+discovered as a node.
+*/
+type DiscoverConf struct {
+	UUID       string `yaml:"uuid,omitempty"        json:"uuid,omitempty"        lopt:"discover-uuid"       comment:"Only discover the node on a machine with this SMBIOS UUID"`
+	Serial     string `yaml:"serial,omitempty"      json:"serial,omitempty"      lopt:"discover-serial"     comment:"Only discover the node on a machine with this serial number"`
+	SwitchPort string `yaml:"switch port,omitempty" json:"switch port,omitempty" lopt:"discover-switchport" comment:"Only discover the node on a machine connected to this switch port (SWITCH:PORT)"`
+}
+
 type KernelConf struct {
 	Version string   `yaml:"version,omitempty" json:"version,omitempty" lopt:"kernelversion"          comment:"Set kernel version"`
 	Args    []string `yaml:"args,omitempty"    json:"args,omitempty"    lopt:"kernelargs"    sopt:"A" comment:"Set kernel arguments"`
@@ -84,6 +98,7 @@ type NetDev struct {
 	Netmask net.IP            `yaml:"netmask,omitempty" json:"netmask,omitempty"  lopt:"netmask" sopt:"M" comment:"Set the networks netmask" type:"IP"`
 	Gateway net.IP            `yaml:"gateway,omitempty" json:"gateway,omitempty"  lopt:"gateway" sopt:"G" comment:"Set the node's network device gateway" type:"IP"`
 	MTU     string            `yaml:"mtu,omitempty"     json:"mtu,omitempty"      lopt:"mtu"              comment:"Set the mtu" type:"uint"`
+	Network string            `yaml:"network,omitempty" json:"network,omitempty"  lopt:"network"          comment:"Set the network to allocate the address from"`
 	Tags    map[string]string `yaml:"tags,omitempty"    json:"tags,omitempty"`
 	primary bool
 }
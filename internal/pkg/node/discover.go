@@ -0,0 +1,153 @@
+package node
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+Machine identifies a machine which requests to be provisioned, as
+reported by iPXE, the initramfs, or wwclient.
+*/
+type Machine struct {
+	Hwaddr     string `json:"hwaddr"`
+	UUID       string `json:"uuid,omitempty"`
+	Serial     string `json:"serial,omitempty"`
+	AssetTag   string `json:"asset tag,omitempty"`
+	SwitchPort string `json:"switch port,omitempty"`
+}
+
+/*
+HasDiscoverRules returns true if the node is only discovered on a
+machine which matches its discovery rules or asset key.
+*/
+func (node *Node) HasDiscoverRules() bool {
+	if node.AssetKey != "" {
+		return true
+	}
+	return node.Discover != nil && (node.Discover.UUID != "" || node.Discover.Serial != "" || node.Discover.SwitchPort != "")
+}
+
+/*
+MatchesMachine returns true if the machine matches all discovery rules
+and the asset key of the node. UUIDs and serial numbers are compared
+ignoring case.
+*/
+func (node *Node) MatchesMachine(machine Machine) bool {
+	if node.AssetKey != "" && node.AssetKey != machine.AssetTag {
+		return false
+	}
+	if node.Discover == nil {
+		return true
+	}
+	if node.Discover.UUID != "" && !strings.EqualFold(node.Discover.UUID, machine.UUID) {
+		return false
+	}
+	if node.Discover.Serial != "" && !strings.EqualFold(node.Discover.Serial, machine.Serial) {
+		return false
+	}
+	if node.Discover.SwitchPort != "" && node.Discover.SwitchPort != machine.SwitchPort {
+		return false
+	}
+	return true
+}
+
+/*
+FindDiscoverableNode returns the discoverable node for the machine and
+the interface to associate with its hardware address. A node with
+discovery rules is only returned for a machine which matches them; a
+node without them is returned for any machine, but only if no node
+with rules matches. The interface is the primary interface of the
+node, if it has one.
+
+The switch port is only reported by the initramfs. If the machine
+reported none, but would match a node with a switch port rule if it
+did, that node is returned with ErrDiscoveryDeferred, so that it can
+boot far enough to report it. If no discoverable node is found,
+ErrNoUnconfigured is returned.
+*/
+func (config *NodesYaml) FindDiscoverableNode(machine Machine) (Node, string, error) {
+	nodes, _ := config.FindAllNodes()
+
+	var deferred, fallback *Node
+	for i := range nodes {
+		node := &nodes[i]
+		if !(node.Discoverable.Bool()) {
+			continue
+		}
+		if node.HasDiscoverRules() {
+			if node.MatchesMachine(machine) {
+				return *node, node.discoverNetDev(), nil
+			}
+			if deferred == nil && machine.SwitchPort == "" && node.Discover != nil && node.Discover.SwitchPort != "" {
+				reported := machine
+				reported.SwitchPort = node.Discover.SwitchPort
+				if node.MatchesMachine(reported) {
+					deferred = node
+				}
+			}
+		} else if fallback == nil {
+			fallback = node
+		}
+	}
+	if deferred != nil {
+		return *deferred, deferred.discoverNetDev(), ErrDiscoveryDeferred
+	}
+	if fallback != nil {
+		return *fallback, fallback.discoverNetDev(), nil
+	}
+
+	return EmptyNode(), "", ErrNoUnconfigured
+}
+
+// discoverNetDev returns the interface of the node which is assigned a
+// discovered hardware address.
+func (node *Node) discoverNetDev() string {
+	if _, ok := node.NetDevs[node.PrimaryNetDev]; ok {
+		return node.PrimaryNetDev
+	}
+	for netdev, dev := range node.NetDevs {
+		if dev.Hwaddr != "" {
+			return netdev
+		}
+	}
+	return ""
+}
+
+/*
+DiscoverNode assigns hwaddr to the interface netdev of the node, clears
+its discoverable field, and allocates its addresses. If netdev is
+empty, the interface is chosen as for discovery. The changes must be
+persisted by the caller.
+*/
+func (config *NodesYaml) DiscoverNode(id, netdev, hwaddr string) (string, error) {
+	nodeChanges, err := config.GetNodeOnly(id)
+	if err != nil {
+		return "", err
+	}
+	if netdev == "" {
+		merged, err := config.GetNode(id)
+		if err != nil {
+			return "", err
+		}
+		netdev = merged.discoverNetDev()
+	}
+	if netdev == "" {
+		netdev = "default"
+	}
+	if nodeChanges.NetDevs == nil {
+		nodeChanges.NetDevs = make(map[string]*NetDev)
+	}
+	if _, ok := nodeChanges.NetDevs[netdev]; !ok {
+		nodeChanges.NetDevs[netdev] = new(NetDev)
+	}
+	nodeChanges.NetDevs[netdev].Hwaddr = hwaddr
+	nodeChanges.Discoverable = "UNDEF"
+	if err := config.SetNode(id, nodeChanges); err != nil {
+		return netdev, err
+	}
+	if err := config.AllocateIPs(id); err != nil {
+		return netdev, fmt.Errorf("failed to allocate addresses: %w", err)
+	}
+	return netdev, nil
+}
@@ -0,0 +1,96 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FindDiscoverableNodeRules(t *testing.T) {
+	config, err := Parse([]byte(`
+nodes:
+  n1:
+    discoverable: true
+    network devices:
+      default: {}
+  n2:
+    discoverable: true
+    discover:
+      uuid: 2E6C2E2A-0000-0000-0000-000000000002
+    network devices:
+      default: {}
+  n3:
+    discoverable: true
+    asset key: rack3-u12
+    discover:
+      switch port: sw3:Ethernet1/12
+    network devices:
+      default: {}
+  n4:
+    discover:
+      serial: S4
+    network devices:
+      default: {}`))
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		machine Machine
+		node    string
+		err     error
+	}{
+		"uuid":              {machine: Machine{Hwaddr: "00:00:00:00:00:02", UUID: "2e6c2e2a-0000-0000-0000-000000000002"}, node: "n2"},
+		"asset and port":    {machine: Machine{Hwaddr: "00:00:00:00:00:03", AssetTag: "rack3-u12", SwitchPort: "sw3:Ethernet1/12"}, node: "n3"},
+		"asset only":        {machine: Machine{Hwaddr: "00:00:00:00:00:03", AssetTag: "rack3-u12"}, node: "n3", err: ErrDiscoveryDeferred},
+		"asset, other port": {machine: Machine{Hwaddr: "00:00:00:00:00:03", AssetTag: "rack3-u12", SwitchPort: "sw3:Ethernet1/13"}, node: "n1"},
+		"port only":         {machine: Machine{Hwaddr: "00:00:00:00:00:03", SwitchPort: "sw3:Ethernet1/12"}, node: "n1"},
+		"not discoverable":  {machine: Machine{Hwaddr: "00:00:00:00:00:04", Serial: "S4"}, node: "n1"},
+		"no rules":          {machine: Machine{Hwaddr: "00:00:00:00:00:05"}, node: "n1"},
+		"no matching rules": {machine: Machine{Hwaddr: "00:00:00:00:00:06", UUID: "other"}, node: "n1"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			n, netdev, err := config.FindDiscoverableNode(tt.machine)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.node, n.Id())
+			assert.Equal(t, "default", netdev)
+		})
+	}
+
+	config.Nodes["n1"].Discoverable = "false"
+	_, _, err = config.FindDiscoverableNode(Machine{Hwaddr: "00:00:00:00:00:06", UUID: "other"})
+	assert.ErrorIs(t, err, ErrNoUnconfigured)
+}
+
+func Test_DiscoverNode(t *testing.T) {
+	config, err := Parse([]byte(`
+nodeprofiles:
+  default:
+    network devices:
+      default:
+        netmask: 255.255.255.0
+nodes:
+  n1:
+    discoverable: true
+    profiles:
+    - default
+    network devices:
+      ib0:
+        type: infiniband`))
+	assert.NoError(t, err)
+
+	netdev, err := config.DiscoverNode("n1", "", "00:00:00:00:00:01")
+	assert.NoError(t, err)
+	assert.Equal(t, "default", netdev)
+	n, err := config.GetNode("n1")
+	assert.NoError(t, err)
+	assert.Equal(t, "00:00:00:00:00:01", n.NetDevs["default"].Hwaddr)
+	assert.Equal(t, "infiniband", n.NetDevs["ib0"].Type)
+	assert.False(t, n.Discoverable.Bool())
+
+	_, err = config.DiscoverNode("n2", "", "00:00:00:00:00:02")
+	assert.Error(t, err)
+}
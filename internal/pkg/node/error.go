@@ -4,3 +4,5 @@ import "errors"
 
 var ErrNotFound = errors.New("node/profile not found")
 var ErrNoUnconfigured = errors.New("no unconfigured node")
+var ErrDiscoveryDeferred = errors.New("discovery deferred until the switch port is reported")
+var ErrConflict = errors.New("node configuration was changed since it was read")
@@ -0,0 +1,138 @@
+package node
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+
+	"github.com/warewulf/warewulf/internal/pkg/util"
+)
+
+// FieldSource is a single contribution to the value of a field of a
+// merged node: the value set by one profile, the node itself, or a
+// network.
+//
+// Overridden is set for scalar fields when a later source replaces the
+// value. Removed lists the entries of list fields which were removed by
+// a negation ("~entry") and the negations themselves.
+type FieldSource struct {
+	Source     string
+	Value      string
+	Overridden bool     `json:",omitempty"`
+	Removed    []string `json:",omitempty"`
+}
+
+// negatedFields are the list fields from which negated entries are
+// removed by cleanLists.
+var negatedFields = []string{"Profiles", "SystemOverlay", "RuntimeOverlay", "Kernel.Args"}
+
+/*
+ExplainNode merges the node identified by id with its profiles, like
+MergeNode, and returns its fields with the chain of sources for each:
+every profile in merge order, the node itself, and the network of a
+network device. The value of each field is the final merged value,
+after negated entries are removed.
+*/
+func (config *NodesYaml) ExplainNode(id string) (explained []Field, err error) {
+	n, fields, err := config.MergeNode(id)
+	if err != nil {
+		return nil, err
+	}
+	rawNode, err := config.GetNodeOnly(id)
+	if err != nil {
+		return nil, err
+	}
+
+	type source struct {
+		name string
+		obj  interface{}
+	}
+	var sources []source
+	for _, profileID := range config.getNodeProfiles(id) {
+		if profile, err := config.GetProfile(profileID); err == nil {
+			sources = append(sources, source{profileID, profile})
+		}
+	}
+	sources = append(sources, source{id, rawNode})
+
+	for _, field := range fields.List(n) {
+		value, err := getNestedFieldValue(n, field.Field)
+		if err != nil {
+			return nil, fmt.Errorf("could not explain %s of %s: %w", field.Field, id, err)
+		}
+		field.Value = valueStr(value)
+		isList := value.Kind() == reflect.Slice && value.Type() != reflect.TypeOf(net.IP{})
+		scalar := isScalar(value)
+
+		// entries of list fields, per source in the chain
+		var entries [][]string
+		for _, src := range sources {
+			if field.Field == "Profiles" && src.name != id {
+				// nested profiles are expanded into the chain of
+				// sources, not merged
+				continue
+			}
+			srcValue, err := getNestedFieldValue(src.obj, field.Field)
+			if err != nil || valueStr(srcValue) == "" {
+				continue
+			}
+			if scalar && len(field.Chain) > 0 {
+				field.Chain[len(field.Chain)-1].Overridden = true
+			}
+			field.Chain = append(field.Chain, FieldSource{Source: src.name, Value: valueStr(srcValue)})
+			entries = append(entries, listEntries(srcValue))
+		}
+		if networkSource := fields.Source(field.Field); strings.HasPrefix(networkSource, "network:") {
+			field.Chain = append(field.Chain, FieldSource{Source: networkSource, Value: field.Value})
+		}
+
+		if isList && util.InSlice(negatedFields, field.Field) {
+			var negated []string
+			for _, sourceEntries := range entries {
+				negated = append(negated, negList(sourceEntries)...)
+			}
+			for i, sourceEntries := range entries {
+				for _, entry := range sourceEntries {
+					if strings.HasPrefix(entry, "~") || util.InSlice(negated, entry) {
+						field.Chain[i].Removed = append(field.Chain[i].Removed, entry)
+					}
+				}
+			}
+		}
+		explained = append(explained, field)
+	}
+	return explained, nil
+}
+
+// listEntries returns the entries of a slice value as strings.
+func listEntries(value reflect.Value) (entries []string) {
+	if !value.IsValid() || value.Kind() != reflect.Slice {
+		return nil
+	}
+	for i := 0; i < value.Len(); i++ {
+		entries = append(entries, fmt.Sprintf("%v", value.Index(i)))
+	}
+	return entries
+}
+
+// isScalar reports whether a later source replaces value as a whole.
+// Lists, and maps such as resources, may be only partially set by a
+// later source, so their earlier values are not overridden.
+func isScalar(value reflect.Value) bool {
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return true
+		}
+		value = value.Elem()
+	}
+	if value.Type() == reflect.TypeOf(net.IP{}) {
+		return true
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		return false
+	default:
+		return true
+	}
+}
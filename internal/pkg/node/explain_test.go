@@ -0,0 +1,131 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExplainNode(t *testing.T) {
+	registry, err := Parse([]byte(`
+networks:
+  cluster:
+    cidr: 10.0.0.0/24
+    gateway: 10.0.0.1
+nodeprofiles:
+  default:
+    image name: rocky8
+    kernel:
+      args:
+      - quiet
+      - crashkernel=no
+    system overlay:
+    - wwinit
+  gpu:
+    profiles:
+    - default
+    image name: rocky9
+    kernel:
+      args:
+      - ~quiet
+      - nomodeset
+nodes:
+  n1:
+    profiles:
+    - gpu
+    kernel:
+      args:
+      - console=ttyS0
+    network devices:
+      default:
+        network: cluster
+        ipaddr: 10.0.0.2`))
+	assert.NoError(t, err)
+
+	fields, err := registry.ExplainNode("n1")
+	assert.NoError(t, err)
+	explained := make(map[string]Field)
+	for _, field := range fields {
+		explained[field.Field] = field
+	}
+
+	assert.Equal(t, Field{
+		Field:  "Profiles",
+		Source: "",
+		Value:  "gpu",
+		Chain:  []FieldSource{{Source: "n1", Value: "gpu"}},
+	}, explained["Profiles"])
+	assert.Equal(t, Field{
+		Field:  "ImageName",
+		Source: "gpu",
+		Value:  "rocky9",
+		Chain: []FieldSource{
+			{Source: "default", Value: "rocky8", Overridden: true},
+			{Source: "gpu", Value: "rocky9"},
+		},
+	}, explained["ImageName"])
+	assert.Equal(t, Field{
+		Field:  "Kernel.Args",
+		Source: "default,gpu,n1",
+		Value:  "crashkernel=no,nomodeset,console=ttyS0",
+		Chain: []FieldSource{
+			{Source: "default", Value: "quiet,crashkernel=no", Removed: []string{"quiet"}},
+			{Source: "gpu", Value: "~quiet,nomodeset", Removed: []string{"~quiet"}},
+			{Source: "n1", Value: "console=ttyS0"},
+		},
+	}, explained["Kernel.Args"])
+	assert.Equal(t, Field{
+		Field:  "NetDevs[default].Gateway",
+		Source: "network:cluster",
+		Value:  "10.0.0.1",
+		Chain:  []FieldSource{{Source: "network:cluster", Value: "10.0.0.1"}},
+	}, explained["NetDevs[default].Gateway"])
+
+	_, err = registry.ExplainNode("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func Test_ExplainNodeOverridden(t *testing.T) {
+	registry, err := Parse([]byte(`
+nodeprofiles:
+  default:
+    comment: profile comment
+    tags:
+      rack: r1
+    resources:
+      partial:
+        a: 1
+        b: 2
+      replaced: one
+nodes:
+  n1:
+    profiles:
+    - default
+    comment: node comment
+    tags:
+      rack: r2
+    resources:
+      partial:
+        b: 3
+      replaced: two`))
+	assert.NoError(t, err)
+
+	fields, err := registry.ExplainNode("n1")
+	assert.NoError(t, err)
+	explained := make(map[string]Field)
+	for _, field := range fields {
+		explained[field.Field] = field
+	}
+
+	for _, name := range []string{"Comment", "Tags[rack]", "Resources[replaced]"} {
+		if assert.Len(t, explained[name].Chain, 2, name) {
+			assert.True(t, explained[name].Chain[0].Overridden, name)
+			assert.False(t, explained[name].Chain[1].Overridden, name)
+		}
+	}
+	assert.Equal(t, `{"a":1,"b":3}`, explained["Resources[partial]"].Value)
+	assert.Equal(t, []FieldSource{
+		{Source: "default", Value: `{"a":1,"b":2}`},
+		{Source: "n1", Value: `{"b":3}`},
+	}, explained["Resources[partial]"].Chain)
+}
@@ -3,9 +3,11 @@ package node
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -14,10 +16,13 @@ import (
 // the actual string value.
 //
 // It is primarily used to provide desired output for `wwctl <node|profile> list -a`.
+//
+// Fields returned by ExplainNode also carry the chain of sources which contributed to the value.
 type Field struct {
 	Field  string
 	Source string
 	Value  string
+	Chain  []FieldSource `json:",omitempty"`
 }
 
 // Set updates the field with the given source and value. If the value is empty, the operation
@@ -92,6 +97,103 @@ func GetFieldList(obj interface{}) (fields []Field) {
 	return fields
 }
 
+// SetField sets the field name of obj, which must be a pointer, from its string representation
+// as returned by GetFieldList. Field names are matched ignoring case. Structs and map entries
+// along the path are created as needed, and an empty value unsets the field.
+func SetField(obj interface{}, name, value string) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer {
+		return fmt.Errorf("cannot set %s of %T", name, obj)
+	}
+	v = v.Elem()
+	parts := splitFieldName(name)
+	for i, part := range parts {
+		fieldName, key := parseMapField(part)
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("unknown field: %s", name)
+		}
+		v = v.FieldByNameFunc(func(s string) bool { return strings.EqualFold(s, fieldName) })
+		if !v.IsValid() || !v.CanSet() {
+			return fmt.Errorf("unknown field: %s", name)
+		}
+		if key == "" {
+			continue
+		}
+		if v.Kind() != reflect.Map {
+			return fmt.Errorf("unknown field: %s", name)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		keyValue := reflect.ValueOf(key)
+		elemType := v.Type().Elem()
+		if i == len(parts)-1 {
+			if value == "" {
+				v.SetMapIndex(keyValue, reflect.Value{})
+				return nil
+			}
+			elem := reflect.New(elemType).Elem()
+			if err := setFieldValue(elem, value); err != nil {
+				return fmt.Errorf("invalid value for %s: %w", name, err)
+			}
+			v.SetMapIndex(keyValue, elem)
+			return nil
+		}
+		if elemType.Kind() != reflect.Pointer {
+			return fmt.Errorf("unknown field: %s", name)
+		}
+		elem := v.MapIndex(keyValue)
+		if !elem.IsValid() || elem.IsNil() {
+			elem = reflect.New(elemType.Elem())
+			v.SetMapIndex(keyValue, elem)
+		}
+		v = elem
+	}
+	if err := setFieldValue(v, value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+	return nil
+}
+
+// setFieldValue sets v from its string representation as returned by valueStr.
+func setFieldValue(v reflect.Value, value string) error {
+	if value == "" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	switch {
+	case v.Type() == reflect.TypeOf(net.IP{}):
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return fmt.Errorf("invalid IP address: %s", value)
+		}
+		v.Set(reflect.ValueOf(ip))
+	case v.Type() == reflect.TypeOf([]string{}):
+		v.Set(reflect.ValueOf(strings.Split(value, ",")))
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	default:
+		ptr := reflect.New(v.Type())
+		if err := json.Unmarshal([]byte(value), ptr.Interface()); err != nil {
+			return err
+		}
+		v.Set(ptr.Elem())
+	}
+	return nil
+}
+
 var mapFieldElement *regexp.Regexp
 
 func init() {
@@ -165,6 +165,9 @@ func Test_listFields(t *testing.T) {
 			fields: []string{
 				"Discoverable",
 				"AssetKey",
+				"Discover.UUID",
+				"Discover.Serial",
+				"Discover.SwitchPort",
 				"Profiles",
 				"Comment",
 				"ClusterName",
@@ -196,6 +199,7 @@ func Test_listFields(t *testing.T) {
 				"NetDevs[default].Netmask",
 				"NetDevs[default].Gateway",
 				"NetDevs[default].MTU",
+				"NetDevs[default].Network",
 				"NetDevs[default].Tags[nettag]",
 				"Tags[tag]",
 				"PrimaryNetDev",
@@ -250,6 +254,7 @@ func Test_listFields(t *testing.T) {
 				"NetDevs[default].Netmask",
 				"NetDevs[default].Gateway",
 				"NetDevs[default].MTU",
+				"NetDevs[default].Network",
 				"NetDevs[default].Tags[nettag]",
 				"Tags[tag]",
 				"PrimaryNetDev",
@@ -3,6 +3,8 @@ package node
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"slices"
+	"sort"
 
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 	"gopkg.in/yaml.v3"
@@ -33,3 +35,50 @@ func (config *NodesYaml) StringHash() string {
 	buffer := config.Hash()
 	return hex.EncodeToString(buffer[:])
 }
+
+/*
+NodeHashes returns a hash for each node, calculated from the node, all
+of the profiles it is merged with, and the networks they reference. The
+hash of a node changes when the node, one of its profiles, or one of its
+networks changes, without merging the node.
+*/
+func (config *NodesYaml) NodeHashes() map[string]string {
+	profileData := make(map[string][]byte)
+	for id, profile := range config.NodeProfiles {
+		data, err := yaml.Marshal(profile)
+		if err != nil {
+			wwlog.Warn("couldn't marshall profile %s for hashing", id)
+		}
+		profileData[id] = data
+	}
+	hashes := make(map[string]string)
+	for id, node := range config.Nodes {
+		hash := sha256.New()
+		data, err := yaml.Marshal(node)
+		if err != nil {
+			wwlog.Warn("couldn't marshall node %s for hashing", id)
+		}
+		hash.Write(data)
+		networks := node.networkNames()
+		for _, profileID := range config.getNodeProfiles(id) {
+			hash.Write([]byte("\x00" + profileID + "\x00"))
+			hash.Write(profileData[profileID])
+			if profile, ok := config.NodeProfiles[profileID]; ok {
+				networks = append(networks, profile.networkNames()...)
+			}
+		}
+		sort.Strings(networks)
+		for _, networkID := range slices.Compact(networks) {
+			if network, ok := config.Networks[networkID]; ok {
+				data, err := yaml.Marshal(network)
+				if err != nil {
+					wwlog.Warn("couldn't marshall network %s for hashing", networkID)
+				}
+				hash.Write([]byte("\x00network:" + networkID + "\x00"))
+				hash.Write(data)
+			}
+		}
+		hashes[id] = hex.EncodeToString(hash.Sum(nil))
+	}
+	return hashes
+}
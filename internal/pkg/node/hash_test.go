@@ -110,3 +110,54 @@ nodes:
 		}
 	})
 }
+
+func TestNodeHashes(t *testing.T) {
+	nodesConf := `
+nodeprofiles:
+  default:
+    profiles:
+    - nested
+  nested:
+    comment: nested profile
+  other:
+    comment: other profile
+nodes:
+  n01:
+    profiles:
+    - default
+  n02:
+    profiles:
+    - other
+  n03: {}
+`
+	registry, err := Parse([]byte(nodesConf))
+	assert.NoError(t, err)
+	hashes := registry.NodeHashes()
+	assert.Len(t, hashes, 3)
+
+	t.Run("unchanged", func(t *testing.T) {
+		registry, err := Parse([]byte(nodesConf))
+		assert.NoError(t, err)
+		assert.Equal(t, hashes, registry.NodeHashes())
+	})
+
+	t.Run("nested profile changed", func(t *testing.T) {
+		registry, err := Parse([]byte(nodesConf))
+		assert.NoError(t, err)
+		registry.NodeProfiles["nested"].Comment = "changed"
+		changed := registry.NodeHashes()
+		assert.NotEqual(t, hashes["n01"], changed["n01"])
+		assert.Equal(t, hashes["n02"], changed["n02"])
+		assert.Equal(t, hashes["n03"], changed["n03"])
+	})
+
+	t.Run("node changed", func(t *testing.T) {
+		registry, err := Parse([]byte(nodesConf))
+		assert.NoError(t, err)
+		registry.Nodes["n03"].Comment = "changed"
+		changed := registry.NodeHashes()
+		assert.Equal(t, hashes["n01"], changed["n01"])
+		assert.Equal(t, hashes["n02"], changed["n02"])
+		assert.NotEqual(t, hashes["n03"], changed["n03"])
+	})
+}
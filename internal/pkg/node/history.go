@@ -0,0 +1,185 @@
+package node
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// HistorySize is the number of previous versions of nodes.conf which
+// are kept.
+const HistorySize = 100
+
+const snapshotSuffix = ".conf"
+
+// snapshotTimeFormat is the UTC time which prefixes the revision in the
+// name of a snapshot, so that a revision which recurs is kept once for
+// each time it was replaced.
+const snapshotTimeFormat = "20060102T150405.000000000Z"
+
+// Snapshot is a previous version of nodes.conf. Rev is the hash of
+// its content, and Time is when it was written.
+type Snapshot struct {
+	Rev  string
+	Time time.Time
+	file string
+}
+
+// Revision returns the revision of the node configuration document
+// data, as used to name its snapshot.
+func Revision(data []byte) string {
+	if registry, err := Parse(data); err == nil {
+		return registry.StringHash()
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Current returns the current node configuration document, as it
+// would be kept in the history, and when it was last changed.
+func Current() (data []byte, modTime time.Time, err error) {
+	store, err := OpenStore()
+	if err != nil {
+		return nil, modTime, err
+	}
+	registry, err := store.Load()
+	if err != nil {
+		return nil, modTime, err
+	}
+	if data, err = registry.Dump(); err != nil {
+		return nil, modTime, err
+	}
+	modTime, err = store.ModTime()
+	return data, modTime, err
+}
+
+// Read returns the content of the snapshot.
+func (snap Snapshot) Read() ([]byte, error) {
+	return os.ReadFile(snap.file)
+}
+
+// snapshot adds data, written at modTime, to the history and removes
+// the oldest snapshots beyond HistorySize.
+func snapshot(data []byte, modTime time.Time) error {
+	historyDir := warewulfconf.Get().Paths.NodesConfHistoryDir()
+	if err := os.MkdirAll(historyDir, 0700); err != nil {
+		return err
+	}
+	// writes within one tick of the file system clock would otherwise
+	// share a time and lose their order
+	if snapshots, err := History(); err != nil {
+		return err
+	} else if len(snapshots) > 0 && !modTime.After(snapshots[0].Time) {
+		modTime = snapshots[0].Time.Add(time.Microsecond)
+	}
+	fileName := path.Join(historyDir, modTime.UTC().Format(snapshotTimeFormat)+"-"+Revision(data)+snapshotSuffix)
+	tmpFile := fileName + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpFile, fileName); err != nil {
+		return err
+	}
+	wwlog.Debug("snapshot of node configuration: %s", fileName)
+	snapshots, err := History()
+	if err != nil {
+		return err
+	}
+	for i := HistorySize; i < len(snapshots); i++ {
+		if err := os.Remove(snapshots[i].file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// History returns the snapshots of previous versions of nodes.conf,
+// newest first.
+func History() (snapshots []Snapshot, err error) {
+	historyDir := warewulfconf.Get().Paths.NodesConfHistoryDir()
+	entries, err := os.ReadDir(historyDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), snapshotSuffix) {
+			continue
+		}
+		timeStr, rev, ok := strings.Cut(strings.TrimSuffix(entry.Name(), snapshotSuffix), "-")
+		if !ok {
+			continue
+		}
+		modTime, err := time.ParseInLocation(snapshotTimeFormat, timeStr, time.UTC)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{
+			Rev:  rev,
+			Time: modTime.Local(),
+			file: path.Join(historyDir, entry.Name()),
+		})
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if snapshots[i].Time.Equal(snapshots[j].Time) {
+			return snapshots[i].Rev < snapshots[j].Rev
+		}
+		return snapshots[i].Time.After(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// FindSnapshot returns the newest snapshot whose revision starts with
+// rev. Rev is ambiguous if it is the start of several revisions.
+func FindSnapshot(rev string) (snap Snapshot, err error) {
+	if rev == "" {
+		return snap, fmt.Errorf("revision must not be empty")
+	}
+	snapshots, err := History()
+	if err != nil {
+		return snap, err
+	}
+	var found []Snapshot
+	revs := make(map[string]bool)
+	for _, s := range snapshots {
+		if strings.HasPrefix(s.Rev, rev) && !revs[s.Rev] {
+			revs[s.Rev] = true
+			found = append(found, s)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return snap, fmt.Errorf("revision not found: %s", rev)
+	case 1:
+		return found[0], nil
+	default:
+		return snap, fmt.Errorf("revision is ambiguous: %s", rev)
+	}
+}
+
+// Rollback restores the snapshot whose revision starts with rev as
+// nodes.conf. The replaced version is added to the history.
+func Rollback(rev string) error {
+	snap, err := FindSnapshot(rev)
+	if err != nil {
+		return err
+	}
+	data, err := snap.Read()
+	if err != nil {
+		return err
+	}
+	registry, err := Parse(data)
+	if err != nil {
+		return fmt.Errorf("could not parse revision %s: %w", snap.Rev, err)
+	}
+	return registry.Persist()
+}
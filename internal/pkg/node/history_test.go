@@ -0,0 +1,108 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_History(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles: {}
+nodes:
+  n1: {}`)
+
+	snapshots, err := History()
+	assert.NoError(t, err)
+	assert.Empty(t, snapshots)
+
+	registry, err := New()
+	assert.NoError(t, err)
+	original := registry.StringHash()
+	// persisting without changes doesn't add to the history
+	assert.NoError(t, registry.Persist())
+	snapshots, err = History()
+	assert.NoError(t, err)
+	assert.Empty(t, snapshots)
+
+	registry.Nodes["n1"].Comment = "first"
+	assert.NoError(t, registry.Persist())
+	first := registry.StringHash()
+	registry.Nodes["n1"].Comment = "second"
+	assert.NoError(t, registry.Persist())
+
+	snapshots, err = History()
+	assert.NoError(t, err)
+	if assert.Len(t, snapshots, 2) {
+		assert.Equal(t, first, snapshots[0].Rev)
+		assert.Equal(t, original, snapshots[1].Rev)
+	}
+
+	_, err = FindSnapshot("")
+	assert.Error(t, err)
+	_, err = FindSnapshot("none")
+	assert.Error(t, err)
+	snap, err := FindSnapshot(original[:8])
+	assert.NoError(t, err)
+	assert.Equal(t, original, snap.Rev)
+
+	assert.NoError(t, Rollback(original[:8]))
+	registry, err = New()
+	assert.NoError(t, err)
+	assert.Equal(t, original, registry.StringHash())
+	assert.Equal(t, "", registry.Nodes["n1"].Comment)
+
+	snapshots, err = History()
+	assert.NoError(t, err)
+	assert.Len(t, snapshots, 3, "the rolled back version is kept")
+}
+
+func Test_HistoryRecurringRevision(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles: {}
+nodes:
+  n1: {}`)
+
+	registry, err := New()
+	assert.NoError(t, err)
+	original := registry.StringHash()
+	revs := map[string]string{}
+	for _, comment := range []string{"a", "b", "a", "c"} {
+		registry.Nodes["n1"].Comment = comment
+		assert.NoError(t, registry.Persist())
+		revs[comment] = registry.StringHash()
+	}
+
+	snapshots, err := History()
+	assert.NoError(t, err)
+	if assert.Len(t, snapshots, 4) {
+		assert.Equal(t, revs["a"], snapshots[0].Rev)
+		assert.Equal(t, revs["b"], snapshots[1].Rev)
+		assert.Equal(t, revs["a"], snapshots[2].Rev)
+		assert.Equal(t, original, snapshots[3].Rev)
+		for i := 1; i < len(snapshots); i++ {
+			assert.True(t, snapshots[i-1].Time.After(snapshots[i].Time))
+		}
+	}
+
+	snap, err := FindSnapshot(revs["a"][:8])
+	assert.NoError(t, err)
+	assert.Equal(t, snapshots[0], snap)
+
+	assert.NoError(t, Rollback(revs["b"]))
+	registry, err = New()
+	assert.NoError(t, err)
+	assert.Equal(t, "b", registry.Nodes["n1"].Comment)
+
+	snapshots, err = History()
+	assert.NoError(t, err)
+	if assert.Len(t, snapshots, 5) {
+		assert.Equal(t, revs["c"], snapshots[0].Rev)
+		assert.Equal(t, revs["a"], snapshots[1].Rev)
+	}
+}
@@ -0,0 +1,191 @@
+package node
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mohae/deepcopy"
+)
+
+// FieldChange is a field whose value differs between two configurations of a node.
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// DiffFields returns the fields whose values differ between old and new, as returned by
+// GetFieldList: first the fields of new, then those which are only set in old.
+func DiffFields(old, new interface{}) (changes []FieldChange) {
+	oldFields, newFields := GetFieldList(old), GetFieldList(new)
+	oldValues, newValues := make(map[string]string), make(map[string]string)
+	for _, field := range oldFields {
+		oldValues[field.Field] = field.Value
+	}
+	for _, field := range newFields {
+		newValues[field.Field] = field.Value
+	}
+	names := make([]string, 0, len(newFields))
+	for _, field := range newFields {
+		names = append(names, field.Field)
+	}
+	for _, field := range oldFields {
+		if _, ok := newValues[field.Field]; !ok {
+			names = append(names, field.Field)
+		}
+	}
+	for _, name := range names {
+		if oldValues[name] != newValues[name] {
+			changes = append(changes, FieldChange{Field: name, Old: oldValues[name], New: newValues[name]})
+		}
+	}
+	return changes
+}
+
+const (
+	ImportAdd    = "add"
+	ImportUpdate = "update"
+	ImportDelete = "delete"
+)
+
+// ImportChange describes how an import changes a node of the node database: Action is
+// ImportAdd, ImportUpdate, or ImportDelete, and Fields are the changed fields of added and
+// updated nodes.
+type ImportChange struct {
+	Node   string
+	Action string
+	Fields []FieldChange
+}
+
+/*
+Import adds the given nodes which don't exist yet and replaces the configuration of
+existing nodes which differ. With prune, nodes which aren't given are deleted. The changes
+are returned ordered by node, unchanged nodes are omitted, and nothing is persisted.
+*/
+func (config *NodesYaml) Import(nodes map[string]*Node, prune bool) (changes []ImportChange) {
+	var ids []string
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	if prune {
+		for id := range config.Nodes {
+			if _, ok := nodes[id]; !ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		imported, ok := nodes[id]
+		if !ok {
+			delete(config.Nodes, id)
+			changes = append(changes, ImportChange{Node: id, Action: ImportDelete})
+			continue
+		}
+		if imported == nil {
+			imported = new(Node)
+		}
+		n := *imported
+		n.id = id
+		if existing, ok := config.Nodes[id]; ok {
+			fields := DiffFields(*existing, n)
+			if len(fields) == 0 {
+				continue
+			}
+			changes = append(changes, ImportChange{Node: id, Action: ImportUpdate, Fields: fields})
+		} else {
+			changes = append(changes, ImportChange{Node: id, Action: ImportAdd, Fields: DiffFields(Node{}, n)})
+		}
+		config.Nodes[id] = &n
+	}
+	return changes
+}
+
+/*
+ReadNodesCSV reads nodes from CSV data. The first column of the header is the node name
+("node" or "nodename"), and the other columns are field names as returned by GetFieldList,
+e.g. ImageName, Kernel.Args, or NetDevs[default].Ipaddr. Each node starts from its existing
+configuration, so that only the given fields are changed; an empty value unsets a field.
+*/
+func (config *NodesYaml) ReadNodesCSV(r io.Reader) (map[string]*Node, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 1 {
+		return nil, fmt.Errorf("no header")
+	}
+	header := records[0]
+	if name := strings.ToLower(header[0]); name != "node" && name != "nodename" {
+		return nil, fmt.Errorf("first column must be the node name (node or nodename), not %s", header[0])
+	}
+	nodes := make(map[string]*Node)
+	for i, record := range records[1:] {
+		id := record[0]
+		if id == "" {
+			return nil, fmt.Errorf("line %d: missing node name", i+2)
+		}
+		n, ok := nodes[id]
+		if !ok {
+			n = new(Node)
+			if existing, ok := config.Nodes[id]; ok {
+				*n = deepcopy.Copy(*existing).(Node)
+			}
+			nodes[id] = n
+		}
+		for j, value := range record[1:] {
+			if err := SetField(n, header[j+1], value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+2, err)
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// WriteNodesCSV writes nodes as CSV data which can be read by ReadNodesCSV, with a column for
+// each field which is set on any of the nodes.
+func WriteNodesCSV(w io.Writer, nodes map[string]*Node) error {
+	var ids []string
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	header := []string{"node"}
+	columns := make(map[string]bool)
+	var values []map[string]string
+	for _, id := range ids {
+		nodeValues := make(map[string]string)
+		for _, field := range GetFieldList(*nodes[id]) {
+			if field.Value == "" {
+				continue
+			}
+			nodeValues[field.Field] = field.Value
+			if !columns[field.Field] {
+				columns[field.Field] = true
+				header = append(header, field.Field)
+			}
+		}
+		values = append(values, nodeValues)
+	}
+
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+	for i, id := range ids {
+		record := []string{id}
+		for _, name := range header[1:] {
+			record = append(record, values[i][name])
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
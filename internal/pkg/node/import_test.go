@@ -0,0 +1,128 @@
+package node
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_SetField(t *testing.T) {
+	var n Node
+	assert.NoError(t, SetField(&n, "ImageName", "rocky9"))
+	assert.NoError(t, SetField(&n, "kernel.args", "quiet,console=ttyS0"))
+	assert.NoError(t, SetField(&n, "NetDevs[default].Ipaddr", "10.0.0.1"))
+	assert.NoError(t, SetField(&n, "NetDevs[default].OnBoot", "true"))
+	assert.NoError(t, SetField(&n, "Tags[rack]", "1"))
+	assert.NoError(t, SetField(&n, "Disks[/dev/vda].Partitions[scratch].ShouldExist", "true"))
+	assert.NoError(t, SetField(&n, "Resources[fstab]", `[{"file":"/home"}]`))
+	assert.Equal(t, "rocky9", n.ImageName)
+	assert.Equal(t, []string{"quiet", "console=ttyS0"}, n.Kernel.Args)
+	assert.Equal(t, "10.0.0.1", n.NetDevs["default"].Ipaddr.String())
+	assert.True(t, n.NetDevs["default"].OnBoot.Bool())
+	assert.Equal(t, map[string]string{"rack": "1"}, n.Tags)
+	assert.True(t, n.Disks["/dev/vda"].Partitions["scratch"].ShouldExist)
+	assert.Equal(t, []interface{}{map[string]interface{}{"file": "/home"}}, n.Resources["fstab"])
+
+	assert.NoError(t, SetField(&n, "Tags[rack]", ""))
+	assert.NoError(t, SetField(&n, "ImageName", ""))
+	assert.Empty(t, n.Tags)
+	assert.Empty(t, n.ImageName)
+
+	assert.EqualError(t, SetField(&n, "Color", "red"), "unknown field: Color")
+	assert.EqualError(t, SetField(&n, "ImageName.Tag", "red"), "unknown field: ImageName.Tag")
+	assert.EqualError(t, SetField(&n, "NetDevs[default].Ipaddr", "x"), "invalid value for NetDevs[default].Ipaddr: invalid IP address: x")
+}
+
+func Test_Import(t *testing.T) {
+	registry, err := Parse([]byte(`
+nodes:
+  n1:
+    image name: rocky8
+  n2:
+    image name: rocky9
+  n3: {}`))
+	assert.NoError(t, err)
+
+	nodes := make(map[string]*Node)
+	assert.NoError(t, yaml.Unmarshal([]byte(`
+n1:
+  image name: rocky9
+  tags:
+    rack: "1"
+n2:
+  image name: rocky9
+n4:
+  profiles:
+  - default`), nodes))
+
+	changes := registry.Import(nodes, true)
+	assert.Equal(t, []ImportChange{
+		{Node: "n1", Action: ImportUpdate, Fields: []FieldChange{
+			{Field: "ImageName", Old: "rocky8", New: "rocky9"},
+			{Field: "Tags[rack]", Old: "", New: "1"},
+		}},
+		{Node: "n3", Action: ImportDelete},
+		{Node: "n4", Action: ImportAdd, Fields: []FieldChange{
+			{Field: "Profiles", Old: "", New: "default"},
+		}},
+	}, changes)
+	assert.Equal(t, []string{"n1", "n2", "n4"}, registry.ListAllNodes())
+	assert.Equal(t, "1", registry.Nodes["n1"].Tags["rack"])
+}
+
+func Test_NodesCSV(t *testing.T) {
+	registry, err := Parse([]byte(`
+nodes:
+  n1:
+    profiles:
+    - default
+    image name: rocky8
+    kernel:
+      args:
+      - quiet
+    network devices:
+      default:
+        device: eth0
+        ipaddr: 10.0.0.1
+  n2:
+    tags:
+      rack: "1"`))
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, WriteNodesCSV(buf, registry.Nodes))
+	assert.Equal(t, `node,Profiles,ImageName,Kernel.Args,NetDevs[default].Device,NetDevs[default].Ipaddr,Tags[rack]
+n1,default,rocky8,quiet,eth0,10.0.0.1,
+n2,,,,,,1
+`, buf.String())
+
+	nodes, err := registry.ReadNodesCSV(strings.NewReader(buf.String()))
+	assert.NoError(t, err)
+	assert.Empty(t, registry.Import(nodes, false))
+
+	// only the given fields are changed
+	nodes, err = registry.ReadNodesCSV(strings.NewReader(`nodename,imagename,NetDevs[default].Ipaddr
+n1,rocky9,10.0.0.2
+n3,rocky9,10.0.0.3
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []ImportChange{
+		{Node: "n1", Action: ImportUpdate, Fields: []FieldChange{
+			{Field: "ImageName", Old: "rocky8", New: "rocky9"},
+			{Field: "NetDevs[default].Ipaddr", Old: "10.0.0.1", New: "10.0.0.2"},
+		}},
+		{Node: "n3", Action: ImportAdd, Fields: []FieldChange{
+			{Field: "ImageName", Old: "", New: "rocky9"},
+			{Field: "NetDevs[default].Ipaddr", Old: "", New: "10.0.0.3"},
+		}},
+	}, registry.Import(nodes, false))
+	assert.Equal(t, []string{"quiet"}, registry.Nodes["n1"].Kernel.Args)
+
+	_, err = registry.ReadNodesCSV(strings.NewReader("name,ImageName\nn1,rocky9\n"))
+	assert.EqualError(t, err, "first column must be the node name (node or nodename), not name")
+	_, err = registry.ReadNodesCSV(strings.NewReader("node,Color\nn1,red\n"))
+	assert.EqualError(t, err, "line 2: unknown field: Color")
+}
@@ -0,0 +1,29 @@
+package node
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// lockFile takes an exclusive advisory lock for changes of fileName,
+// waiting for other processes which hold it. The lock is held on a
+// separate file, as fileName itself is replaced when it is written.
+func lockFile(fileName string) (unlock func(), err error) {
+	lock, err := os.OpenFile(fileName+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	wwlog.Debug("waiting for lock: %s", lock.Name())
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	return func() {
+		if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_UN); err != nil {
+			wwlog.Warn("could not unlock %s: %s", lock.Name(), err)
+		}
+		lock.Close()
+	}, nil
+}
@@ -0,0 +1,100 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_PersistConflict(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles: {}
+nodes:
+  n1: {}`)
+
+	first, err := New()
+	assert.NoError(t, err)
+	second, err := New()
+	assert.NoError(t, err)
+
+	first.Nodes["n1"].Comment = "first"
+	assert.NoError(t, first.Persist())
+	second.Nodes["n1"].Comment = "second"
+	assert.ErrorIs(t, second.Persist(), ErrConflict)
+
+	registry, err := New()
+	assert.NoError(t, err)
+	assert.Equal(t, "first", registry.Nodes["n1"].Comment)
+
+	// the written registry may be persisted again
+	first.Nodes["n1"].Comment = "again"
+	assert.NoError(t, first.Persist())
+
+	// registries which weren't read from disk are not checked
+	parsed, err := Parse([]byte("nodes:\n  n2: {}\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, parsed.Persist())
+}
+
+func Test_PersistConcurrent(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles: {}
+nodes: {}`)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for {
+				registry, err := New()
+				if !assert.NoError(t, err) {
+					return
+				}
+				_, err = registry.AddNode(fmt.Sprintf("n%d", i))
+				assert.NoError(t, err)
+				err = registry.Persist()
+				if !errors.Is(err, ErrConflict) {
+					assert.NoError(t, err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	registry, err := New()
+	assert.NoError(t, err)
+	assert.Len(t, registry.Nodes, 10)
+}
+
+func Test_PersistToFile(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `nodes: {}`)
+	nodesConf := env.GetPath("etc/warewulf/nodes.conf")
+	assert.NoError(t, os.Chmod(nodesConf, 0o640))
+
+	registry, err := Parse([]byte("nodes:\n  n1: {}\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, registry.PersistToFile(nodesConf))
+
+	info, err := os.Stat(nodesConf)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+	entries, err := os.ReadDir(path.Dir(nodesConf))
+	assert.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), ".nodes.conf.", "temporary files are removed")
+	}
+}
@@ -157,6 +157,15 @@ func (config *NodesYaml) MergeNode(id string) (node Node, fields fieldMap, err e
 		delete(fields, "Profiles")
 	}
 
+	for name, netdev := range node.NetDevs {
+		if netdev != nil && netdev.Network != "" {
+			// the merged network device may still be shared with the node
+			netdev := *netdev
+			config.applyNetwork(name, &netdev, fields)
+			node.NetDevs[name] = &netdev
+		}
+	}
+
 	node.setIds(id)
 	node.valid = true
 	node.updatePrimaryNetDev()
@@ -111,6 +111,9 @@ func EmptyProfile() (profileconf Profile) {
 }
 
 func (nodeconf *Node) Expand() {
+	if nodeconf.Discover == nil {
+		nodeconf.Discover = new(DiscoverConf)
+	}
 	nodeconf.Profile.Expand()
 }
 
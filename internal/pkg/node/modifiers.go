@@ -3,9 +3,13 @@ package node
 import (
 	"bytes"
 	"encoding/gob"
+	"fmt"
 	"os"
+	"path"
+	"sort"
 
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
 
 	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
 	"github.com/warewulf/warewulf/internal/pkg/util"
@@ -110,9 +114,128 @@ func (config *NodesYaml) DelProfile(profileID string) error {
 Write the the NodeYaml to disk.
 */
 func (config *NodesYaml) Persist() error {
-	return config.PersistToFile(warewulfconf.Get().Paths.NodesConf())
+	return config.PersistAs(defaultAuditActor())
 }
 
+/*
+Write the NodeYaml to the configured storage backend and record the
+changes made by actor in the audit log. Only changed nodes and profiles
+are written. The previous version is kept in the history.
+
+If the NodeYaml was read with New, it is only written if the stored
+nodes and profiles weren't changed since; otherwise ErrConflict is
+returned.
+
+Changed nodes are validated first, and their problems are logged. With
+strict validation, the NodeYaml is not written if there are problems,
+and a ValidationError is returned.
+*/
+func (config *NodesYaml) PersistAs(actor AuditActor) error {
+	store, err := OpenStore()
+	if err != nil {
+		return err
+	}
+	unlock, err := lockFile(store.File())
+	if err != nil {
+		return fmt.Errorf("could not lock %s: %w", store.File(), err)
+	}
+	defer unlock()
+
+	current, err := store.Load()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var before []byte
+	if err == nil {
+		if before, err = current.Dump(); err != nil {
+			return err
+		}
+		beforeRev := Revision(before)
+		if config.rev != "" && config.rev != beforeRev {
+			return ErrConflict
+		}
+		if beforeRev != config.StringHash() {
+			modTime, err := store.ModTime()
+			if err != nil {
+				return err
+			}
+			if err := snapshot(before, modTime); err != nil {
+				return fmt.Errorf("could not add %s to history: %w", store.File(), err)
+			}
+		}
+	}
+	after, err := config.Dump()
+	if err != nil {
+		return err
+	}
+	problems, err := config.validateChanges(current)
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		wwlog.Warn("%s", problem)
+	}
+	if len(problems) > 0 && StrictValidation() {
+		return &ValidationError{Problems: problems}
+	}
+	entries, err := AuditDiff(before, after, actor)
+	if err != nil {
+		return err
+	}
+	if err := config.write(store, current); err != nil {
+		return err
+	}
+	wwlog.Debug("persisted: %s", store.File())
+	config.rev = Revision(after)
+	if err := WriteAuditLog(entries); err != nil {
+		wwlog.Error("could not write audit log: %s", err)
+	}
+	return nil
+}
+
+// write puts the nodes, profiles, and networks which differ from those
+// in current into store, and deletes those which are only in current,
+// in a single write. The store is written even without changes, so
+// that a YAML document is always rewritten in its normalized form.
+func (config *NodesYaml) write(store Store, current NodesYaml) error {
+	var changes Changes
+	changes.Nodes, changes.DeletedNodes = changedEntities(current.Nodes, config.Nodes)
+	changes.Profiles, changes.DeletedProfiles = changedEntities(current.NodeProfiles, config.NodeProfiles)
+	changes.Networks, changes.DeletedNetworks = changedEntities(current.Networks, config.Networks)
+	return store.Apply(changes)
+}
+
+// changedEntities returns the entities of after which are not in before
+// or differ in their stored form, and the ids of those which are only
+// in before.
+func changedEntities[T any](before, after map[string]*T) (changed map[string]*T, deleted []string) {
+	changed = make(map[string]*T)
+	for id, entity := range after {
+		if old, ok := before[id]; !ok || !sameEntity(old, entity) {
+			changed[id] = entity
+		}
+	}
+	for id := range before {
+		if _, ok := after[id]; !ok {
+			deleted = append(deleted, id)
+		}
+	}
+	sort.Strings(deleted)
+	return changed, deleted
+}
+
+// sameEntity reports whether two nodes, profiles, or networks are
+// stored the same.
+func sameEntity(a, b interface{}) bool {
+	aData, aErr := yaml.Marshal(a)
+	bData, bErr := yaml.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
+
+/*
+Write the NodeYaml to configFile. The file is replaced atomically, so
+that readers see either the previous or the new content.
+*/
 func (config *NodesYaml) PersistToFile(configFile string) error {
 	if configFile == "" {
 		configFile = warewulfconf.Get().Paths.NodesConf()
@@ -122,20 +245,40 @@ func (config *NodesYaml) PersistToFile(configFile string) error {
 		wwlog.Error("%s", dumpErr)
 		return dumpErr
 	}
-	file, err := os.OpenFile(configFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	var mode os.FileMode = 0o644
+	if info, err := os.Stat(configFile); err == nil {
+		mode = info.Mode().Perm()
+	}
+	file, err := os.CreateTemp(path.Dir(configFile), "."+path.Base(configFile)+".*")
 	if err != nil {
 		wwlog.Error("%s", err)
 		return err
 	}
-	defer file.Close()
-	_, err = file.WriteString(string(out))
-	if err != nil {
+	defer os.Remove(file.Name())
+	if err := writeAndClose(file, out, mode); err != nil {
+		return err
+	}
+	if err := os.Rename(file.Name(), configFile); err != nil {
 		return err
 	}
 	wwlog.Debug("persisted: %s", configFile)
 	return nil
 }
 
+func writeAndClose(file *os.File, data []byte, mode os.FileMode) error {
+	_, err := file.Write(data)
+	if err == nil {
+		err = file.Chmod(mode)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
 // Dump returns a YAML document representing the nodeDb instance. Passes through any errors
 // generated by yaml encoding.
 func (config *NodesYaml) Dump() ([]byte, error) {
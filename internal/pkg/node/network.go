@@ -0,0 +1,347 @@
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+/*
+Network is an IPv4 network from which the addresses of network devices
+which reference it are allocated. Reserved lists addresses and ranges
+(FIRST-LAST) which are never allocated.
+*/
+type Network struct {
+	CIDR     string   `yaml:"cidr"               json:"cidr"`
+	Gateway  net.IP   `yaml:"gateway,omitempty"  json:"gateway,omitempty"`
+	Reserved []string `yaml:"reserved,omitempty" json:"reserved,omitempty"`
+	MTU      string   `yaml:"mtu,omitempty"      json:"mtu,omitempty"`
+	Comment  string   `yaml:"comment,omitempty"  json:"comment,omitempty"`
+}
+
+// NetworkAddress is an address of a network which is used by a
+// network device of a node.
+type NetworkAddress struct {
+	Ipaddr net.IP `json:"ipaddr"`
+	Node   string `json:"node"`
+	NetDev string `json:"netdev"`
+}
+
+// IPNet returns the parsed CIDR of the network.
+func (network *Network) IPNet() (*net.IPNet, error) {
+	_, ipNet, err := net.ParseCIDR(network.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("invalid network %q: %w", network.CIDR, err)
+	}
+	if ipNet.IP.To4() == nil {
+		return nil, fmt.Errorf("only IPv4 networks are supported: %s", network.CIDR)
+	}
+	return ipNet, nil
+}
+
+// Netmask returns the netmask of the network.
+func (network *Network) Netmask() net.IP {
+	ipNet, err := network.IPNet()
+	if err != nil {
+		return nil
+	}
+	return net.IP(ipNet.Mask).To4()
+}
+
+// reservedRanges parses the reserved addresses and ranges.
+func (network *Network) reservedRanges() (ranges [][2]net.IP, err error) {
+	for _, reserved := range network.Reserved {
+		first, last, found := strings.Cut(reserved, "-")
+		firstIP := net.ParseIP(strings.TrimSpace(first)).To4()
+		lastIP := firstIP
+		if found {
+			lastIP = net.ParseIP(strings.TrimSpace(last)).To4()
+		}
+		if firstIP == nil || lastIP == nil || bytes.Compare(firstIP, lastIP) > 0 {
+			return nil, fmt.Errorf("invalid reserved range: %s", reserved)
+		}
+		ranges = append(ranges, [2]net.IP{firstIP, lastIP})
+	}
+	return ranges, nil
+}
+
+// excluded returns the ranges of addresses of the network which are
+// never allocated, as sorted and merged ranges of integers: the network
+// and broadcast addresses, the gateway, and the reserved addresses.
+func (network *Network) excluded() (first uint32, last uint32, excluded [][2]uint32, err error) {
+	ipNet, err := network.IPNet()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	first = ipToUint(ipNet.IP)
+	last = first | ^ipToUint(net.IP(ipNet.Mask))
+	excluded = [][2]uint32{{first, first}, {last, last}}
+	if gateway := network.Gateway.To4(); gateway != nil {
+		excluded = append(excluded, [2]uint32{ipToUint(gateway), ipToUint(gateway)})
+	}
+	ranges, err := network.reservedRanges()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	for _, r := range ranges {
+		excluded = append(excluded, [2]uint32{ipToUint(r[0]), ipToUint(r[1])})
+	}
+	sort.Slice(excluded, func(i, j int) bool { return excluded[i][0] < excluded[j][0] })
+	merged := excluded[:1]
+	for _, r := range excluded[1:] {
+		if prev := &merged[len(merged)-1]; uint64(r[0]) <= uint64(prev[1])+1 {
+			prev[1] = max(prev[1], r[1])
+		} else {
+			merged = append(merged, r)
+		}
+	}
+	return first, last, merged, nil
+}
+
+// IsReserved reports whether ip is the network or broadcast address,
+// the gateway, or a reserved address of the network.
+func (network *Network) IsReserved(ip net.IP) bool {
+	if ip.To4() == nil {
+		return false
+	}
+	_, _, excluded, err := network.excluded()
+	if err != nil {
+		return false
+	}
+	i := ipToUint(ip)
+	for _, r := range excluded {
+		if i >= r[0] && i <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// Size returns the number of addresses of the network which can be
+// allocated.
+func (network *Network) Size() int {
+	first, last, excluded, err := network.excluded()
+	if err != nil {
+		return 0
+	}
+	size := int64(last) - int64(first) + 1
+	for _, r := range excluded {
+		lo, hi := max(r[0], first), min(r[1], last)
+		if lo <= hi {
+			size -= int64(hi) - int64(lo) + 1
+		}
+	}
+	return int(max(size, 0))
+}
+
+func ipToUint(ip net.IP) uint32 {
+	ip = ip.To4()
+	return uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3])
+}
+
+func uintToIP(i uint32) net.IP {
+	return net.IPv4(byte(i>>24), byte(i>>16), byte(i>>8), byte(i)).To4()
+}
+
+/*
+Add a network with the given ID and return a pointer to it
+*/
+func (config *NodesYaml) AddNetwork(networkID string, network Network) (*Network, error) {
+	if _, ok := config.Networks[networkID]; ok {
+		return nil, fmt.Errorf("network already exists: %s", networkID)
+	}
+	if _, err := network.IPNet(); err != nil {
+		return nil, err
+	}
+	if _, err := network.reservedRanges(); err != nil {
+		return nil, err
+	}
+	if config.Networks == nil {
+		config.Networks = make(map[string]*Network)
+	}
+	config.Networks[networkID] = &network
+	return &network, nil
+}
+
+/*
+delete network with the given id
+*/
+func (config *NodesYaml) DelNetwork(networkID string) error {
+	if _, ok := config.Networks[networkID]; !ok {
+		return fmt.Errorf("network does not exist: %s", networkID)
+	}
+	delete(config.Networks, networkID)
+	return nil
+}
+
+/*
+Get the network with id, return ErrNotFound otherwise
+*/
+func (config *NodesYaml) GetNetwork(id string) (*Network, error) {
+	if network, ok := config.Networks[id]; ok {
+		return network, nil
+	}
+	return nil, ErrNotFound
+}
+
+/*
+NetworkAddresses returns the addresses of the network id which are used
+by network devices of the merged nodes, ordered by address.
+*/
+func (config *NodesYaml) NetworkAddresses(id string) (addresses []NetworkAddress, err error) {
+	network, err := config.GetNetwork(id)
+	if err != nil {
+		return nil, err
+	}
+	ipNet, err := network.IPNet()
+	if err != nil {
+		return nil, err
+	}
+	used, err := config.usedAddresses()
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range used {
+		if ipNet.Contains(address.Ipaddr) {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses, nil
+}
+
+// usedAddresses returns the IPv4 addresses of the network devices of
+// all merged nodes, ordered by address.
+func (config *NodesYaml) usedAddresses() (addresses []NetworkAddress, err error) {
+	nodes, err := config.FindAllNodes()
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range nodes {
+		for name, netdev := range n.NetDevs {
+			if ip := netdev.Ipaddr.To4(); ip != nil && !ip.IsUnspecified() {
+				addresses = append(addresses, NetworkAddress{Ipaddr: ip, Node: n.Id(), NetDev: name})
+			}
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		if c := bytes.Compare(addresses[i].Ipaddr, addresses[j].Ipaddr); c != 0 {
+			return c < 0
+		}
+		if addresses[i].Node != addresses[j].Node {
+			return addresses[i].Node < addresses[j].Node
+		}
+		return addresses[i].NetDev < addresses[j].NetDev
+	})
+	return addresses, nil
+}
+
+/*
+AllocateIPs sets the address of each network device of the given nodes
+which references a network but has no address to the next address of
+that network which isn't reserved or used by another network device.
+The address is set on the node, even if the network device is defined
+by one of its profiles.
+*/
+func (config *NodesYaml) AllocateIPs(nodes ...string) error {
+	usedAddresses, err := config.usedAddresses()
+	if err != nil {
+		return err
+	}
+	used := make(map[string]bool)
+	for _, address := range usedAddresses {
+		used[address.Ipaddr.String()] = true
+	}
+	for _, id := range nodes {
+		merged, err := config.GetNode(id)
+		if err != nil {
+			return err
+		}
+		var names []string
+		for name := range merged.NetDevs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			netdev := merged.NetDevs[name]
+			if netdev.Network == "" || (netdev.Ipaddr != nil && !netdev.Ipaddr.IsUnspecified()) {
+				continue
+			}
+			network, err := config.GetNetwork(netdev.Network)
+			if err != nil {
+				return fmt.Errorf("network of %s/%s does not exist: %s", id, name, netdev.Network)
+			}
+			ip, err := network.nextFree(used)
+			if err != nil {
+				return fmt.Errorf("could not allocate address for %s/%s: %w", id, name, err)
+			}
+			used[ip.String()] = true
+			n := config.Nodes[id]
+			if n.NetDevs == nil {
+				n.NetDevs = make(map[string]*NetDev)
+			}
+			if n.NetDevs[name] == nil {
+				n.NetDevs[name] = new(NetDev)
+			}
+			n.NetDevs[name].Ipaddr = ip
+			wwlog.Info("Allocated address %s from network %s for %s/%s", ip, netdev.Network, id, name)
+		}
+	}
+	return nil
+}
+
+// nextFree returns the first address of the network which is neither
+// reserved nor used.
+func (network *Network) nextFree(used map[string]bool) (net.IP, error) {
+	first, last, excluded, err := network.excluded()
+	if err != nil {
+		return nil, err
+	}
+	next := uint64(first)
+	for _, r := range append(excluded, [2]uint32{last, last}) {
+		for ; next < uint64(r[0]) && next <= uint64(last); next++ {
+			if ip := uintToIP(uint32(next)); !used[ip.String()] {
+				return ip, nil
+			}
+		}
+		next = max(next, uint64(r[1])+1)
+	}
+	return nil, fmt.Errorf("no free address in network %s", network.CIDR)
+}
+
+// applyNetwork sets the netmask, gateway, and MTU of netdev from its
+// network, unless they are set already.
+func (config *NodesYaml) applyNetwork(netdevName string, netdev *NetDev, fields fieldMap) {
+	network, ok := config.Networks[netdev.Network]
+	if !ok {
+		return
+	}
+	source := "network:" + netdev.Network
+	prefix := "NetDevs[" + netdevName + "]."
+	if (netdev.Netmask == nil || netdev.Netmask.IsUnspecified()) && network.Netmask() != nil {
+		netdev.Netmask = network.Netmask()
+		fields.Set(prefix+"Netmask", source, netdev.Netmask.String())
+	}
+	if (netdev.Gateway == nil || netdev.Gateway.IsUnspecified()) && network.Gateway != nil {
+		netdev.Gateway = network.Gateway
+		fields.Set(prefix+"Gateway", source, netdev.Gateway.String())
+	}
+	if netdev.MTU == "" && network.MTU != "" {
+		netdev.MTU = network.MTU
+		fields.Set(prefix+"MTU", source, netdev.MTU)
+	}
+}
+
+// networkNames returns the networks referenced by the network devices of
+// the profile.
+func (profile *Profile) networkNames() (names []string) {
+	for _, netdev := range profile.NetDevs {
+		if netdev != nil && netdev.Network != "" {
+			names = append(names, netdev.Network)
+		}
+	}
+	return names
+}
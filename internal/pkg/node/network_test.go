@@ -0,0 +1,139 @@
+package node
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NetworkSize(t *testing.T) {
+	var tests = map[string]struct {
+		network  Network
+		size     int
+		reserved []string
+		free     []string
+	}{
+		"plain": {
+			network:  Network{CIDR: "10.0.0.0/24"},
+			size:     254,
+			reserved: []string{"10.0.0.0", "10.0.0.255"},
+			free:     []string{"10.0.0.1", "10.0.0.254"},
+		},
+		"gateway and reserved": {
+			network: Network{
+				CIDR:     "10.0.0.0/24",
+				Gateway:  net.ParseIP("10.0.0.1"),
+				Reserved: []string{"10.0.0.2-10.0.0.9", "10.0.0.5", "10.0.0.200"},
+			},
+			size:     244,
+			reserved: []string{"10.0.0.1", "10.0.0.2", "10.0.0.9", "10.0.0.200"},
+			free:     []string{"10.0.0.10", "10.0.0.199"},
+		},
+		"reserved outside of the network": {
+			network: Network{
+				CIDR:     "10.0.0.0/30",
+				Reserved: []string{"10.0.0.2-10.0.1.0"},
+			},
+			size:     1,
+			reserved: []string{"10.0.0.2", "10.0.0.3"},
+			free:     []string{"10.0.0.1"},
+		},
+		"invalid": {
+			network: Network{CIDR: "10.0.0.0"},
+			size:    0,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.size, tt.network.Size())
+			for _, ip := range tt.reserved {
+				assert.True(t, tt.network.IsReserved(net.ParseIP(ip)), ip)
+			}
+			for _, ip := range tt.free {
+				assert.False(t, tt.network.IsReserved(net.ParseIP(ip)), ip)
+			}
+		})
+	}
+}
+
+func Test_AddNetwork(t *testing.T) {
+	registry, err := Parse(nil)
+	assert.NoError(t, err)
+	_, err = registry.AddNetwork("cluster", Network{CIDR: "10.0.0.0/24"})
+	assert.NoError(t, err)
+	_, err = registry.AddNetwork("cluster", Network{CIDR: "10.0.1.0/24"})
+	assert.EqualError(t, err, "network already exists: cluster")
+	_, err = registry.AddNetwork("v6", Network{CIDR: "fd00::/64"})
+	assert.EqualError(t, err, "only IPv4 networks are supported: fd00::/64")
+	_, err = registry.AddNetwork("reserved", Network{CIDR: "10.0.1.0/24", Reserved: []string{"10.0.1.9-10.0.1.2"}})
+	assert.EqualError(t, err, "invalid reserved range: 10.0.1.9-10.0.1.2")
+	assert.NoError(t, registry.DelNetwork("cluster"))
+	assert.EqualError(t, registry.DelNetwork("cluster"), "network does not exist: cluster")
+}
+
+func Test_AllocateIPs(t *testing.T) {
+	registry, err := Parse([]byte(`
+networks:
+  cluster:
+    cidr: 10.0.0.0/29
+    gateway: 10.0.0.1
+    mtu: "9000"
+    reserved:
+    - 10.0.0.2
+nodeprofiles:
+  default:
+    network devices:
+      default:
+        device: eth0
+        network: cluster
+nodes:
+  n1:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 10.0.0.4
+  n2:
+    profiles:
+    - default
+  n3:
+    profiles:
+    - default
+  n4:
+    profiles:
+    - default
+  n5:
+    profiles:
+    - default`))
+	assert.NoError(t, err)
+
+	assert.NoError(t, registry.AllocateIPs("n2", "n3"))
+	assert.Equal(t, "10.0.0.3", registry.Nodes["n2"].NetDevs["default"].Ipaddr.String())
+	assert.Equal(t, "10.0.0.5", registry.Nodes["n3"].NetDevs["default"].Ipaddr.String())
+
+	// addresses which are set already are kept
+	assert.NoError(t, registry.AllocateIPs("n1"))
+	assert.Equal(t, "10.0.0.4", registry.Nodes["n1"].NetDevs["default"].Ipaddr.String())
+
+	assert.NoError(t, registry.AllocateIPs("n4"))
+	assert.Equal(t, "10.0.0.6", registry.Nodes["n4"].NetDevs["default"].Ipaddr.String())
+	assert.EqualError(t, registry.AllocateIPs("n5"), "could not allocate address for n5/default: no free address in network 10.0.0.0/29")
+
+	n2, fields, err := registry.MergeNode("n2")
+	assert.NoError(t, err)
+	assert.Equal(t, "255.255.255.248", n2.NetDevs["default"].Netmask.String())
+	assert.Equal(t, "10.0.0.1", n2.NetDevs["default"].Gateway.String())
+	assert.Equal(t, "9000", n2.NetDevs["default"].MTU)
+	assert.Equal(t, "network:cluster", fields.Source("NetDevs[default].Gateway"))
+
+	addresses, err := registry.NetworkAddresses("cluster")
+	assert.NoError(t, err)
+	assert.Equal(t, []NetworkAddress{
+		{net.ParseIP("10.0.0.3").To4(), "n2", "default"},
+		{net.ParseIP("10.0.0.4").To4(), "n1", "default"},
+		{net.ParseIP("10.0.0.5").To4(), "n3", "default"},
+		{net.ParseIP("10.0.0.6").To4(), "n4", "default"},
+	}, addresses)
+}
@@ -0,0 +1,464 @@
+package node
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/warewulf/warewulf/internal/pkg/hostlist"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+)
+
+/*
+Selector is a parsed selector expression, which selects nodes by their
+fields and state. An expression is made of terms, which are combined
+with "&&" (or "and", or ","), "||" (or "or"), "!" (or "not"), and
+parentheses. "&&" binds more tightly than "||".
+
+A term compares a key with a value:
+
+	name=n[01-10]        node name, as a hostlist pattern
+	cluster=hpc2         cluster name
+	image=rocky*         image name
+	profile=gpu          profile, including nested profiles
+	network=cluster      network referenced by a network device
+	tag.gpu=a100         tag value; "tag.gpu" alone selects nodes with the tag
+	stage=RUNTIME*       provisioning stage reported to warewulfd
+	lastseen>10m         time since the node was last seen (s, m, h, d)
+
+Values are matched as shell patterns (*, ?, [...]) and may be quoted.
+"=" and "==" select matching values, "!=" values which don't match.
+lastseen is compared with <, <=, >, and >=; nodes which were never seen
+are treated as last seen infinitely long ago.
+*/
+type Selector struct {
+	expression string
+	expr       selectorExpr
+}
+
+// NodeState is the state of a node, as reported to warewulfd, which is
+// used by the stage and lastseen keys of a selector.
+type NodeState struct {
+	Stage    string
+	LastSeen time.Time
+}
+
+// StateFunc returns the state of the node id, if it is known.
+type StateFunc func(id string) (NodeState, bool)
+
+const (
+	selectorName     = "name"
+	selectorCluster  = "cluster"
+	selectorImage    = "image"
+	selectorProfile  = "profile"
+	selectorNetwork  = "network"
+	selectorStage    = "stage"
+	selectorLastSeen = "lastseen"
+	selectorTag      = "tag."
+)
+
+// ParseSelector parses a selector expression.
+func ParseSelector(expression string) (*Selector, error) {
+	tokens, err := tokenizeSelector(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %w", expression, err)
+	}
+	parser := selectorParser{tokens: tokens}
+	expr, err := parser.parseOr()
+	if err == nil && parser.pos < len(parser.tokens) {
+		err = fmt.Errorf("unexpected %q", parser.tokens[parser.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %w", expression, err)
+	}
+	return &Selector{expression: expression, expr: expr}, nil
+}
+
+func (selector *Selector) String() string {
+	return selector.expression
+}
+
+// NeedsState reports whether the selector uses the state of nodes, so
+// that callers only have to get the state from warewulfd if needed.
+func (selector *Selector) NeedsState() bool {
+	return selector.expr.needsState()
+}
+
+/*
+SelectNodes returns the nodes of set which match selector, sorted by
+name. state may be nil, if the selector doesn't need the state of
+nodes.
+*/
+func (config *NodesYaml) SelectNodes(set []Node, selector *Selector, state StateFunc) []Node {
+	var ret []Node
+	for i := range set {
+		target := selectorTarget{node: &set[i]}
+		for _, profileID := range config.getNodeProfiles(set[i].Id()) {
+			if !strings.HasPrefix(profileID, "~") {
+				target.profiles = append(target.profiles, profileID)
+			}
+		}
+		if state != nil {
+			target.state, target.seen = state(set[i].Id())
+		}
+		if selector.expr.match(&target) {
+			ret = append(ret, set[i])
+		}
+	}
+	sort.Sort(nodeList(ret))
+	return ret
+}
+
+// selectorTarget is a node which is matched against a selector.
+type selectorTarget struct {
+	node     *Node
+	profiles []string
+	state    NodeState
+	seen     bool
+}
+
+type selectorExpr interface {
+	match(target *selectorTarget) bool
+	needsState() bool
+}
+
+type selectorAnd []selectorExpr
+
+func (exprs selectorAnd) match(target *selectorTarget) bool {
+	for _, expr := range exprs {
+		if !expr.match(target) {
+			return false
+		}
+	}
+	return true
+}
+
+func (exprs selectorAnd) needsState() bool {
+	for _, expr := range exprs {
+		if expr.needsState() {
+			return true
+		}
+	}
+	return false
+}
+
+type selectorOr []selectorExpr
+
+func (exprs selectorOr) match(target *selectorTarget) bool {
+	for _, expr := range exprs {
+		if expr.match(target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (exprs selectorOr) needsState() bool {
+	return selectorAnd(exprs).needsState()
+}
+
+type selectorNot struct {
+	expr selectorExpr
+}
+
+func (not selectorNot) match(target *selectorTarget) bool {
+	return !not.expr.match(target)
+}
+
+func (not selectorNot) needsState() bool {
+	return not.expr.needsState()
+}
+
+// selectorTerm compares a key with a value. An empty op selects nodes
+// for which the key is set.
+type selectorTerm struct {
+	key      string
+	op       string
+	value    string
+	duration time.Duration
+}
+
+func (term selectorTerm) needsState() bool {
+	return term.key == selectorStage || term.key == selectorLastSeen
+}
+
+func (term selectorTerm) match(target *selectorTarget) bool {
+	n := target.node
+	var values []string
+	switch {
+	case term.key == selectorName:
+		values = []string{n.Id()}
+	case term.key == selectorCluster:
+		values = []string{n.ClusterName}
+	case term.key == selectorImage:
+		values = []string{n.ImageName}
+	case term.key == selectorProfile:
+		values = target.profiles
+	case term.key == selectorNetwork:
+		for _, netdev := range n.NetDevs {
+			if netdev != nil && netdev.Network != "" {
+				values = append(values, netdev.Network)
+			}
+		}
+	case term.key == selectorStage:
+		values = []string{target.state.Stage}
+	case term.key == selectorLastSeen:
+		return term.matchLastSeen(target)
+	case strings.HasPrefix(term.key, selectorTag):
+		if value, ok := n.Tags[strings.TrimPrefix(term.key, selectorTag)]; ok {
+			values = []string{value}
+		}
+	}
+
+	if term.op == "" {
+		for _, value := range values {
+			if value != "" {
+				return true
+			}
+		}
+		return false
+	}
+	patterns := []string{term.value}
+	if term.key == selectorName {
+		patterns = hostlist.Expand(patterns)
+	}
+	matched := false
+	for _, value := range values {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, value); ok {
+				matched = true
+			}
+		}
+	}
+	if term.op == "!=" {
+		return !matched
+	}
+	return matched
+}
+
+func (term selectorTerm) matchLastSeen(target *selectorTarget) bool {
+	if !target.seen || target.state.LastSeen.IsZero() {
+		return term.op == ">" || term.op == ">="
+	}
+	age := time.Since(target.state.LastSeen)
+	switch term.op {
+	case "<":
+		return age < term.duration
+	case "<=":
+		return age <= term.duration
+	case ">":
+		return age > term.duration
+	case ">=":
+		return age >= term.duration
+	}
+	return false
+}
+
+type selectorToken struct {
+	// op is true for operators and parentheses, false for words
+	op   bool
+	text string
+}
+
+// selectorOps are the operators of the selector language, longest first.
+var selectorOps = []string{"&&", "||", "==", "!=", "<=", ">=", "(", ")", "!", ",", "=", "<", ">"}
+
+func tokenizeSelector(expression string) (tokens []selectorToken, err error) {
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if unicode.IsSpace(r) {
+			i++
+			continue
+		}
+		if r == '"' || r == '\'' {
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			tokens = append(tokens, selectorToken{text: string(runes[i+1 : end])})
+			i = end + 1
+			continue
+		}
+		if op := selectorOpAt(runes[i:]); op != "" {
+			tokens = append(tokens, selectorToken{op: true, text: op})
+			i += len(op)
+			continue
+		}
+		// a word ends at whitespace or an operator, except for commas in
+		// brackets, as in hostlist patterns
+		start, depth := i, 0
+		for ; i < len(runes) && !unicode.IsSpace(runes[i]); i++ {
+			if runes[i] == '[' {
+				depth++
+			} else if runes[i] == ']' && depth > 0 {
+				depth--
+			} else if depth == 0 && selectorOpAt(runes[i:]) != "" {
+				break
+			}
+		}
+		tokens = append(tokens, selectorToken{text: string(runes[start:i])})
+	}
+	return tokens, nil
+}
+
+func selectorOpAt(runes []rune) string {
+	for _, op := range selectorOps {
+		if strings.HasPrefix(string(runes[:min(len(runes), len(op))]), op) {
+			return op
+		}
+	}
+	return ""
+}
+
+type selectorParser struct {
+	tokens []selectorToken
+	pos    int
+}
+
+// accept consumes the next token if it is one of the given operators or
+// (case-insensitive) keywords.
+func (parser *selectorParser) accept(ops []string, keywords ...string) bool {
+	if parser.pos >= len(parser.tokens) {
+		return false
+	}
+	token := parser.tokens[parser.pos]
+	if (token.op && util.InSlice(ops, token.text)) || (!token.op && util.InSlice(keywords, strings.ToLower(token.text))) {
+		parser.pos++
+		return true
+	}
+	return false
+}
+
+func (parser *selectorParser) parseOr() (selectorExpr, error) {
+	var exprs selectorOr
+	for {
+		expr, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !parser.accept([]string{"||"}, "or") {
+			break
+		}
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return exprs, nil
+}
+
+func (parser *selectorParser) parseAnd() (selectorExpr, error) {
+	var exprs selectorAnd
+	for {
+		expr, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !parser.accept([]string{"&&", ","}, "and") {
+			break
+		}
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return exprs, nil
+}
+
+func (parser *selectorParser) parseNot() (selectorExpr, error) {
+	if parser.accept([]string{"!"}, "not") {
+		expr, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return selectorNot{expr}, nil
+	}
+	if parser.accept([]string{"("}) {
+		expr, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !parser.accept([]string{")"}) {
+			return nil, fmt.Errorf("missing )")
+		}
+		return expr, nil
+	}
+	return parser.parseTerm()
+}
+
+func (parser *selectorParser) parseTerm() (selectorExpr, error) {
+	if parser.pos >= len(parser.tokens) {
+		return nil, fmt.Errorf("unexpected end")
+	}
+	token := parser.tokens[parser.pos]
+	if token.op {
+		return nil, fmt.Errorf("unexpected %q", token.text)
+	}
+	parser.pos++
+	term := selectorTerm{key: strings.ToLower(token.text)}
+	switch {
+	case util.InSlice([]string{selectorName, selectorCluster, selectorImage, selectorProfile, selectorNetwork, selectorStage, selectorLastSeen}, term.key):
+	case strings.HasPrefix(term.key, selectorTag) && len(term.key) > len(selectorTag):
+		// tag names are case-sensitive
+		term.key = selectorTag + token.text[len(selectorTag):]
+	default:
+		return nil, fmt.Errorf("unknown key %q", token.text)
+	}
+
+	if parser.pos < len(parser.tokens) && parser.tokens[parser.pos].op &&
+		util.InSlice([]string{"=", "==", "!=", "<", "<=", ">", ">="}, parser.tokens[parser.pos].text) {
+		term.op = parser.tokens[parser.pos].text
+		parser.pos++
+		if parser.pos >= len(parser.tokens) || parser.tokens[parser.pos].op {
+			return nil, fmt.Errorf("missing value for %s", token.text)
+		}
+		term.value = parser.tokens[parser.pos].text
+		parser.pos++
+	}
+
+	comparison := util.InSlice([]string{"<", "<=", ">", ">="}, term.op)
+	if term.key == selectorLastSeen {
+		if !comparison {
+			return nil, fmt.Errorf("%s must be compared with <, <=, >, or >=", token.text)
+		}
+		duration, err := parseSelectorDuration(term.value)
+		if err != nil {
+			return nil, err
+		}
+		term.duration = duration
+	} else if comparison {
+		return nil, fmt.Errorf("%s can't be compared with %s", token.text, term.op)
+	} else if term.op == "" && !strings.HasPrefix(term.key, selectorTag) {
+		return nil, fmt.Errorf("missing value for %s", token.text)
+	}
+	if term.op == "==" {
+		term.op = "="
+	}
+	return term, nil
+}
+
+// parseSelectorDuration parses a duration, which may also be given in
+// days (e.g., 2d), or in seconds without a unit.
+func parseSelectorDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.ParseFloat(days, 64); err == nil {
+			return time.Duration(n * float64(24*time.Hour)), nil
+		}
+	}
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(n * float64(time.Second)), nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return duration, nil
+}
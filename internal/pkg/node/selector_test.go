@@ -0,0 +1,131 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SelectNodes(t *testing.T) {
+	registry, err := Parse([]byte(`
+nodeprofiles:
+  default:
+    cluster name: hpc1
+  gpu:
+    profiles:
+    - default
+    tags:
+      gpu: a100
+nodes:
+  n01:
+    profiles:
+    - gpu
+    image name: rocky9
+    cluster name: hpc2
+  n02:
+    profiles:
+    - gpu
+    image name: rocky8
+    tags:
+      gpu: h100
+  n03:
+    profiles:
+    - default
+    image name: rocky9
+    network devices:
+      default:
+        network: cluster
+  n10:
+    image name: rocky9
+    tags:
+      rack: "1"`))
+	assert.NoError(t, err)
+	nodes, err := registry.FindAllNodes()
+	assert.NoError(t, err)
+
+	now := time.Now()
+	state := func(id string) (NodeState, bool) {
+		switch id {
+		case "n01":
+			return NodeState{Stage: "RUNTIME_OVERLAY", LastSeen: now.Add(-time.Minute)}, true
+		case "n02":
+			return NodeState{Stage: "KERNEL", LastSeen: now.Add(-time.Hour)}, true
+		}
+		return NodeState{}, false
+	}
+
+	var tests = map[string]struct {
+		selector string
+		nodes    []string
+	}{
+		"tag, cluster, and image":  {"tag.gpu=a100 && cluster=hpc2 && image=rocky9", []string{"n01"}},
+		"and with commas":          {"tag.gpu=a100, cluster=hpc2, image=rocky9", []string{"n01"}},
+		"and with keyword":         {"tag.gpu=a100 and image=rocky9", []string{"n01"}},
+		"tag from node":            {"tag.gpu==h100", []string{"n02"}},
+		"tag set":                  {"tag.gpu", []string{"n01", "n02"}},
+		"tag not set":              {"!tag.gpu", []string{"n03", "n10"}},
+		"nested profile":           {"profile=default", []string{"n01", "n02", "n03"}},
+		"cluster from profile":     {"cluster=hpc1", []string{"n02", "n03"}},
+		"pattern":                  {"image=rocky*", []string{"n01", "n02", "n03", "n10"}},
+		"not equal":                {"image!=rocky9", []string{"n02"}},
+		"hostlist":                 {"name=n[01-03]", []string{"n01", "n02", "n03"}},
+		"hostlist with commas":     {"name=n[01,10]", []string{"n01", "n10"}},
+		"or":                       {"tag.rack=1 || network=cluster", []string{"n03", "n10"}},
+		"precedence":               {"tag.rack=1 or network=cluster and image=rocky8", []string{"n10"}},
+		"parentheses":              {"(tag.rack=1 or network=cluster) and not image=rocky8", []string{"n03", "n10"}},
+		"quoted":                   {`tag.rack="1"`, []string{"n10"}},
+		"stage":                    {"stage=RUNTIME*", []string{"n01"}},
+		"seen recently":            {"lastseen<10m", []string{"n01"}},
+		"not seen recently":        {"lastseen>10m", []string{"n02", "n03", "n10"}},
+		"not seen in days":         {"lastseen>=1d", []string{"n03", "n10"}},
+		"seen in seconds":          {"lastseen<=120", []string{"n01"}},
+		"case-insensitive keys":    {"Image=rocky8", []string{"n02"}},
+		"case-sensitive tag names": {"tag.GPU", nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			selector, err := ParseSelector(tt.selector)
+			assert.NoError(t, err)
+			var ids []string
+			for _, n := range registry.SelectNodes(nodes, selector, state) {
+				ids = append(ids, n.Id())
+			}
+			assert.Equal(t, tt.nodes, ids)
+		})
+	}
+}
+
+func Test_ParseSelector(t *testing.T) {
+	var tests = map[string]struct {
+		selector   string
+		err        string
+		needsState bool
+	}{
+		"valid":               {selector: "image=rocky9"},
+		"state":               {selector: "image=rocky9 || !(stage=KERNEL)", needsState: true},
+		"unknown key":         {selector: "color=red", err: `invalid selector "color=red": unknown key "color"`},
+		"missing value":       {selector: "image", err: `invalid selector "image": missing value for image`},
+		"missing value at op": {selector: "image=", err: `invalid selector "image=": missing value for image`},
+		"missing paren":       {selector: "(image=a", err: `invalid selector "(image=a": missing )`},
+		"trailing":            {selector: "image=a)", err: `invalid selector "image=a)": unexpected ")"`},
+		"empty":               {selector: "", err: `invalid selector "": unexpected end`},
+		"unterminated quote":  {selector: `image="a`, err: `invalid selector "image=\"a": unterminated quote`},
+		"lastseen without op": {selector: "lastseen=1m", err: `invalid selector "lastseen=1m": lastseen must be compared with <, <=, >, or >=`},
+		"invalid duration":    {selector: "lastseen>soon", err: `invalid selector "lastseen>soon": invalid duration "soon"`},
+		"comparison":          {selector: "image>a", err: `invalid selector "image>a": image can't be compared with >`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			selector, err := ParseSelector(tt.selector)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.needsState, selector.NeedsState())
+		})
+	}
+}
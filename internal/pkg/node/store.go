@@ -0,0 +1,132 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+)
+
+/*
+Store is a storage backend for nodes, profiles, and networks.
+*/
+type Store interface {
+	// Load reads all nodes, profiles, and networks.
+	Load() (NodesYaml, error)
+	// GetNode reads a single node, without its profiles merged in.
+	GetNode(id string) (*Node, error)
+	// GetProfile reads a single profile.
+	GetProfile(id string) (*Profile, error)
+	// Put writes nodes, profiles, and networks, replacing any with the
+	// same id.
+	Put(nodes map[string]*Node, profiles map[string]*Profile, networks map[string]*Network) error
+	// Delete removes nodes, profiles, and networks.
+	Delete(nodes []string, profiles []string, networks []string) error
+	// Apply writes and removes nodes, profiles, and networks in a
+	// single write, so that readers never see only some of the changes.
+	Apply(changes Changes) error
+	// Watch signals changes of the stored nodes and profiles until ctx
+	// is done.
+	Watch(ctx context.Context) (<-chan struct{}, error)
+	// ModTime returns when the stored nodes and profiles were last
+	// changed.
+	ModTime() (time.Time, error)
+	// File returns the file which holds the nodes and profiles.
+	File() string
+}
+
+/*
+Changes are nodes, profiles, and networks which are written to a store,
+replacing any with the same id, and the ids of those which are removed
+from it.
+*/
+type Changes struct {
+	Nodes           map[string]*Node
+	Profiles        map[string]*Profile
+	Networks        map[string]*Network
+	DeletedNodes    []string
+	DeletedProfiles []string
+	DeletedNetworks []string
+}
+
+const (
+	BackendYaml = "yaml"
+	BackendBolt = "bolt"
+)
+
+// Backends lists the available storage backends.
+var Backends = []string{BackendYaml, BackendBolt}
+
+/*
+NewStore returns the storage backend with the given name, using its
+default file.
+*/
+func NewStore(backend string) (Store, error) {
+	paths := warewulfconf.Get().Paths
+	switch backend {
+	case "", BackendYaml:
+		return &yamlStore{file: paths.NodesConf()}, nil
+	case BackendBolt:
+		return &boltStore{file: paths.NodesDB()}, nil
+	default:
+		return nil, fmt.Errorf("unknown node database backend: %s", backend)
+	}
+}
+
+/*
+OpenStore returns the storage backend configured in warewulf.conf.
+*/
+func OpenStore() (Store, error) {
+	conf := warewulfconf.Get()
+	backend := ""
+	if conf.NodeDB != nil {
+		backend = conf.NodeDB.Backend
+	}
+	return NewStore(backend)
+}
+
+/*
+Migrate replaces all nodes, profiles, and networks in the store to
+with those in the store from.
+*/
+func Migrate(from Store, to Store) error {
+	registry, err := from.Load()
+	if err != nil {
+		return err
+	}
+	existing, err := to.Load()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	changes := Changes{
+		Nodes:    registry.Nodes,
+		Profiles: registry.NodeProfiles,
+		Networks: registry.Networks,
+	}
+	for id := range existing.Nodes {
+		if _, ok := registry.Nodes[id]; !ok {
+			changes.DeletedNodes = append(changes.DeletedNodes, id)
+		}
+	}
+	for id := range existing.NodeProfiles {
+		if _, ok := registry.NodeProfiles[id]; !ok {
+			changes.DeletedProfiles = append(changes.DeletedProfiles, id)
+		}
+	}
+	for id := range existing.Networks {
+		if _, ok := registry.Networks[id]; !ok {
+			changes.DeletedNetworks = append(changes.DeletedNetworks, id)
+		}
+	}
+	return to.Apply(changes)
+}
+
+func fileModTime(fileName string) (time.Time, error) {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
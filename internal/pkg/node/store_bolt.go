@@ -0,0 +1,206 @@
+package node
+
+import (
+	"context"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	boltNodes    = []byte("nodes")
+	boltProfiles = []byte("profiles")
+	boltNetworks = []byte("networks")
+)
+
+/*
+boltStore keeps each node, profile, and network as a separate YAML document in an
+embedded key-value database, so that single nodes and profiles can be
+read and written without parsing all of them.
+*/
+type boltStore struct {
+	file string
+}
+
+func (store *boltStore) File() string {
+	return store.file
+}
+
+// open opens the database, waiting for other processes which are
+// writing to it. A missing database is only created for writing.
+func (store *boltStore) open(readOnly bool) (*bolt.DB, error) {
+	if readOnly {
+		if _, err := os.Stat(store.file); err != nil {
+			return nil, err
+		}
+	}
+	return bolt.Open(store.file, 0o600, &bolt.Options{Timeout: 30 * time.Second, ReadOnly: readOnly})
+}
+
+func (store *boltStore) view(fn func(tx *bolt.Tx) error) error {
+	db, err := store.open(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+func (store *boltStore) update(fn func(tx *bolt.Tx) error) error {
+	db, err := store.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltNodes, boltProfiles, boltNetworks} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return fn(tx)
+	})
+}
+
+func (store *boltStore) Load() (registry NodesYaml, err error) {
+	registry, _ = Parse(nil)
+	err = store.view(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(boltNodes); bucket != nil {
+			if err := bucket.ForEach(func(k, v []byte) error {
+				node := new(Node)
+				if err := yaml.Unmarshal(v, node); err != nil {
+					return err
+				}
+				registry.Nodes[string(k)] = node
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		if bucket := tx.Bucket(boltProfiles); bucket != nil {
+			if err := bucket.ForEach(func(k, v []byte) error {
+				profile := new(Profile)
+				if err := yaml.Unmarshal(v, profile); err != nil {
+					return err
+				}
+				registry.NodeProfiles[string(k)] = profile
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		if bucket := tx.Bucket(boltNetworks); bucket != nil {
+			return bucket.ForEach(func(k, v []byte) error {
+				network := new(Network)
+				if err := yaml.Unmarshal(v, network); err != nil {
+					return err
+				}
+				registry.Networks[string(k)] = network
+				return nil
+			})
+		}
+		return nil
+	})
+	return registry, err
+}
+
+// get unmarshals the value of key in bucket into out.
+func (store *boltStore) get(bucket []byte, key string, out interface{}) error {
+	return store.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return ErrNotFound
+		}
+		value := b.Get([]byte(key))
+		if value == nil {
+			return ErrNotFound
+		}
+		return yaml.Unmarshal(value, out)
+	})
+}
+
+func (store *boltStore) GetNode(id string) (*Node, error) {
+	node := new(Node)
+	if err := store.get(boltNodes, id, node); err != nil {
+		return nil, err
+	}
+	node.id = id
+	return node, nil
+}
+
+func (store *boltStore) GetProfile(id string) (*Profile, error) {
+	profile := new(Profile)
+	if err := store.get(boltProfiles, id, profile); err != nil {
+		return nil, err
+	}
+	profile.id = id
+	return profile, nil
+}
+
+func (store *boltStore) Put(nodes map[string]*Node, profiles map[string]*Profile, networks map[string]*Network) error {
+	return store.Apply(Changes{Nodes: nodes, Profiles: profiles, Networks: networks})
+}
+
+func (store *boltStore) Delete(nodes []string, profiles []string, networks []string) error {
+	return store.Apply(Changes{DeletedNodes: nodes, DeletedProfiles: profiles, DeletedNetworks: networks})
+}
+
+func (store *boltStore) Apply(changes Changes) error {
+	return store.update(func(tx *bolt.Tx) error {
+		for _, id := range changes.DeletedNodes {
+			if err := tx.Bucket(boltNodes).Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		for _, id := range changes.DeletedProfiles {
+			if err := tx.Bucket(boltProfiles).Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		for _, id := range changes.DeletedNetworks {
+			if err := tx.Bucket(boltNetworks).Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		for id, node := range changes.Nodes {
+			node.Flatten()
+			value, err := yaml.Marshal(node)
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket(boltNodes).Put([]byte(id), value); err != nil {
+				return err
+			}
+		}
+		for id, profile := range changes.Profiles {
+			profile.Flatten()
+			value, err := yaml.Marshal(profile)
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket(boltProfiles).Put([]byte(id), value); err != nil {
+				return err
+			}
+		}
+		for id, network := range changes.Networks {
+			value, err := yaml.Marshal(network)
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket(boltNetworks).Put([]byte(id), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (store *boltStore) Watch(ctx context.Context) (<-chan struct{}, error) {
+	return watchFile(ctx, store.file), nil
+}
+
+func (store *boltStore) ModTime() (time.Time, error) {
+	return fileModTime(store.file)
+}
@@ -0,0 +1,243 @@
+package node
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Store(t *testing.T) {
+	for _, backend := range Backends {
+		t.Run(backend, func(t *testing.T) {
+			env := testenv.New(t)
+			defer env.RemoveAll()
+			assert.NoError(t, os.Remove(env.GetPath("etc/warewulf/nodes.conf")))
+
+			store, err := NewStore(backend)
+			assert.NoError(t, err)
+			_, err = store.Load()
+			assert.ErrorIs(t, err, os.ErrNotExist)
+
+			n1 := NewNode("n1")
+			n1.Comment = "first"
+			n2 := NewNode("n2")
+			profile := EmptyProfile()
+			profile.ClusterName = "cluster"
+			assert.NoError(t, store.Put(
+				map[string]*Node{"n1": &n1, "n2": &n2},
+				map[string]*Profile{"default": &profile},
+				map[string]*Network{"cluster": {CIDR: "10.0.0.0/24"}, "ipmi": {CIDR: "10.0.1.0/24"}}))
+
+			node, err := store.GetNode("n1")
+			assert.NoError(t, err)
+			assert.Equal(t, "n1", node.Id())
+			assert.Equal(t, "first", node.Comment)
+			gotProfile, err := store.GetProfile("default")
+			assert.NoError(t, err)
+			assert.Equal(t, "cluster", gotProfile.ClusterName)
+			_, err = store.GetNode("none")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			assert.NoError(t, store.Delete([]string{"n2"}, nil, []string{"ipmi"}))
+			registry, err := store.Load()
+			assert.NoError(t, err)
+			assert.Len(t, registry.Nodes, 1)
+			assert.Contains(t, registry.Nodes, "n1")
+			assert.Len(t, registry.NodeProfiles, 1)
+			assert.Equal(t, map[string]*Network{"cluster": {CIDR: "10.0.0.0/24"}}, registry.Networks)
+
+			n3 := NewNode("n3")
+			assert.NoError(t, store.Apply(Changes{
+				Nodes:        map[string]*Node{"n3": &n3},
+				DeletedNodes: []string{"n1"},
+			}))
+			registry, err = store.Load()
+			assert.NoError(t, err)
+			assert.Len(t, registry.Nodes, 1)
+			assert.Contains(t, registry.Nodes, "n3")
+			assert.Len(t, registry.NodeProfiles, 1)
+
+			modTime, err := store.ModTime()
+			assert.NoError(t, err)
+			assert.WithinDuration(t, time.Now(), modTime, time.Minute)
+		})
+	}
+}
+
+func Test_StoreBackend(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default:
+    comment: profile
+nodes:
+  n1:
+    profiles:
+    - default`)
+
+	conf := warewulfconf.Get()
+	yamlStore, err := OpenStore()
+	assert.NoError(t, err)
+	assert.Equal(t, conf.Paths.NodesConf(), yamlStore.File())
+
+	conf.NodeDB.Backend = "unknown"
+	_, err = OpenStore()
+	assert.Error(t, err)
+
+	conf.NodeDB.Backend = BackendBolt
+	boltStore, err := OpenStore()
+	assert.NoError(t, err)
+	assert.Equal(t, conf.Paths.NodesDB(), boltStore.File())
+	assert.NoError(t, Migrate(yamlStore, boltStore))
+
+	registry, err := New()
+	assert.NoError(t, err)
+	node, err := registry.GetNode("n1")
+	assert.NoError(t, err)
+	assert.Equal(t, "profile", node.Comment)
+
+	registry.Nodes["n1"].ClusterName = "cluster"
+	_, err = registry.AddNode("n2")
+	assert.NoError(t, err)
+	assert.NoError(t, registry.Persist())
+	stale, err := New()
+	assert.NoError(t, err)
+	assert.NoError(t, registry.DelNode("n2"))
+	assert.NoError(t, registry.Persist())
+	assert.ErrorIs(t, stale.Persist(), ErrConflict)
+
+	registry, err = New()
+	assert.NoError(t, err)
+	assert.Equal(t, "cluster", registry.Nodes["n1"].ClusterName)
+	assert.NotContains(t, registry.Nodes, "n2")
+
+	// the YAML file is not changed by the bolt backend
+	registry, err = yamlStore.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "", registry.Nodes["n1"].ClusterName)
+
+	// migrating back removes nodes which no longer exist
+	_, err = registry.AddNode("n3")
+	assert.NoError(t, err)
+	assert.NoError(t, registry.PersistToFile(yamlStore.File()))
+	assert.NoError(t, Migrate(boltStore, yamlStore))
+	registry, err = yamlStore.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "cluster", registry.Nodes["n1"].ClusterName)
+	assert.NotContains(t, registry.Nodes, "n3")
+}
+
+func Test_PersistAllChanges(t *testing.T) {
+	for _, backend := range Backends {
+		t.Run(backend, func(t *testing.T) {
+			env := testenv.New(t)
+			defer env.RemoveAll()
+			env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default:
+    comment: profile
+nodes:
+  n1:
+    profiles:
+    - default
+networks:
+  cluster:
+    cidr: 10.0.0.0/24
+  ipmi:
+    cidr: 10.0.1.0/24`)
+			conf := warewulfconf.Get()
+			conf.NodeDB.Backend = backend
+			if backend != BackendYaml {
+				yamlStore, err := NewStore(BackendYaml)
+				assert.NoError(t, err)
+				store, err := OpenStore()
+				assert.NoError(t, err)
+				assert.NoError(t, Migrate(yamlStore, store))
+			}
+
+			registry, err := New()
+			assert.NoError(t, err)
+			registry.Networks["cluster"].Comment = "changed"
+			registry.Networks["cluster"].Reserved = []string{"10.0.0.1"}
+			registry.Networks["storage"] = &Network{CIDR: "10.0.2.0/24"}
+			delete(registry.Networks, "ipmi")
+			registry.Nodes["n1"].AssetKey = "asset"
+			registry.Nodes["n1"].Discoverable = "true"
+			registry.NodeProfiles["default"].Comment = "changed"
+			assert.NoError(t, registry.Persist())
+
+			registry, err = New()
+			assert.NoError(t, err)
+			if assert.Contains(t, registry.Networks, "cluster") {
+				assert.Equal(t, "changed", registry.Networks["cluster"].Comment)
+				assert.Equal(t, []string{"10.0.0.1"}, registry.Networks["cluster"].Reserved)
+			}
+			assert.Contains(t, registry.Networks, "storage")
+			assert.NotContains(t, registry.Networks, "ipmi")
+			assert.Equal(t, "asset", registry.Nodes["n1"].AssetKey)
+			assert.True(t, registry.Nodes["n1"].Discoverable.Bool())
+			assert.Equal(t, "changed", registry.NodeProfiles["default"].Comment)
+		})
+	}
+}
+
+func Test_StoreWatch(t *testing.T) {
+	for _, backend := range Backends {
+		t.Run(backend, func(t *testing.T) {
+			env := testenv.New(t)
+			defer env.RemoveAll()
+			env.WriteFile("etc/warewulf/nodes.conf", `nodes: {}`)
+			defer func(interval time.Duration) { watchInterval = interval }(watchInterval)
+			watchInterval = 10 * time.Millisecond
+
+			store, err := NewStore(backend)
+			assert.NoError(t, err)
+			ctx, cancel := context.WithCancel(context.Background())
+			changes, err := store.Watch(ctx)
+			assert.NoError(t, err)
+
+			n1 := NewNode("n1")
+			assert.NoError(t, store.Put(map[string]*Node{"n1": &n1}, nil, nil))
+			select {
+			case <-changes:
+			case <-time.After(5 * time.Second):
+				t.Error("change was not signaled")
+			}
+
+			cancel()
+			for range changes {
+			}
+		})
+	}
+}
+
+func Test_pollFile(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `nodes: {}`)
+	defer func(interval time.Duration) { watchInterval = interval }(watchInterval)
+	watchInterval = 10 * time.Millisecond
+
+	fileName := env.GetPath("etc/warewulf/nodes.conf")
+	modTime, size := statFile(fileName)
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan struct{}, 1)
+	go pollFile(ctx, fileName, modTime, size, changes)
+
+	env.WriteFile("etc/warewulf/nodes.conf", `nodes: {n1: {}}`)
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Error("change was not signaled")
+	}
+
+	cancel()
+	for range changes {
+	}
+}
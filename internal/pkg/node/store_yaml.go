@@ -0,0 +1,100 @@
+package node
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+/*
+yamlStore keeps all nodes, profiles, and networks in a single YAML document, which
+is read and written as a whole.
+*/
+type yamlStore struct {
+	file string
+}
+
+func (store *yamlStore) File() string {
+	return store.file
+}
+
+func (store *yamlStore) Load() (NodesYaml, error) {
+	data, err := os.ReadFile(store.file)
+	if err != nil {
+		return NodesYaml{}, err
+	}
+	return Parse(data)
+}
+
+// loadOrEmpty reads the document, or returns an empty one if it
+// doesn't exist yet.
+func (store *yamlStore) loadOrEmpty() (NodesYaml, error) {
+	registry, err := store.Load()
+	if os.IsNotExist(err) {
+		return Parse(nil)
+	}
+	return registry, err
+}
+
+func (store *yamlStore) GetNode(id string) (*Node, error) {
+	registry, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	node, err := registry.GetNodeOnlyPtr(id)
+	if err != nil {
+		return nil, err
+	}
+	node.id = id
+	return node, nil
+}
+
+func (store *yamlStore) GetProfile(id string) (*Profile, error) {
+	registry, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	return registry.GetProfilePtr(id)
+}
+
+func (store *yamlStore) Put(nodes map[string]*Node, profiles map[string]*Profile, networks map[string]*Network) error {
+	return store.Apply(Changes{Nodes: nodes, Profiles: profiles, Networks: networks})
+}
+
+func (store *yamlStore) Delete(nodes []string, profiles []string, networks []string) error {
+	return store.Apply(Changes{DeletedNodes: nodes, DeletedProfiles: profiles, DeletedNetworks: networks})
+}
+
+func (store *yamlStore) Apply(changes Changes) error {
+	registry, err := store.loadOrEmpty()
+	if err != nil {
+		return err
+	}
+	for _, id := range changes.DeletedNodes {
+		delete(registry.Nodes, id)
+	}
+	for _, id := range changes.DeletedProfiles {
+		delete(registry.NodeProfiles, id)
+	}
+	for _, id := range changes.DeletedNetworks {
+		delete(registry.Networks, id)
+	}
+	for id, node := range changes.Nodes {
+		registry.Nodes[id] = node
+	}
+	for id, profile := range changes.Profiles {
+		registry.NodeProfiles[id] = profile
+	}
+	for id, network := range changes.Networks {
+		registry.Networks[id] = network
+	}
+	return registry.PersistToFile(store.file)
+}
+
+func (store *yamlStore) Watch(ctx context.Context) (<-chan struct{}, error) {
+	return watchFile(ctx, store.file), nil
+}
+
+func (store *yamlStore) ModTime() (time.Time, error) {
+	return fileModTime(store.file)
+}
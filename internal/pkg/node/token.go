@@ -0,0 +1,109 @@
+package node
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// tokenFile returns the file which holds the token of the node.
+func tokenFile(nodeName string) (string, error) {
+	if nodeName == "" || strings.ContainsAny(nodeName, "/\x00") || nodeName == "." || nodeName == ".." {
+		return "", fmt.Errorf("invalid node name for token: %q", nodeName)
+	}
+	return path.Join(warewulfconf.Get().Paths.NodeTokenDir(), nodeName), nil
+}
+
+func readToken(nodeName string) (string, error) {
+	fileName, err := tokenFile(nodeName)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+/*
+Token returns the secret which authorizes runtime overlay requests of
+the node. A new token is issued if the node doesn't have one yet.
+*/
+func Token(nodeName string) (string, error) {
+	token, err := readToken(nodeName)
+	if err == nil && token != "" {
+		return token, nil
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	return RotateToken(nodeName)
+}
+
+/*
+IssuedToken returns the token of the node, without issuing one if the
+node doesn't have one yet, in which case an error is returned.
+*/
+func IssuedToken(nodeName string) (string, error) {
+	token, err := readToken(nodeName)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && token == "") {
+		return "", fmt.Errorf("node %s has not been issued a token", nodeName)
+	}
+	return token, err
+}
+
+/*
+RotateToken issues a new token for the node, replacing its previous
+token.
+*/
+func RotateToken(nodeName string) (string, error) {
+	fileName, err := tokenFile(nodeName)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	if err := os.MkdirAll(path.Dir(fileName), 0700); err != nil {
+		return "", err
+	}
+	tmpFile := fileName + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(token+"\n"), 0600); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpFile, fileName); err != nil {
+		return "", err
+	}
+	wwlog.Verbose("issued new token for node %s", nodeName)
+	return token, nil
+}
+
+/*
+VerifyToken reports whether token matches the token issued for the
+node. Requests of nodes without an issued token are rejected: the token
+is issued when the system overlay of the node is built.
+*/
+func VerifyToken(nodeName string, token string) bool {
+	issued, err := readToken(nodeName)
+	if errors.Is(err, os.ErrNotExist) {
+		wwlog.Verbose("node %s has not been issued a token", nodeName)
+		return false
+	} else if err != nil {
+		wwlog.Error("could not read token of node %s: %s", nodeName, err)
+		return false
+	}
+	if issued == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(issued), []byte(token)) == 1
+}
@@ -0,0 +1,40 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Token(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	assert.False(t, VerifyToken("n1", ""), "nodes without a token are rejected")
+	_, err := IssuedToken("n1")
+	assert.Error(t, err, "IssuedToken doesn't issue a token")
+
+	token, err := Token("n1")
+	assert.NoError(t, err)
+	assert.Len(t, token, 64)
+	issued, err := IssuedToken("n1")
+	assert.NoError(t, err)
+	assert.Equal(t, token, issued)
+	again, err := Token("n1")
+	assert.NoError(t, err)
+	assert.Equal(t, token, again)
+
+	assert.True(t, VerifyToken("n1", token))
+	assert.False(t, VerifyToken("n1", ""))
+	assert.False(t, VerifyToken("n1", "wrong"))
+
+	rotated, err := RotateToken("n1")
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, rotated)
+	assert.False(t, VerifyToken("n1", token))
+	assert.True(t, VerifyToken("n1", rotated))
+
+	_, err = Token("../n1")
+	assert.Error(t, err)
+}
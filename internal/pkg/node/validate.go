@@ -0,0 +1,384 @@
+package node
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strings"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+/*
+Problem is a problem of a merged node found by Validate. Field is the
+name of the field as listed by GetFieldList, and Source is the profile
+the value was merged from, if any.
+*/
+type Problem struct {
+	Severity Severity `json:"severity"`
+	Node     string   `json:"node"`
+	Field    string   `json:"field"`
+	Source   string   `json:"source,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (problem Problem) String() string {
+	field := problem.Field
+	if problem.Source != "" {
+		field += " (from " + problem.Source + ")"
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", problem.Severity, problem.Node, field, problem.Message)
+}
+
+// ValidationError is returned by Persist if the node configuration has
+// problems and strict validation is enabled.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (err *ValidationError) Error() string {
+	errors, warnings := CountProblems(err.Problems)
+	return fmt.Sprintf("node configuration is invalid: %d error(s), %d warning(s)", errors, warnings)
+}
+
+// CountProblems returns the number of errors and warnings in problems.
+func CountProblems(problems []Problem) (errors int, warnings int) {
+	for _, problem := range problems {
+		if problem.Severity == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return
+}
+
+// StrictValidation reports whether Persist refuses to write a node
+// configuration which has problems, as set with nodedb:strict in
+// warewulf.conf.
+func StrictValidation() bool {
+	conf := warewulfconf.Get()
+	return conf.NodeDB != nil && conf.NodeDB.Strict()
+}
+
+/*
+Validate checks the merged nodes for:
+
+  - profiles and networks which don't exist
+  - hardware and IP addresses used by more than one node
+  - addresses outside of the network of the Warewulf server, their
+    network device, or their network, and reserved addresses
+  - images and overlays which don't exist
+  - network devices without a device name
+
+If nodes are given, only they are merged and checked; the addresses of
+the other nodes are still considered for duplicates, without merging
+them.
+*/
+func (config *NodesYaml) Validate(nodes ...string) (problems []Problem, err error) {
+	var ids []string
+	if len(nodes) > 0 {
+		for _, id := range nodes {
+			if _, ok := config.Nodes[id]; ok && !util.InSlice(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	} else {
+		for id := range config.Nodes {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	hwaddrs := make(map[string][]string)
+	ipaddrs := make(map[string][]string)
+	for id := range config.Nodes {
+		for name, addresses := range config.netdevAddresses(id) {
+			if addresses.hwaddr != "" {
+				hwaddrs[addresses.hwaddr] = append(hwaddrs[addresses.hwaddr], id+"/"+name)
+			}
+			if addresses.ipaddr != "" {
+				ipaddrs[addresses.ipaddr] = append(ipaddrs[addresses.ipaddr], id+"/"+name)
+			}
+		}
+	}
+	for _, usedBy := range []map[string][]string{hwaddrs, ipaddrs} {
+		for _, netdevs := range usedBy {
+			sort.Strings(netdevs)
+		}
+	}
+
+	var duplicates []Problem
+	for _, id := range ids {
+		n, fields, err := config.MergeNode(id)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, config.validateProfiles(id)...)
+		problems = append(problems, validateNode(n, fields)...)
+		problems = append(problems, config.validateNetworks(n, fields)...)
+		netdevs := make([]string, 0, len(n.NetDevs))
+		for name := range n.NetDevs {
+			netdevs = append(netdevs, name)
+		}
+		sort.Strings(netdevs)
+		for _, name := range netdevs {
+			for _, unique := range []struct {
+				field  string
+				kind   string
+				value  string
+				usedBy map[string][]string
+			}{
+				{"Hwaddr", "hardware address", strings.ToLower(n.NetDevs[name].Hwaddr), hwaddrs},
+				{"Ipaddr", "address", ipString(n.NetDevs[name].Ipaddr), ipaddrs},
+			} {
+				var others []string
+				for _, other := range unique.usedBy[unique.value] {
+					if other != id+"/"+name {
+						others = append(others, other)
+					}
+				}
+				if unique.value == "" || len(others) == 0 {
+					continue
+				}
+				field := "NetDevs[" + name + "]." + unique.field
+				duplicates = append(duplicates, Problem{
+					Severity: SeverityError,
+					Node:     id,
+					Field:    field,
+					Source:   fields.Source(field),
+					Message:  fmt.Sprintf("%s %s is also used by %s", unique.kind, unique.value, strings.Join(others, ", ")),
+				})
+			}
+		}
+	}
+	return append(problems, duplicates...), nil
+}
+
+type netdevAddresses struct {
+	hwaddr string
+	ipaddr string
+}
+
+// netdevAddresses returns the hardware and IP address of each network
+// device of the node id, as they are merged from its profiles and the
+// node, without merging the whole node.
+func (config *NodesYaml) netdevAddresses(id string) map[string]netdevAddresses {
+	addresses := make(map[string]netdevAddresses)
+	add := func(netdevs map[string]*NetDev) {
+		for name, netdev := range netdevs {
+			if netdev == nil {
+				continue
+			}
+			a := addresses[name]
+			if netdev.Hwaddr != "" {
+				a.hwaddr = strings.ToLower(netdev.Hwaddr)
+			}
+			if ipaddr := ipString(netdev.Ipaddr); ipaddr != "" {
+				a.ipaddr = ipaddr
+			}
+			addresses[name] = a
+		}
+	}
+	for _, profileID := range config.getNodeProfiles(id) {
+		if profile, ok := config.NodeProfiles[profileID]; ok {
+			add(profile.NetDevs)
+		}
+	}
+	add(config.Nodes[id].NetDevs)
+	return addresses
+}
+
+// validateProfiles returns the profiles of the node id which don't
+// exist, with the node or profile which references them.
+func (config *NodesYaml) validateProfiles(id string) (problems []Problem) {
+	referencedBy := func(profileID string) string {
+		for _, ref := range config.Nodes[id].Profiles {
+			if ref == profileID {
+				return ""
+			}
+		}
+		for _, ref := range config.getNodeProfiles(id) {
+			if profile, ok := config.NodeProfiles[ref]; ok && util.InSlice(profile.Profiles, profileID) {
+				return ref
+			}
+		}
+		return ""
+	}
+	for _, profileID := range config.getNodeProfiles(id) {
+		if strings.HasPrefix(profileID, "~") {
+			continue
+		}
+		if _, ok := config.NodeProfiles[profileID]; !ok {
+			problems = append(problems, Problem{
+				Severity: SeverityError,
+				Node:     id,
+				Field:    "Profiles",
+				Source:   referencedBy(profileID),
+				Message:  fmt.Sprintf("profile %s does not exist", profileID),
+			})
+		}
+	}
+	return problems
+}
+
+// validateNode checks the fields of a single merged node.
+func validateNode(n Node, fields fieldMap) (problems []Problem) {
+	problem := func(severity Severity, field string, format string, args ...interface{}) {
+		problems = append(problems, Problem{
+			Severity: severity,
+			Node:     n.Id(),
+			Field:    field,
+			Source:   fields.Source(field),
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	if n.ImageName != "" && !image.DoesSourceExist(n.ImageName) {
+		problem(SeverityWarning, "ImageName", "image %s does not exist", n.ImageName)
+	}
+	for _, overlays := range []struct {
+		field string
+		names []string
+	}{
+		{"SystemOverlay", n.SystemOverlay},
+		{"RuntimeOverlay", n.RuntimeOverlay},
+	} {
+		for _, name := range overlays.names {
+			if !overlayExists(name) {
+				problem(SeverityWarning, overlays.field, "overlay %s does not exist", name)
+			}
+		}
+	}
+
+	serverNet := serverNetwork()
+	var names []string
+	for name := range n.NetDevs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		netdev := n.NetDevs[name]
+		prefix := "NetDevs[" + name + "]."
+		if netdev.Device == "" {
+			problem(SeverityWarning, prefix+"Device", "network device %s has no device", name)
+		}
+		if netdev.Ipaddr == nil || netdev.Ipaddr.IsUnspecified() {
+			continue
+		}
+		if netdev.Primary() && serverNet != nil && !serverNet.Contains(netdev.Ipaddr) {
+			problem(SeverityWarning, prefix+"Ipaddr", "address %s is outside of the Warewulf network %s", netdev.Ipaddr, serverNet)
+		}
+		netmask := netdev.Netmask.To4()
+		if netmask == nil || netmask.IsUnspecified() {
+			continue
+		}
+		network := net.IPNet{IP: netdev.Ipaddr.Mask(net.IPMask(netmask)), Mask: net.IPMask(netmask)}
+		if netdev.Gateway != nil && !netdev.Gateway.IsUnspecified() && !network.Contains(netdev.Gateway) {
+			problem(SeverityWarning, prefix+"Gateway", "gateway %s is outside of the network %s", netdev.Gateway, network.String())
+		}
+	}
+	return problems
+}
+
+// validateNetworks checks the network devices of a merged node against
+// the networks they reference.
+func (config *NodesYaml) validateNetworks(n Node, fields fieldMap) (problems []Problem) {
+	var names []string
+	for name := range n.NetDevs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		netdev := n.NetDevs[name]
+		if netdev.Network == "" {
+			continue
+		}
+		prefix := "NetDevs[" + name + "]."
+		problem := func(severity Severity, field string, format string, args ...interface{}) {
+			problems = append(problems, Problem{
+				Severity: severity,
+				Node:     n.Id(),
+				Field:    prefix + field,
+				Source:   fields.Source(prefix + field),
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+		network, ok := config.Networks[netdev.Network]
+		if !ok {
+			problem(SeverityError, "Network", "network %s does not exist", netdev.Network)
+			continue
+		}
+		ipNet, err := network.IPNet()
+		if err != nil {
+			problem(SeverityError, "Network", "network %s is invalid: %s", netdev.Network, err)
+			continue
+		}
+		ipaddr := ipString(netdev.Ipaddr)
+		if ipaddr == "" {
+			continue
+		}
+		if !ipNet.Contains(netdev.Ipaddr) {
+			problem(SeverityWarning, "Ipaddr", "address %s is outside of the network %s (%s)", ipaddr, netdev.Network, network.CIDR)
+		} else if network.IsReserved(netdev.Ipaddr) {
+			problem(SeverityWarning, "Ipaddr", "address %s is reserved in the network %s", ipaddr, netdev.Network)
+		}
+	}
+	return problems
+}
+
+// ipString returns the IPv4 or IPv6 address ip as a string, or an empty
+// string if it isn't set.
+func ipString(ip net.IP) string {
+	if ip == nil || ip.IsUnspecified() {
+		return ""
+	}
+	return ip.String()
+}
+
+// serverNetwork returns the network of the Warewulf server, as
+// configured in warewulf.conf.
+func serverNetwork() *net.IPNet {
+	conf := warewulfconf.Get()
+	network := net.ParseIP(conf.Network).To4()
+	netmask := net.ParseIP(conf.Netmask).To4()
+	if network == nil || netmask == nil {
+		return nil
+	}
+	return &net.IPNet{IP: network.Mask(net.IPMask(netmask)), Mask: net.IPMask(netmask)}
+}
+
+// overlayExists reports whether a site or distribution overlay with
+// the given name exists. (The overlay package can't be used here, as it
+// depends on this package.)
+func overlayExists(name string) bool {
+	paths := warewulfconf.Get().Paths
+	return util.IsDir(path.Join(paths.SiteOverlaydir(), name)) ||
+		util.IsDir(path.Join(paths.DistributionOverlaydir(), name))
+}
+
+// validateChanges validates the nodes which were added or changed in
+// config compared to before, including nodes whose profiles changed.
+func (config *NodesYaml) validateChanges(before NodesYaml) ([]Problem, error) {
+	beforeHashes := before.NodeHashes()
+	var changed []string
+	for id, hash := range config.NodeHashes() {
+		if beforeHashes[id] != hash {
+			changed = append(changed, id)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	return config.Validate(changed...)
+}
@@ -0,0 +1,267 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Validate(t *testing.T) {
+	var tests = map[string]struct {
+		nodesConf string
+		nodes     []string
+		problems  []Problem
+	}{
+		"valid": {
+			nodesConf: `
+nodeprofiles:
+  default:
+    image name: rocky
+    system overlay:
+    - wwinit
+nodes:
+  n1:
+    profiles:
+    - default
+    network devices:
+      default:
+        device: eth0
+        hwaddr: 00:00:00:00:00:01
+        ipaddr: 192.168.0.1
+        netmask: 255.255.255.0
+        gateway: 192.168.0.254`,
+		},
+		"missing profiles": {
+			nodesConf: `
+nodeprofiles:
+  default:
+    profiles:
+    - nested
+nodes:
+  n1:
+    profiles:
+    - default
+    - missing
+    - ~negated`,
+			problems: []Problem{
+				{SeverityError, "n1", "Profiles", "default", "profile nested does not exist"},
+				{SeverityError, "n1", "Profiles", "", "profile missing does not exist"},
+			},
+		},
+		"duplicate hwaddr": {
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default:
+        device: eth0
+        hwaddr: 00:00:00:00:00:01
+  n2:
+    network devices:
+      default:
+        device: eth0
+        hwaddr: 00:00:00:00:00:01`,
+			problems: []Problem{
+				{SeverityError, "n1", "NetDevs[default].Hwaddr", "", "hardware address 00:00:00:00:00:01 is also used by n2/default"},
+				{SeverityError, "n2", "NetDevs[default].Hwaddr", "", "hardware address 00:00:00:00:00:01 is also used by n1/default"},
+			},
+		},
+		"duplicate hwaddr of other node": {
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default:
+        device: eth0
+        hwaddr: 00:00:00:00:00:01
+  n2:
+    network devices:
+      default:
+        device: eth0
+        hwaddr: 00:00:00:00:00:01`,
+			nodes: []string{"n2"},
+			problems: []Problem{
+				{SeverityError, "n2", "NetDevs[default].Hwaddr", "", "hardware address 00:00:00:00:00:01 is also used by n1/default"},
+			},
+		},
+		"duplicate ipaddr of other node from a profile": {
+			nodesConf: `
+nodeprofiles:
+  p1:
+    network devices:
+      default:
+        ipaddr: 192.168.0.1
+nodes:
+  n1:
+    profiles:
+    - p1
+    network devices:
+      default:
+        device: eth0
+  n2:
+    network devices:
+      default:
+        device: eth0
+        ipaddr: 192.168.0.1
+  n3:
+    profiles:
+    - missing`,
+			nodes: []string{"n2"},
+			problems: []Problem{
+				{SeverityError, "n2", "NetDevs[default].Ipaddr", "", "address 192.168.0.1 is also used by n1/default"},
+			},
+		},
+		"missing image and overlays": {
+			nodesConf: `
+nodeprofiles:
+  default:
+    image name: missing
+    runtime overlay:
+    - missing
+nodes:
+  n1:
+    profiles:
+    - default
+    system overlay:
+    - wwinit`,
+			problems: []Problem{
+				{SeverityWarning, "n1", "ImageName", "default", "image missing does not exist"},
+				{SeverityWarning, "n1", "RuntimeOverlay", "default", "overlay missing does not exist"},
+			},
+		},
+		"addresses outside of the network": {
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default:
+        device: eth0
+        ipaddr: 10.0.0.1
+        netmask: 255.255.255.0
+        gateway: 10.0.1.254`,
+			problems: []Problem{
+				{SeverityWarning, "n1", "NetDevs[default].Ipaddr", "", "address 10.0.0.1 is outside of the Warewulf network 192.168.0.0/24"},
+				{SeverityWarning, "n1", "NetDevs[default].Gateway", "", "gateway 10.0.1.254 is outside of the network 10.0.0.0/24"},
+			},
+		},
+		"networks": {
+			nodesConf: `
+networks:
+  cluster:
+    cidr: 192.168.0.0/24
+    gateway: 192.168.0.254
+    reserved:
+    - 192.168.0.10-192.168.0.19
+nodeprofiles:
+  default:
+    network devices:
+      default:
+        device: eth0
+        network: cluster
+nodes:
+  n1:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 192.168.0.1
+  n2:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 192.168.0.1
+  n3:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 192.168.0.10
+  n4:
+    network devices:
+      default:
+        device: eth0
+        network: missing
+        ipaddr: 192.168.0.4
+  n5:
+    profiles:
+    - default
+    network devices:
+      default:
+        ipaddr: 192.168.1.5`,
+			problems: []Problem{
+				{SeverityWarning, "n3", "NetDevs[default].Ipaddr", "", "address 192.168.0.10 is reserved in the network cluster"},
+				{SeverityError, "n4", "NetDevs[default].Network", "", "network missing does not exist"},
+				{SeverityWarning, "n5", "NetDevs[default].Ipaddr", "", "address 192.168.1.5 is outside of the Warewulf network 192.168.0.0/24"},
+				{SeverityWarning, "n5", "NetDevs[default].Gateway", "network:cluster", "gateway 192.168.0.254 is outside of the network 192.168.1.0/24"},
+				{SeverityWarning, "n5", "NetDevs[default].Ipaddr", "", "address 192.168.1.5 is outside of the network cluster (192.168.0.0/24)"},
+				{SeverityError, "n1", "NetDevs[default].Ipaddr", "", "address 192.168.0.1 is also used by n2/default"},
+				{SeverityError, "n2", "NetDevs[default].Ipaddr", "", "address 192.168.0.1 is also used by n1/default"},
+			},
+		},
+		"netdev without device": {
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01`,
+			problems: []Problem{
+				{SeverityWarning, "n1", "NetDevs[default].Device", "", "network device default has no device"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			env := testenv.New(t)
+			defer env.RemoveAll()
+			env.WriteFile("etc/warewulf/nodes.conf", tt.nodesConf)
+			env.MkdirAll(testenv.WWChrootdir + "/rocky/rootfs")
+			env.MkdirAll(testenv.WWOverlaydir + "/wwinit")
+			conf := warewulfconf.Get()
+			conf.Network = "192.168.0.0"
+			conf.Netmask = "255.255.255.0"
+
+			registry, err := New()
+			assert.NoError(t, err)
+			problems, err := registry.Validate(tt.nodes...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.problems, problems)
+		})
+	}
+}
+
+func Test_PersistValidation(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodes:
+  n1:
+    profiles:
+    - missing
+  n2: {}`)
+
+	// existing problems of unchanged nodes don't prevent writes
+	registry, err := New()
+	assert.NoError(t, err)
+	strict := true
+	warewulfconf.Get().NodeDB.StrictP = &strict
+	registry.Nodes["n2"].Comment = "changed"
+	assert.NoError(t, registry.Persist())
+
+	registry.Nodes["n2"].Profiles = []string{"missing"}
+	err = registry.Persist()
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []Problem{{SeverityError, "n2", "Profiles", "", "profile missing does not exist"}}, validationErr.Problems)
+
+	strict = false
+	assert.NoError(t, registry.Persist())
+	registry, err = New()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"missing"}, registry.Nodes["n2"].Profiles)
+}
@@ -0,0 +1,115 @@
+package node
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// watchInterval is how often Watch checks for changes if inotify is
+// not available, and how often it checks whether it should stop
+// otherwise.
+var watchInterval = 2 * time.Second
+
+// watchFile signals changes of fileName until ctx is done. Changes are
+// detected with inotify on the directory of fileName, so that the file
+// may be replaced, or else by polling its modification time and size.
+func watchFile(ctx context.Context, fileName string) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err == nil {
+		_, err = unix.InotifyAddWatch(fd, path.Dir(fileName), unix.IN_CLOSE_WRITE|unix.IN_MOVED_TO|unix.IN_DELETE)
+		if err != nil {
+			unix.Close(fd)
+		}
+	}
+	if err != nil {
+		wwlog.Warn("could not watch %s with inotify, polling instead: %s", fileName, err)
+		modTime, size := statFile(fileName)
+		go pollFile(ctx, fileName, modTime, size, changes)
+	} else {
+		go inotifyFile(ctx, fd, path.Base(fileName), changes)
+	}
+	return changes
+}
+
+// inotifyFile reads the events of the inotify instance fd and signals
+// those for the file name.
+func inotifyFile(ctx context.Context, fd int, name string, changes chan<- struct{}) {
+	defer close(changes)
+	defer unix.Close(fd)
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, int(watchInterval.Milliseconds()))
+		if ctx.Err() != nil {
+			return
+		}
+		if err == unix.EINTR || n == 0 {
+			continue
+		} else if err != nil {
+			wwlog.Error("could not watch %s: %s", name, err)
+			return
+		}
+		length, err := unix.Read(fd, buf)
+		if err == unix.EAGAIN || err == unix.EINTR {
+			continue
+		} else if err != nil {
+			wwlog.Error("could not watch %s: %s", name, err)
+			return
+		}
+		changed := false
+		for offset := 0; offset+unix.SizeofInotifyEvent <= length; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			offset = nameStart + int(event.Len)
+			if strings.TrimRight(string(buf[nameStart:offset]), "\x00") == name {
+				changed = true
+			}
+		}
+		if changed {
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// pollFile signals changes of the modification time or size of
+// fileName, starting from modTime and size.
+func pollFile(ctx context.Context, fileName string, modTime time.Time, size int64, changes chan<- struct{}) {
+	defer close(changes)
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			newModTime, newSize := statFile(fileName)
+			if newModTime.Equal(modTime) && newSize == size {
+				continue
+			}
+			modTime, size = newModTime, newSize
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func statFile(fileName string) (time.Time, int64) {
+	if info, err := os.Stat(fileName); err == nil {
+		return info.ModTime(), info.Size()
+	}
+	return time.Time{}, -1
+}
@@ -0,0 +1,287 @@
+package oci
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/containers/image/v5/pkg/compression"
+	imgSpecs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// FileState is the state of the files of an image as described by its
+// layers, keyed by their absolute path.
+type FileState map[string]*File
+
+// File is a file of an image: its tar header and, for regular files,
+// the digest of its content.
+type File struct {
+	Header *tar.Header
+	Digest string
+}
+
+// ChangeKind is how a file of a rootfs differs from its image.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeModified ChangeKind = "modified"
+	ChangeDeleted  ChangeKind = "deleted"
+)
+
+// Change is a file of a rootfs which differs from its image. The
+// contents of added and deleted directories are not listed separately.
+type Change struct {
+	Path string
+	Kind ChangeKind
+}
+
+// CommonLayers returns the number of leading layers which the manifests
+// a and b share.
+func CommonLayers(a, b imgSpecs.Manifest) (n int) {
+	for n < len(a.Layers) && n < len(b.Layers) && a.Layers[n].Digest == b.Layers[n].Digest {
+		n++
+	}
+	return n
+}
+
+// Clone returns a copy of the state.
+func (state FileState) Clone() FileState {
+	clone := make(FileState, len(state))
+	for name, file := range state {
+		clone[name] = file
+	}
+	return clone
+}
+
+// ApplyLayers adds the files of layers, which are read from the blob
+// cache, to state.
+func (p *puller) ApplyLayers(ctx context.Context, state FileState, layers []imgSpecs.Descriptor) error {
+	for _, desc := range layers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		blob, err := os.Open(filepath.Join(p.blobCachePath, "blobs", desc.Digest.Algorithm().String(), desc.Digest.Encoded()))
+		if err != nil {
+			return fmt.Errorf("unable to open layer %s: %w", desc.Digest, err)
+		}
+		err = func() error {
+			defer blob.Close()
+			r, _, err := compression.AutoDecompress(blob)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			return state.apply(r)
+		}()
+		if err != nil {
+			return fmt.Errorf("unable to read layer %s: %w", desc.Digest, err)
+		}
+	}
+	return nil
+}
+
+// apply adds the files of the layer tar stream r to state. Whiteouts
+// remove files of lower layers.
+func (state FileState) apply(r io.Reader) error {
+	layer := make(map[string]bool)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		name := path.Clean("/" + hdr.Name)
+		dir, base := path.Split(name)
+		if base == whiteoutOpaque {
+			state.remove(path.Clean(dir), false, layer)
+			continue
+		} else if strings.HasPrefix(base, whiteoutPrefix) {
+			state.remove(path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)), true, layer)
+			continue
+		} else if name == "/" {
+			continue
+		}
+		if old, ok := state[name]; ok && old.Header.Typeflag == tar.TypeDir && hdr.Typeflag != tar.TypeDir {
+			state.remove(name, false, layer)
+		}
+		file := &File{Header: hdr}
+		if hdr.Typeflag == tar.TypeReg {
+			digest := sha256.New()
+			if _, err := io.Copy(digest, tr); err != nil {
+				return err
+			}
+			file.Digest = fmt.Sprintf("sha256:%x", digest.Sum(nil))
+		}
+		state[name] = file
+		layer[name] = true
+	}
+}
+
+// remove deletes the files below name, and name itself if self is set,
+// except for the files in keep.
+func (state FileState) remove(name string, self bool, keep map[string]bool) {
+	prefix := strings.TrimSuffix(name, "/") + "/"
+	for file := range state {
+		if keep[file] {
+			continue
+		}
+		if (self && file == name) || strings.HasPrefix(file, prefix) {
+			delete(state, file)
+		}
+	}
+}
+
+// resolve returns the file which a hard link refers to.
+func (state FileState) resolve(file *File) *File {
+	for i := 0; i < 16 && file.Header.Typeflag == tar.TypeLink; i++ {
+		target, ok := state[path.Clean("/"+file.Header.Linkname)]
+		if !ok {
+			break
+		}
+		file = target
+	}
+	return file
+}
+
+// Diff returns the files which differ between the states, as a set of
+// their paths.
+func (state FileState) Diff(other FileState) map[string]bool {
+	changed := make(map[string]bool)
+	for name, file := range state {
+		if otherFile, ok := other[name]; !ok || !sameFile(state.resolve(file), other.resolve(otherFile)) {
+			changed[name] = true
+		}
+	}
+	for name := range other {
+		if _, ok := state[name]; !ok {
+			changed[name] = true
+		}
+	}
+	return changed
+}
+
+func sameFile(a, b *File) bool {
+	return a.Header.Typeflag == b.Header.Typeflag && a.Header.Mode == b.Header.Mode &&
+		a.Header.Uid == b.Header.Uid && a.Header.Gid == b.Header.Gid &&
+		a.Header.Linkname == b.Header.Linkname && a.Digest == b.Digest
+}
+
+// DiffRootfs returns the files of the rootfs at root which differ from
+// state, sorted by path.
+func DiffRootfs(root string, state FileState) (changes []Change, err error) {
+	// layers need not have entries for the parents of their files
+	parents := make(map[string]bool)
+	for name := range state {
+		for dir := path.Dir(name); dir != "/" && !parents[dir]; dir = path.Dir(dir) {
+			parents[dir] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	err = filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		name := path.Clean("/" + filepath.ToSlash(rel))
+		if name == "/" {
+			return nil
+		}
+		seen[name] = true
+		stateFile, ok := state[name]
+		if !ok {
+			if parents[name] && d.IsDir() {
+				return nil
+			}
+			changes = append(changes, Change{Path: name, Kind: ChangeAdded})
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if modified, err := differs(file, state.resolve(stateFile).Header); err != nil {
+			return err
+		} else if modified {
+			changes = append(changes, Change{Path: name, Kind: ChangeModified})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make(map[string]bool)
+	for name := range state {
+		if !seen[name] {
+			deleted[name] = true
+		}
+	}
+	for name := range deleted {
+		if !deleted[path.Dir(name)] {
+			changes = append(changes, Change{Path: name, Kind: ChangeDeleted})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// differs reports whether the file differs from hdr in its type,
+// ownership, permissions, or, for regular files, size and modification
+// time, and, for symbolic links, target.
+func differs(file string, hdr *tar.Header) (bool, error) {
+	fi, err := os.Lstat(file)
+	if err != nil {
+		return false, err
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		if int(st.Uid) != hdr.Uid || int(st.Gid) != hdr.Gid {
+			return true, nil
+		}
+		if hdr.Typeflag != tar.TypeSymlink && int64(st.Mode&07777) != hdr.Mode&07777 {
+			return true, nil
+		}
+	}
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return !fi.IsDir(), nil
+	case tar.TypeSymlink:
+		if fi.Mode()&fs.ModeSymlink == 0 {
+			return true, nil
+		}
+		target, err := os.Readlink(file)
+		if err != nil {
+			return false, err
+		}
+		return target != hdr.Linkname, nil
+	case tar.TypeReg:
+		return !fi.Mode().IsRegular() || fi.Size() != hdr.Size || fi.ModTime().Unix() != hdr.ModTime.Unix(), nil
+	case tar.TypeChar:
+		return fi.Mode()&fs.ModeCharDevice == 0, nil
+	case tar.TypeBlock:
+		return fi.Mode()&fs.ModeDevice == 0 || fi.Mode()&fs.ModeCharDevice != 0, nil
+	case tar.TypeFifo:
+		return fi.Mode()&fs.ModeNamedPipe == 0, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,75 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func layerTar(t *testing.T, names ...string) *bytes.Buffer {
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for _, name := range names {
+		hdr := &tar.Header{Name: name, Mode: 0644, Typeflag: tar.TypeReg, ModTime: time.Unix(1700000000, 0)}
+		if name[len(name)-1] == '/' {
+			hdr.Mode, hdr.Typeflag = 0755, tar.TypeDir
+		}
+		assert.NoError(t, w.WriteHeader(hdr))
+	}
+	assert.NoError(t, w.Close())
+	return &buf
+}
+
+func stateNames(state FileState) (names []string) {
+	for name := range state {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestFileStateApply(t *testing.T) {
+	state := make(FileState)
+	assert.NoError(t, state.apply(layerTar(t, "etc/", "etc/a", "etc/b", "opt/", "opt/x/", "opt/x/y", "usr/bin/z")))
+	assert.NoError(t, state.apply(layerTar(t, "etc/.wh.a", "opt/x/.wh..wh..opq", "opt/x/new", "usr/bin/z/")))
+	assert.Equal(t, []string{"/etc", "/etc/b", "/opt", "/opt/x", "/opt/x/new", "/usr/bin/z"}, stateNames(state))
+}
+
+func TestDiffRootfs(t *testing.T) {
+	state := make(FileState)
+	assert.NoError(t, state.apply(layerTar(t, "etc/", "etc/a", "etc/b", "usr/share/doc/", "usr/share/doc/readme")))
+	root := t.TempDir()
+	for name, file := range state {
+		if file.Header.Typeflag == tar.TypeDir {
+			assert.NoError(t, os.MkdirAll(filepath.Join(root, name), 0755))
+			assert.NoError(t, os.Chmod(filepath.Join(root, name), 0755))
+		} else {
+			assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755))
+			assert.NoError(t, os.WriteFile(filepath.Join(root, name), nil, 0644))
+			assert.NoError(t, os.Chmod(filepath.Join(root, name), 0644))
+			assert.NoError(t, os.Chtimes(filepath.Join(root, name), file.Header.ModTime, file.Header.ModTime))
+		}
+	}
+	changes, err := DiffRootfs(root, state)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "etc/a"), []byte("changed"), 0644))
+	assert.NoError(t, os.Chmod(filepath.Join(root, "etc/b"), 0600))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "opt/app/bin"), 0755))
+	assert.NoError(t, os.RemoveAll(filepath.Join(root, "usr/share/doc")))
+	changes, err = DiffRootfs(root, state)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "/etc/a", Kind: ChangeModified},
+		{Path: "/etc/b", Kind: ChangeModified},
+		{Path: "/opt", Kind: ChangeAdded},
+		{Path: "/usr/share/doc", Kind: ChangeDeleted},
+	}, changes)
+}
@@ -0,0 +1,237 @@
+package oci
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/docker"
+	dockerarchive "github.com/containers/image/v5/docker/archive"
+	ociarchive "github.com/containers/image/v5/oci/archive"
+	"github.com/containers/image/v5/oci/layout"
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	imgSpecs "github.com/opencontainers/image-spec/specs-go"
+	imgSpecsV1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// getExportReference parses the uri of an image which is exported.
+func getExportReference(uri string) (types.ImageReference, error) {
+	s := strings.SplitN(uri, ":", 2)
+	if len(s) != 2 {
+		return nil, fmt.Errorf("invalid uri: %q", uri)
+	}
+
+	switch s[0] {
+	case "docker":
+		return docker.ParseReference(s[1])
+	case "docker-archive":
+		return dockerarchive.ParseReference(s[1])
+	case "oci-archive":
+		return ociarchive.ParseReference(s[1])
+	case "oci":
+		return layout.ParseReference(s[1])
+	default:
+		return nil, fmt.Errorf("unknown uri scheme: %q", uri)
+	}
+}
+
+/*
+Export writes files, which are paths relative to root, as the single
+layer of an image to dest, a docker://, docker-archive:, oci-archive:,
+or oci: uri. The image is described by config, and its manifest carries
+annotations.
+*/
+func Export(ctx context.Context, root string, files []string, dest string, sysCtx *types.SystemContext, config imgSpecsV1.Image, annotations map[string]string) error {
+	destRef, err := getExportReference(dest)
+	if err != nil {
+		return fmt.Errorf("unable to parse uri: %v", err)
+	}
+
+	// defaults to $TMPDIR or /tmp
+	tmpDir, err := os.MkdirTemp("", "oci-export-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := os.MkdirAll(filepath.Join(tmpDir, "blobs", "sha256"), 0755); err != nil {
+		return err
+	}
+
+	layerDesc, diffID, err := writeLayer(tmpDir, root, files)
+	if err != nil {
+		return fmt.Errorf("unable to create layer: %w", err)
+	}
+	config.RootFS = imgSpecsV1.RootFS{Type: "layers", DiffIDs: []digest.Digest{diffID}}
+	configDesc, err := writeBlob(tmpDir, imgSpecsV1.MediaTypeImageConfig, config)
+	if err != nil {
+		return err
+	}
+	manifestDesc, err := writeBlob(tmpDir, imgSpecsV1.MediaTypeImageManifest, imgSpecsV1.Manifest{
+		Versioned:   imgSpecs.Versioned{SchemaVersion: 2},
+		MediaType:   imgSpecsV1.MediaTypeImageManifest,
+		Config:      configDesc,
+		Layers:      []imgSpecsV1.Descriptor{layerDesc},
+		Annotations: annotations,
+	})
+	if err != nil {
+		return err
+	}
+	manifestDesc.Annotations = map[string]string{imgSpecsV1.AnnotationRefName: "export"}
+	index, err := json.Marshal(imgSpecsV1.Index{
+		Versioned: imgSpecs.Versioned{SchemaVersion: 2},
+		MediaType: imgSpecsV1.MediaTypeImageIndex,
+		Manifests: []imgSpecsV1.Descriptor{manifestDesc},
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, imgSpecsV1.ImageIndexFile), index, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, imgSpecsV1.ImageLayoutFile), []byte(`{"imageLayoutVersion": "1.0.0"}`), 0644); err != nil {
+		return err
+	}
+
+	srcRef, err := layout.ParseReference(tmpDir + ":export")
+	if err != nil {
+		return fmt.Errorf("unable to generate local oci reference: %v", err)
+	}
+	policyCtx, err := insecurePolicyContext()
+	if err != nil {
+		return fmt.Errorf("unable to create policy context: %v", err)
+	}
+	defer func() { _ = policyCtx.Destroy() }()
+	_, err = copy.Image(ctx, policyCtx, destRef, srcRef, &copy.Options{
+		ReportWriter:   os.Stdout,
+		DestinationCtx: sysCtx,
+	})
+	return err
+}
+
+// writeBlob writes v as json to the blobs of the oci layout at dir.
+func writeBlob(dir string, mediaType string, v interface{}) (desc imgSpecsV1.Descriptor, err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return desc, err
+	}
+	desc = imgSpecsV1.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(data), Size: int64(len(data))}
+	return desc, os.WriteFile(filepath.Join(dir, "blobs", "sha256", desc.Digest.Encoded()), data, 0644)
+}
+
+// writeLayer writes files as a gzip-compressed tar layer to the blobs of
+// the oci layout at dir, and returns its descriptor and diff id.
+func writeLayer(dir string, root string, files []string) (desc imgSpecsV1.Descriptor, diffID digest.Digest, err error) {
+	blob, err := os.CreateTemp(dir, "layer-")
+	if err != nil {
+		return desc, diffID, err
+	}
+	defer os.Remove(blob.Name())
+	defer blob.Close()
+
+	compressed := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(blob, compressed)}
+	gz := gzip.NewWriter(counter)
+	uncompressed := sha256.New()
+	tw := tar.NewWriter(io.MultiWriter(gz, uncompressed))
+	if err := writeFiles(tw, root, files); err != nil {
+		return desc, diffID, err
+	}
+	if err := tw.Close(); err != nil {
+		return desc, diffID, err
+	}
+	if err := gz.Close(); err != nil {
+		return desc, diffID, err
+	}
+	if err := blob.Close(); err != nil {
+		return desc, diffID, err
+	}
+
+	desc = imgSpecsV1.Descriptor{
+		MediaType: imgSpecsV1.MediaTypeImageLayerGzip,
+		Digest:    digest.NewDigestFromBytes(digest.SHA256, compressed.Sum(nil)),
+		Size:      counter.n,
+	}
+	diffID = digest.NewDigestFromBytes(digest.SHA256, uncompressed.Sum(nil))
+	return desc, diffID, os.Rename(blob.Name(), filepath.Join(dir, "blobs", "sha256", desc.Digest.Encoded()))
+}
+
+// writeFiles writes files, relative to root, to tw. Hard links are kept,
+// and sockets, which tar cannot represent, are skipped.
+func writeFiles(tw *tar.Writer, root string, files []string) error {
+	links := make(map[[2]uint64]string)
+	for _, file := range files {
+		fullPath := filepath.Join(root, file)
+		fi, err := os.Lstat(fullPath)
+		if err != nil {
+			return err
+		}
+		if fi.Mode()&os.ModeSocket != 0 {
+			wwlog.Debug("Skipping socket: %s", file)
+			continue
+		}
+		var target string
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if target, err = os.Readlink(fullPath); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(fi, target)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		hdr.Name = filepath.ToSlash(file)
+		if fi.IsDir() {
+			hdr.Name += "/"
+		}
+		hdr.Uname, hdr.Gname = "", ""
+		if st, ok := fi.Sys().(*syscall.Stat_t); ok && fi.Mode().IsRegular() && st.Nlink > 1 {
+			inode := [2]uint64{uint64(st.Dev), st.Ino}
+			if first, ok := links[inode]; ok {
+				hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeLink, first, 0
+			} else {
+				links[inode] = hdr.Name
+			}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if hdr.Typeflag == tar.TypeReg && hdr.Size > 0 {
+			if err := copyFile(tw, fullPath); err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+		}
+	}
+	return nil
+}
+
+func copyFile(w io.Writer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
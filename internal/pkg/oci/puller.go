@@ -43,6 +43,7 @@ type puller struct {
 	blobCachePath string
 	tmpDirPath    string
 	sysCtx        *types.SystemContext
+	policy        *signature.Policy
 }
 
 func NewPuller(opts ...pullerOpt) (*puller, error) {
@@ -108,31 +109,95 @@ func (p *puller) GenerateID(ctx context.Context, uri string) (string, error) {
 }
 
 func (p *puller) Pull(ctx context.Context, uri, dst string) (err error) {
+	if _, err := p.Fetch(ctx, uri); err != nil {
+		return err
+	}
+	return p.Unpack(ctx, dst, 0)
+}
+
+// Fetch copies the image at uri into the blob cache, as the identifier
+// from GenerateID, and returns its manifest. Only layers which are not
+// already in the blob cache are downloaded.
+func (p *puller) Fetch(ctx context.Context, uri string) (manifest imgSpecs.Manifest, err error) {
 	srcRef, err := getReference(uri)
 	if err != nil {
-		return fmt.Errorf("unable to parse uri: %v", err)
+		return manifest, fmt.Errorf("unable to parse uri: %v", err)
 	}
 
 	cacheRef, err := layout.ParseReference(p.blobCachePath + ":" + p.id)
 	if err != nil {
-		return fmt.Errorf("unable to generate local oci reference: %v", err)
+		return manifest, fmt.Errorf("unable to generate local oci reference: %v", err)
 	}
 
-	// Create a wide open oci image signature policy
-	policy := &signature.Policy{Default: []signature.PolicyRequirement{signature.NewPRInsecureAcceptAnything()}}
-	policyCtx, err := signature.NewPolicyContext(policy)
+	srcPolicyCtx, err := insecurePolicyContext()
 	if err != nil {
-		return fmt.Errorf("unable to create policy context: %v", err)
+		return manifest, fmt.Errorf("unable to create policy context: %v", err)
+	}
+	if p.policy != nil {
+		if srcPolicyCtx, err = signature.NewPolicyContext(p.policy); err != nil {
+			return manifest, fmt.Errorf("unable to create policy context: %v", err)
+		}
+		defer func() { _ = srcPolicyCtx.Destroy() }()
+		if err := p.verify(ctx, srcPolicyCtx, uri, srcRef); err != nil {
+			return manifest, err
+		}
 	}
 
-	// copy to cache location
-	_, err = copy.Image(ctx, policyCtx, cacheRef, srcRef, &copy.Options{
+	// copy to cache location, verifying the source again, as it is read
+	// again
+	_, err = copy.Image(ctx, srcPolicyCtx, cacheRef, srcRef, &copy.Options{
 		ReportWriter:     os.Stdout,
 		SourceCtx:        p.sysCtx,
 		RemoveSignatures: true,
 	})
 	if err != nil {
-		return err
+		return manifest, err
+	}
+
+	return p.CachedManifest(ctx, p.id)
+}
+
+// CachedManifest returns the manifest of the image with the identifier
+// id in the blob cache.
+func (p *puller) CachedManifest(ctx context.Context, id string) (manifest imgSpecs.Manifest, err error) {
+	cacheRef, err := layout.ParseReference(p.blobCachePath + ":" + id)
+	if err != nil {
+		return manifest, fmt.Errorf("unable to generate local oci reference: %v", err)
+	}
+	return readManifest(ctx, cacheRef)
+}
+
+func readManifest(ctx context.Context, ref types.ImageReference) (manifest imgSpecs.Manifest, err error) {
+	src, err := ref.NewImageSource(ctx, nil)
+	if err != nil {
+		return manifest, err
+	}
+	defer src.Close()
+
+	manifestBytes, _, err := src.GetManifest(ctx, nil)
+	if err != nil {
+		return manifest, err
+	}
+
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return manifest, fmt.Errorf("unable to unmarshall mafinest json: %v", err)
+	}
+	return manifest, nil
+}
+
+// Unpack unpacks the layers of the fetched image, starting with the
+// layer at index from, onto the rootfs at dst. Layers before from must
+// already be unpacked at dst.
+func (p *puller) Unpack(ctx context.Context, dst string, from int) (err error) {
+	cacheRef, err := layout.ParseReference(p.blobCachePath + ":" + p.id)
+	if err != nil {
+		return fmt.Errorf("unable to generate local oci reference: %v", err)
+	}
+
+	// copies within the cache are not verified
+	policyCtx, err := insecurePolicyContext()
+	if err != nil {
+		return fmt.Errorf("unable to create policy context: %v", err)
 	}
 
 	// defaults to $TMPDIR or /tmp
@@ -154,27 +219,24 @@ func (p *puller) Pull(ctx context.Context, uri, dst string) (err error) {
 		return err
 	}
 
-	tmp, err := tmpRef.NewImageSource(ctx, nil)
+	manifest, err := readManifest(ctx, tmpRef)
 	if err != nil {
 		return err
 	}
-
-	manifestBytes, _, err := tmp.GetManifest(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	var manifest imgSpecs.Manifest
-	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
-		return fmt.Errorf("unable to unmarshall mafinest json: %v", err)
+	if from >= len(manifest.Layers) {
+		return nil
 	}
 
 	eng, err := umoci.OpenLayout(tmpDir)
 	if err != nil {
 		return fmt.Errorf("unable to open oci layout: %v", err)
 	}
+	defer eng.Close()
 
 	var uo layer.UnpackOptions
+	if from > 0 {
+		uo.StartFrom = manifest.Layers[from]
+	}
 	err = layer.UnpackRootfs(ctx, eng, dst, manifest, &uo)
 	if err != nil {
 		return fmt.Errorf("unable to unpack rootfs: %v", err)
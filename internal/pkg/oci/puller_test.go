@@ -1,9 +1,14 @@
 package oci
 
 import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -68,3 +73,103 @@ func TestGetReference(t *testing.T) {
 	}
 
 }
+
+// writeDockerArchive writes a docker-archive with a single layer which
+// contains /hello.
+func writeDockerArchive(t *testing.T, fileName string) {
+	var layer bytes.Buffer
+	lw := tar.NewWriter(&layer)
+	assert.NoError(t, lw.WriteHeader(&tar.Header{Name: "hello", Mode: 0644, Size: 5, Typeflag: tar.TypeReg}))
+	_, err := lw.Write([]byte("hello"))
+	assert.NoError(t, err)
+	assert.NoError(t, lw.Close())
+
+	config := fmt.Sprintf(`{"architecture": "%s", "os": "linux", "rootfs": {"type": "layers", "diff_ids": ["sha256:%x"]}}`,
+		runtime.GOARCH, sha256.Sum256(layer.Bytes()))
+	manifest := `[{"Config": "config.json", "RepoTags": ["test:latest"], "Layers": ["layer.tar"]}]`
+
+	f, err := os.Create(fileName)
+	assert.NoError(t, err)
+	defer f.Close()
+	w := tar.NewWriter(f)
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{
+		{"layer.tar", layer.Bytes()},
+		{"config.json", []byte(config)},
+		{"manifest.json", []byte(manifest)},
+	} {
+		assert.NoError(t, w.WriteHeader(&tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.data)), Typeflag: tar.TypeReg}))
+		_, err := w.Write(file.data)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+}
+
+func TestPullSignaturePolicy(t *testing.T) {
+	temp := t.TempDir()
+	archive := filepath.Join(temp, "test.tar")
+	writeDockerArchive(t, archive)
+	acceptPolicy := filepath.Join(temp, "accept.json")
+	assert.NoError(t, os.WriteFile(acceptPolicy, []byte(`{"default": [{"type": "reject"}], "transports": {"docker-archive": {"": [{"type": "insecureAcceptAnything"}]}}}`), 0644))
+	rejectPolicy := filepath.Join(temp, "reject.json")
+	assert.NoError(t, os.WriteFile(rejectPolicy, []byte(`{"default": [{"type": "reject"}]}`), 0644))
+	key := filepath.Join(temp, "cosign.pub")
+	assert.NoError(t, os.WriteFile(key, []byte("not a key"), 0644))
+
+	tests := map[string]struct {
+		policyPath string
+		keys       []string
+		verify     bool
+		err        string
+	}{
+		"no verification": {},
+		"accept policy":   {verify: true, policyPath: acceptPolicy},
+		"reject policy":   {verify: true, policyPath: rejectPolicy, err: "signature verification failed for " + archive},
+		"keys":            {verify: true, keys: []string{key}, err: "signature verification failed for " + archive},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cache := filepath.Join(temp, name, "cache")
+			assert.NoError(t, os.MkdirAll(cache, 0755))
+			opts := []pullerOpt{OptSetBlobCachePath(cache)}
+			if tt.verify {
+				policy, err := SignaturePolicy(tt.policyPath, tt.keys)
+				assert.NoError(t, err)
+				opts = append(opts, OptSetSignaturePolicy(policy))
+			}
+			p, err := NewPuller(opts...)
+			assert.NoError(t, err)
+			_, err = p.GenerateID(context.Background(), archive)
+			assert.NoError(t, err)
+			rootfs := filepath.Join(temp, name, "rootfs")
+			err = p.Pull(context.Background(), archive, rootfs)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				assert.NoFileExists(t, filepath.Join(rootfs, "hello"))
+			} else {
+				assert.NoError(t, err)
+				assert.FileExists(t, filepath.Join(rootfs, "hello"))
+			}
+		})
+	}
+}
+
+func TestSignaturePolicy(t *testing.T) {
+	temp := t.TempDir()
+	anythingPolicy := filepath.Join(temp, "anything.json")
+	assert.NoError(t, os.WriteFile(anythingPolicy, []byte(`{"default": [{"type": "insecureAcceptAnything"}], "transports": {"docker": {"": [{"type": "insecureAcceptAnything"}]}}}`), 0644))
+	rejectPolicy := filepath.Join(temp, "reject.json")
+	assert.NoError(t, os.WriteFile(rejectPolicy, []byte(`{"default": [{"type": "reject"}]}`), 0644))
+
+	_, err := SignaturePolicy("", nil)
+	assert.ErrorIs(t, err, ErrNoSignaturePolicy)
+
+	_, err = SignaturePolicy(anythingPolicy, nil)
+	assert.ErrorContains(t, err, "accepts any image")
+
+	policy, err := SignaturePolicy(rejectPolicy, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, policy)
+}
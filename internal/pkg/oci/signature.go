@@ -0,0 +1,109 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/containers/image/v5/image"
+	"github.com/containers/image/v5/signature"
+	"github.com/containers/image/v5/types"
+)
+
+var ErrNoSignaturePolicy = errors.New("no signature policy or keys are configured to verify images with")
+
+/*
+SignaturePolicy returns the policy which pulled images must satisfy. If
+policyPath is set, the policy is read from that containers-policy.json(5)
+file. Otherwise, if keys are given, images from registries must be
+signed with one of these sigstore (cosign) public keys, and images from
+other sources are rejected. Otherwise, ErrNoSignaturePolicy is returned:
+the default policy of the system often accepts any image.
+
+A policy which accepts any image from any source is rejected.
+*/
+func SignaturePolicy(policyPath string, keys []string) (*signature.Policy, error) {
+	if policyPath != "" {
+		policy, err := signature.NewPolicyFromFile(policyPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read signature policy %s: %w", policyPath, err)
+		}
+		if acceptsAnything(policy) {
+			return nil, fmt.Errorf("signature policy %s accepts any image", policyPath)
+		}
+		return policy, nil
+	}
+	if len(keys) > 0 {
+		signed, err := signature.NewPRSigstoreSigned(
+			signature.PRSigstoreSignedWithKeyPaths(keys),
+			signature.PRSigstoreSignedWithSignedIdentity(signature.NewPRMMatchRepoDigestOrExact()),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("unable to use signature keys: %w", err)
+		}
+		return &signature.Policy{
+			Default: signature.PolicyRequirements{signature.NewPRReject()},
+			Transports: map[string]signature.PolicyTransportScopes{
+				"docker": {"": signature.PolicyRequirements{signed}},
+			},
+		}, nil
+	}
+	return nil, ErrNoSignaturePolicy
+}
+
+// acceptsAnything reports whether every requirement of the policy is
+// insecureAcceptAnything, so that it verifies nothing.
+func acceptsAnything(policy *signature.Policy) bool {
+	requirements := append(signature.PolicyRequirements{}, policy.Default...)
+	for _, scopes := range policy.Transports {
+		for _, scope := range scopes {
+			requirements = append(requirements, scope...)
+		}
+	}
+	for _, requirement := range requirements {
+		data, err := json.Marshal(requirement)
+		if err != nil {
+			return false
+		}
+		var req struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &req); err != nil || req.Type != "insecureAcceptAnything" {
+			return false
+		}
+	}
+	return true
+}
+
+// OptSetSignaturePolicy verifies pulled images with policy, unless it
+// is nil.
+func OptSetSignaturePolicy(policy *signature.Policy) pullerOpt {
+	return func(p *puller) error {
+		p.policy = policy
+		return nil
+	}
+}
+
+// insecurePolicyContext accepts any image, as is done for copies within
+// the blob cache.
+func insecurePolicyContext() (*signature.PolicyContext, error) {
+	policy := &signature.Policy{Default: []signature.PolicyRequirement{signature.NewPRInsecureAcceptAnything()}}
+	return signature.NewPolicyContext(policy)
+}
+
+// verify checks the image at srcRef against the signature policy of
+// the puller.
+func (p *puller) verify(ctx context.Context, policyCtx *signature.PolicyContext, uri string, srcRef types.ImageReference) error {
+	src, err := srcRef.NewImageSource(ctx, p.sysCtx)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if allowed, err := policyCtx.IsRunningImageAllowed(ctx, image.UnparsedInstance(src, nil)); err != nil {
+		return fmt.Errorf("signature verification failed for %s: %w", uri, err)
+	} else if !allowed {
+		return fmt.Errorf("signature verification failed for %s", uri)
+	}
+	return nil
+}
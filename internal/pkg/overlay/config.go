@@ -105,3 +105,14 @@ func OverlayImage(nodeName string, context string, overlayNames []string) string
 
 	return path.Join(config.Get().Paths.OverlayProvisiondir(), nodeName, name)
 }
+
+// RemoveOverlayImages removes the built overlay images of a node, so that
+// they are built again when they are next requested.
+func RemoveOverlayImages(nodeName string) error {
+	if nodeName == "" || strings.ContainsAny(nodeName, "/\x00") || nodeName == "." || nodeName == ".." {
+		return fmt.Errorf("invalid node name: %q", nodeName)
+	}
+	dir := path.Join(config.Get().Paths.OverlayProvisiondir(), nodeName)
+	wwlog.Verbose("Removing overlay images: %s", dir)
+	return os.RemoveAll(dir)
+}
@@ -43,6 +43,8 @@ type TemplateStruct struct {
 	Container     string
 	ContainerName string
 	ThisNode      *node.Node
+	// NodeToken only issues a token while the overlay is built
+	issueToken bool
 }
 
 /*
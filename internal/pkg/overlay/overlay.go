@@ -102,6 +102,25 @@ func (overlay Overlay) IsDistributionOverlay() bool {
 	return path.Dir(overlay.Path()) == config.Get().Paths.DistributionOverlaydir()
 }
 
+// UsesAllNodes reports whether any template of the overlay refers to
+// AllNodes, so that it renders differently when any node changes.
+func (overlay Overlay) UsesAllNodes() bool {
+	found := false
+	_ = filepath.WalkDir(overlay.Rootfs(), func(fullPath string, entry fs.DirEntry, err error) error {
+		if err != nil || found {
+			return nil
+		}
+		if entry.Type().IsRegular() && filepath.Ext(fullPath) == ".ww" {
+			if content, err := os.ReadFile(fullPath); err == nil && bytes.Contains(content, []byte("AllNodes")) {
+				found = true
+				return fs.SkipAll
+			}
+		}
+		return nil
+	})
+	return found
+}
+
 func BuildAllOverlays(nodes []node.Node, allNodes []node.Node, workerCount int) error {
 	nodeChan := make(chan node.Node, len(nodes))
 	errChan := make(chan error, len(nodes)*2)
@@ -355,6 +374,7 @@ func BuildOverlayIndir(nodeData node.Node, allNodes []node.Node, overlayNames []
 					return fmt.Errorf("failed to initial data for %s: %w", nodeData.Id(), err)
 				}
 				tstruct.BuildSource = walkPath
+				tstruct.issueToken = true
 				wwlog.Verbose("Evaluating overlay template file: %s", walkPath)
 
 				buffer, backupFile, writeFile, err := RenderTemplateFile(walkPath, tstruct)
@@ -509,6 +529,12 @@ func RenderTemplateFile(fileName string, data TemplateStruct) (
 		"IgnitionJson": func() string {
 			return createIgnitionJson(data.ThisNode)
 		},
+		"NodeToken": func() (string, error) {
+			if data.issueToken {
+				return node.Token(data.Id)
+			}
+			return node.IssuedToken(data.Id)
+		},
 		"abort": func() string {
 			wwlog.Debug("abort file called in %s", fileName)
 			writeFile = false
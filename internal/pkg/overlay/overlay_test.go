@@ -748,3 +748,41 @@ func readCpio(name string) (headers map[string]*cpio.Header, err error) {
 		headers[header.Name] = header
 	}
 }
+
+func Test_UsesAllNodes(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/var/lib/warewulf/overlays/hosts/rootfs/etc/hosts.ww", "{{ range $node := $.AllNodes }}{{ $node.Id }}{{ end }}")
+	env.WriteFile("/var/lib/warewulf/overlays/plain/rootfs/etc/hostname.ww", "{{ .Id }}")
+	env.WriteFile("/var/lib/warewulf/overlays/plain/rootfs/etc/AllNodes", "not a template")
+
+	assert.True(t, GetOverlay("hosts").UsesAllNodes())
+	assert.False(t, GetOverlay("plain").UsesAllNodes())
+	assert.False(t, GetOverlay("none").UsesAllNodes())
+}
+
+func Test_NodeToken(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/var/lib/warewulf/overlays/o1/rootfs/token.ww", "{{ NodeToken }}")
+	templateFile := env.GetPath("/var/lib/warewulf/overlays/o1/rootfs/token.ww")
+	n1 := node.NewNode("n1")
+
+	// rendering the template outside of a build doesn't issue a token
+	tstruct, err := InitStruct("o1", n1, []node.Node{n1})
+	assert.NoError(t, err)
+	_, _, _, err = RenderTemplateFile(templateFile, tstruct)
+	assert.ErrorContains(t, err, "has not been issued a token")
+	_, err = node.IssuedToken("n1")
+	assert.Error(t, err)
+
+	env.MkdirAll("/image")
+	assert.NoError(t, BuildOverlayIndir(n1, []node.Node{n1}, []string{"o1"}, env.GetPath("/image")))
+	token, err := node.IssuedToken("n1")
+	assert.NoError(t, err)
+	assert.Equal(t, token, env.ReadFile("/image/token"))
+
+	buffer, _, _, err := RenderTemplateFile(templateFile, tstruct)
+	assert.NoError(t, err)
+	assert.Equal(t, token, buffer.String())
+}
@@ -21,19 +21,31 @@ func Handler(auth *config.Authentication, allowedNets []net.IPNet) *web.Service
 	api.OpenAPISchema().SetDescription("This service provides an API to a Warewulf v4 server.")
 	api.OpenAPISchema().SetVersion(version.GetVersion())
 
+	// Routes are grouped by the role required to access them.
 	api.Route("/api/nodes", func(r chi.Router) {
 		r.Group(func(r chi.Router) {
 			r.Use(AuthMiddleware(auth, allowedNets))
 
-			r.Method(http.MethodGet, "/", nethttp.NewHandler(getNodes()))
-			r.Method(http.MethodGet, "/{id}", nethttp.NewHandler(getNodeByID()))
-			r.Method(http.MethodGet, "/{id}/raw", nethttp.NewHandler(getRawNodeByID()))
-			r.Method(http.MethodPut, "/{id}", nethttp.NewHandler(addNode()))
-			r.Method(http.MethodDelete, "/{id}", nethttp.NewHandler(deleteNode()))
-			r.Method(http.MethodPatch, "/{id}", nethttp.NewHandler(updateNode()))
-			r.Method(http.MethodGet, "/{id}/fields", nethttp.NewHandler(getNodeFields()))
-			r.Method(http.MethodPost, "/overlays/build", nethttp.NewHandler(buildAllOverlays()))
-			r.Method(http.MethodPost, "/{id}/overlays/build", nethttp.NewHandler(buildOverlays()))
+			r.Group(func(r chi.Router) {
+				r.Use(RequireRole(config.RoleReadOnly))
+				r.Method(http.MethodGet, "/", nethttp.NewHandler(getNodes()))
+				r.Method(http.MethodGet, "/{id}", nethttp.NewHandler(getNodeByID()))
+				r.Method(http.MethodGet, "/{id}/fields", nethttp.NewHandler(getNodeFields()))
+				r.Method(http.MethodGet, "/{id}/inventory", nethttp.NewHandler(getNodeInventory()))
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(RequireRole(config.RoleOperator))
+				r.Method(http.MethodPost, "/overlays/build", nethttp.NewHandler(buildAllOverlays()))
+				r.Method(http.MethodPost, "/{id}/overlays/build", nethttp.NewHandler(buildOverlays()))
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(RequireRole(config.RoleAdmin))
+				// raw nodes include IPMI passwords
+				r.Method(http.MethodGet, "/{id}/raw", nethttp.NewHandler(getRawNodeByID()))
+				r.Method(http.MethodPut, "/{id}", nethttp.NewHandler(addNode()))
+				r.Method(http.MethodDelete, "/{id}", nethttp.NewHandler(deleteNode()))
+				r.Method(http.MethodPatch, "/{id}", nethttp.NewHandler(updateNode()))
+			})
 		})
 	})
 
@@ -41,11 +53,17 @@ func Handler(auth *config.Authentication, allowedNets []net.IPNet) *web.Service
 		r.Group(func(r chi.Router) {
 			r.Use(AuthMiddleware(auth, allowedNets))
 
-			r.Method(http.MethodGet, "/", nethttp.NewHandler(getProfiles()))
-			r.Method(http.MethodGet, "/{id}", nethttp.NewHandler(getProfileByID()))
-			r.Method(http.MethodPut, "/{id}", nethttp.NewHandler(addProfile()))
-			r.Method(http.MethodPatch, "/{id}", nethttp.NewHandler(updateProfile()))
-			r.Method(http.MethodDelete, "/{id}", nethttp.NewHandler(deleteProfile()))
+			r.Group(func(r chi.Router) {
+				r.Use(RequireRole(config.RoleReadOnly))
+				r.Method(http.MethodGet, "/", nethttp.NewHandler(getProfiles()))
+				r.Method(http.MethodGet, "/{id}", nethttp.NewHandler(getProfileByID()))
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(RequireRole(config.RoleAdmin))
+				r.Method(http.MethodPut, "/{id}", nethttp.NewHandler(addProfile()))
+				r.Method(http.MethodPatch, "/{id}", nethttp.NewHandler(updateProfile()))
+				r.Method(http.MethodDelete, "/{id}", nethttp.NewHandler(deleteProfile()))
+			})
 		})
 	})
 
@@ -53,12 +71,21 @@ func Handler(auth *config.Authentication, allowedNets []net.IPNet) *web.Service
 		r.Group(func(r chi.Router) {
 			r.Use(AuthMiddleware(auth, allowedNets))
 
-			r.Method(http.MethodGet, "/", nethttp.NewHandler(getImages()))
-			r.Method(http.MethodGet, "/{name}", nethttp.NewHandler(getImageByName()))
-			r.Method(http.MethodPost, "/{name}/import", nethttp.NewHandler(importImage()))
-			r.Method(http.MethodPatch, "/{name}", nethttp.NewHandler(updateImage()))
-			r.Method(http.MethodPost, "/{name}/build", nethttp.NewHandler(buildImage()))
-			r.Method(http.MethodDelete, "/{name}", nethttp.NewHandler(deleteImage()))
+			r.Group(func(r chi.Router) {
+				r.Use(RequireRole(config.RoleReadOnly))
+				r.Method(http.MethodGet, "/", nethttp.NewHandler(getImages()))
+				r.Method(http.MethodGet, "/{name}", nethttp.NewHandler(getImageByName()))
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(RequireRole(config.RoleOperator))
+				r.Method(http.MethodPost, "/{name}/build", nethttp.NewHandler(buildImage()))
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(RequireRole(config.RoleAdmin))
+				r.Method(http.MethodPost, "/{name}/import", nethttp.NewHandler(importImage()))
+				r.Method(http.MethodPatch, "/{name}", nethttp.NewHandler(updateImage()))
+				r.Method(http.MethodDelete, "/{name}", nethttp.NewHandler(deleteImage()))
+			})
 		})
 	})
 
@@ -66,11 +93,18 @@ func Handler(auth *config.Authentication, allowedNets []net.IPNet) *web.Service
 		r.Group(func(r chi.Router) {
 			r.Use(AuthMiddleware(auth, allowedNets))
 
-			r.Method(http.MethodGet, "/", nethttp.NewHandler(getOverlays()))
-			r.Method(http.MethodGet, "/{name}", nethttp.NewHandler(getOverlayByName()))
-			r.Method(http.MethodGet, "/{name}/file", nethttp.NewHandler(getOverlayFile()))
-			r.Method(http.MethodPut, "/{name}", nethttp.NewHandler(createOverlay()))
-			r.Method(http.MethodDelete, "/{name}", nethttp.NewHandler(deleteOverlay()))
+			r.Group(func(r chi.Router) {
+				r.Use(RequireRole(config.RoleReadOnly))
+				r.Method(http.MethodGet, "/", nethttp.NewHandler(getOverlays()))
+				r.Method(http.MethodGet, "/{name}", nethttp.NewHandler(getOverlayByName()))
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(RequireRole(config.RoleAdmin))
+				// rendered templates include secrets, like node tokens
+				r.Method(http.MethodGet, "/{name}/file", nethttp.NewHandler(getOverlayFile()))
+				r.Method(http.MethodPut, "/{name}", nethttp.NewHandler(createOverlay()))
+				r.Method(http.MethodDelete, "/{name}", nethttp.NewHandler(deleteOverlay()))
+			})
 		})
 	})
 
@@ -1,11 +1,14 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
+	"strings"
 
 	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
 
@@ -35,22 +38,100 @@ func AuthMiddleware(auth *config.Authentication, allowedNets []net.IPNet) func(h
 				return
 			}
 
+			actor, role := "anonymous", config.RoleAdmin
 			if auth != nil {
-				username, password, ok := r.BasicAuth()
-				if !ok {
+				var ok bool
+				if actor, role, ok = authenticate(auth, r); !ok {
 					w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
 					http.Error(w, "Unauthorized", http.StatusUnauthorized)
 					return
 				}
+			}
+			ctx := context.WithValue(r.Context(), roleContextKey{}, role)
+			ctx = context.WithValue(ctx, actorContextKey{}, actor)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-				_, err := auth.Authenticate(username, password)
-				if err != nil {
-					w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
-					return
-				}
+type roleContextKey struct{}
+
+type actorContextKey struct{}
+
+// authenticate returns the name and role of the API token or the user
+// in the Authorization header. Token names are prefixed with "token:".
+func authenticate(auth *config.Authentication, r *http.Request) (string, config.Role, bool) {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		token, err := auth.AuthenticateToken(strings.TrimPrefix(header, "Bearer "))
+		if err != nil {
+			return "", "", false
+		}
+		return "token:" + token.Name, token.Role, true
+	}
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return "", "", false
+	}
+	user, err := auth.Authenticate(username, password)
+	if err != nil {
+		return "", "", false
+	}
+	return user.Name, user.Role(), true
+}
+
+// RequireRole rejects requests which were not authenticated by
+// AuthMiddleware with at least the required role.
+func RequireRole(required config.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := r.Context().Value(roleContextKey{}).(config.Role)
+			if !role.Allows(required) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
 			}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+// isAdmin reports whether the request was authenticated with the admin
+// role.
+func isAdmin(ctx context.Context) bool {
+	role, _ := ctx.Value(roleContextKey{}).(config.Role)
+	return role.Allows(config.RoleAdmin)
+}
+
+const redacted = "********"
+
+// redactProfile replaces the IPMI password of the profile, unless the
+// request was authenticated with the admin role. The IPMI settings are
+// copied, so that the node DB isn't changed.
+func redactProfile(ctx context.Context, profile *node.Profile) {
+	if isAdmin(ctx) || profile.Ipmi == nil || profile.Ipmi.Password == "" {
+		return
+	}
+	ipmi := *profile.Ipmi
+	ipmi.Password = redacted
+	profile.Ipmi = &ipmi
+}
+
+// redactFields replaces the IPMI password in the fields, unless the
+// request was authenticated with the admin role.
+func redactFields(ctx context.Context, fields []node.Field) {
+	if isAdmin(ctx) {
+		return
+	}
+	for i := range fields {
+		if fields[i].Field != "Ipmi.Password" {
+			continue
+		}
+		if fields[i].Value != "" {
+			fields[i].Value = redacted
+		}
+		for j := range fields[i].Chain {
+			if fields[i].Chain[j].Value != "" {
+				fields[i].Chain[j].Value = redacted
+			}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+)
+
+func TestAuthorization(t *testing.T) {
+	warewulfd.SetNoDaemon()
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	authData := `
+users:
+- name: admin
+  password hash: $2b$05$5QVWDpiWE7L4SDL9CYdi3O/l6HnbNOLoXgY2sa1bQQ7aSBKdSqvsC
+- name: monitor
+  password hash: $2b$05$5QVWDpiWE7L4SDL9CYdi3O/l6HnbNOLoXgY2sa1bQQ7aSBKdSqvsC
+  role: read-only
+`
+	auth := config.NewAuthentication()
+	assert.NoError(t, auth.ParseFromRaw([]byte(authData)))
+
+	tokenFile := path.Join(env.BaseDir, "api-tokens.conf")
+	tokens, err := config.ReadAPITokens(tokenFile)
+	assert.NoError(t, err)
+	readOnlyToken, err := tokens.Create("portal", config.RoleReadOnly)
+	assert.NoError(t, err)
+	operatorToken, err := tokens.Create("ci", config.RoleOperator)
+	assert.NoError(t, err)
+	revokedToken, err := tokens.Create("old", config.RoleAdmin)
+	assert.NoError(t, err)
+	assert.NoError(t, tokens.Revoke("old"))
+	assert.NoError(t, tokens.Write(tokenFile))
+	auth.SetTokenFile(tokenFile)
+
+	allowedNets := []net.IPNet{
+		{
+			IP:   net.IPv4(127, 0, 0, 0),
+			Mask: net.CIDRMask(8, 32),
+		},
+	}
+	srv := httptest.NewServer(Handler(auth, allowedNets))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		method string
+		url    string
+		user   string
+		token  string
+		status int
+	}{
+		"admin user can delete": {
+			method: http.MethodDelete, url: "/api/nodes/node1", user: "admin",
+			status: http.StatusOK,
+		},
+		"read-only user can read": {
+			method: http.MethodGet, url: "/api/profiles", user: "monitor",
+			status: http.StatusOK,
+		},
+		"read-only user can't delete": {
+			method: http.MethodDelete, url: "/api/profiles/none", user: "monitor",
+			status: http.StatusForbidden,
+		},
+		"read-only token can read": {
+			method: http.MethodGet, url: "/api/nodes", token: readOnlyToken,
+			status: http.StatusOK,
+		},
+		"read-only token can't delete": {
+			method: http.MethodDelete, url: "/api/nodes/node1", token: readOnlyToken,
+			status: http.StatusForbidden,
+		},
+		"read-only token can't read raw nodes": {
+			method: http.MethodGet, url: "/api/nodes/node1/raw", token: readOnlyToken,
+			status: http.StatusForbidden,
+		},
+		"operator token can't read raw nodes": {
+			method: http.MethodGet, url: "/api/nodes/node1/raw", token: operatorToken,
+			status: http.StatusForbidden,
+		},
+		"read-only token can't read overlay files": {
+			method: http.MethodGet, url: "/api/overlays/wwinit/file?path=warewulf/token.ww&render=node1", token: readOnlyToken,
+			status: http.StatusForbidden,
+		},
+		"operator token can't delete": {
+			method: http.MethodDelete, url: "/api/images/none", token: operatorToken,
+			status: http.StatusForbidden,
+		},
+		"revoked token": {
+			method: http.MethodGet, url: "/api/nodes", token: revokedToken,
+			status: http.StatusUnauthorized,
+		},
+		"unknown token": {
+			method: http.MethodGet, url: "/api/nodes", token: "unknown",
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+tt.url, nil)
+			assert.NoError(t, err)
+			if tt.user != "" {
+				req.SetBasicAuth(tt.user, "admin")
+			}
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			resp, err := http.DefaultTransport.RoundTrip(req)
+			assert.NoError(t, err)
+			assert.NoError(t, resp.Body.Close())
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+
+	entries, err := node.ReadAuditLog()
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "admin", entries[0].Actor)
+		assert.Equal(t, node.AuditSourceAPI, entries[0].Source)
+		assert.Equal(t, "node1", entries[0].Entity)
+		assert.Equal(t, "delete", entries[0].Action)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	warewulfd.SetNoDaemon()
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default:
+    ipmi:
+      password: profile-secret
+nodes:
+  n1:
+    profiles:
+    - default
+    ipmi:
+      username: admin
+      password: node-secret
+`)
+
+	auth := config.NewAuthentication()
+	assert.NoError(t, auth.ParseFromRaw([]byte(`
+users:
+- name: admin
+  password hash: $2b$05$5QVWDpiWE7L4SDL9CYdi3O/l6HnbNOLoXgY2sa1bQQ7aSBKdSqvsC
+- name: monitor
+  password hash: $2b$05$5QVWDpiWE7L4SDL9CYdi3O/l6HnbNOLoXgY2sa1bQQ7aSBKdSqvsC
+  role: read-only
+`)))
+	allowedNets := []net.IPNet{
+		{
+			IP:   net.IPv4(127, 0, 0, 0),
+			Mask: net.CIDRMask(8, 32),
+		},
+	}
+	srv := httptest.NewServer(Handler(auth, allowedNets))
+	defer srv.Close()
+
+	get := func(user, url string) string {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+url, nil)
+		assert.NoError(t, err)
+		req.SetBasicAuth(user, "admin")
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return string(body)
+	}
+
+	for _, url := range []string{"/api/nodes", "/api/nodes/n1", "/api/nodes/n1/fields", "/api/profiles", "/api/profiles/default"} {
+		t.Run(url, func(t *testing.T) {
+			body := get("monitor", url)
+			assert.NotContains(t, body, "secret")
+			assert.Contains(t, body, "********")
+
+			body = get("admin", url)
+			assert.Contains(t, body, "-secret")
+		})
+	}
+
+	// the node DB is not changed by redacting
+	body := get("admin", "/api/nodes/n1/raw")
+	assert.Contains(t, body, "node-secret")
+}
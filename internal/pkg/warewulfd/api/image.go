@@ -16,10 +16,11 @@ import (
 )
 
 type Image struct {
-	Kernels   []string `json:"kernels"`
-	Size      int      `json:"size"`
-	BuildTime int64    `json:"buildtime"`
-	Writable  bool     `json:"writable"`
+	Kernels    []string        `json:"kernels"`
+	Size       int             `json:"size"`
+	BuildTime  int64           `json:"buildtime"`
+	Writable   bool            `json:"writable"`
+	Provenance *image.Metadata `json:"provenance,omitempty"`
 }
 
 func NewImage(name string) *Image {
@@ -36,6 +37,11 @@ func NewImage(name string) *Image {
 		c.BuildTime = modTime.Unix()
 	}
 	c.Writable = image.IsWriteAble(name)
+	if md, err := image.ReadMetadata(name); err != nil {
+		wwlog.Warn("could not read metadata of image %s: %s", name, err)
+	} else if md.Source != "" || !md.Imported.IsZero() || len(md.History) > 0 {
+		c.Provenance = md
+	}
 	return c
 }
 
@@ -86,11 +92,12 @@ func importImage() usecase.Interactor {
 		NoHttps  bool   `json:"nohttps" default:"false" description:"Use http, rather than https, to communicate with the registry, default:'false'"`
 		User     string `json:"user" description:"Username for the registry, if needed"`
 		Password string `json:"password" description:"Password for the registry, if needed"`
+		Verify   bool   `json:"verify" default:"false" description:"Verify the signatures of the image with the signature policy configured on the server, default:'false'"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, input importImageInput, output *Image) error {
-		wwlog.Debug("api.importImage(Name:%v, URI:%v, NoHttps:%v, User:%v, Password:[redacted])",
-			input.Name, input.URI, input.NoHttps, input.User)
+		wwlog.Debug("api.importImage(Name:%v, URI:%v, NoHttps:%v, User:%v, Password:[redacted], Verify:%v)",
+			input.Name, input.URI, input.NoHttps, input.User, input.Verify)
 		if !strings.HasPrefix(input.URI, "docker://") {
 			return status.Wrap(fmt.Errorf("missing docker:// prefix: %s", input.URI), status.InvalidArgument)
 		}
@@ -102,9 +109,12 @@ func importImage() usecase.Interactor {
 		if sctx, err := image_api.GetSystemContext(input.NoHttps, input.User, input.Password, ""); err != nil {
 			return err
 		} else {
-			if err := image.ImportDocker(input.URI, input.Name, sctx); err != nil {
+			if err := image.ImportDocker(input.URI, input.Name, sctx, input.Verify); err != nil {
 				return err
 			}
+			if err := image.UpdateMetadata(input.Name, func(md *image.Metadata) { md.User = auditActor(ctx).Name }); err != nil {
+				wwlog.Warn("could not record the importing user of image %s: %s", input.Name, err)
+			}
 			*output = *NewImage(input.Name)
 			return nil
 		}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path"
 	"testing"
 
@@ -80,6 +81,38 @@ users:
 		assert.JSONEq(t, `{"kernels":[] ,"size":0, "buildtime":0, "writable":true}`, string(body))
 	})
 
+	t.Run("test get image provenance", func(t *testing.T) {
+		env.WriteFile(path.Join(testenv.WWChrootdir, "provenance-image/rootfs/file"), `test`)
+		env.WriteFile(path.Join(testenv.WWChrootdir, "provenance-image/metadata.json"), `{
+  "source": "docker://ghcr.io/warewulf/warewulf-rockylinux:9",
+  "digest": "sha256:0123",
+  "platform": "linux/amd64",
+  "imported": "2024-01-02T03:04:05Z",
+  "user": "admin",
+  "history": [{"time": "2024-01-02T04:05:06Z", "user": "admin", "command": ["dnf", "-y", "update"], "exitcode": 0}]
+}`)
+		defer func() {
+			assert.NoError(t, os.RemoveAll(env.GetPath(path.Join(testenv.WWChrootdir, "provenance-image"))))
+		}()
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/images/provenance-image", nil)
+		assert.NoError(t, err)
+		req.SetBasicAuth("admin", "admin")
+
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, resp.Body.Close())
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"kernels":[] ,"size":0, "buildtime":0, "writable":true, "provenance": {
+			"source": "docker://ghcr.io/warewulf/warewulf-rockylinux:9",
+			"digest": "sha256:0123",
+			"platform": "linux/amd64",
+			"imported": "2024-01-02T03:04:05Z",
+			"user": "admin",
+			"history": [{"time": "2024-01-02T04:05:06Z", "user": "admin", "command": ["dnf", "-y", "update"], "exitcode": 0}]}}`, string(body))
+	})
+
 	t.Run("test build image", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/images/test-image/build?force=true&default=true", nil)
 		assert.NoError(t, err)
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime"
 	"sort"
@@ -10,6 +11,7 @@ import (
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
 	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/inventory"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
@@ -17,8 +19,19 @@ import (
 )
 
 func getNodes() usecase.Interactor {
-	u := usecase.NewInteractor(func(ctx context.Context, _ struct{}, output *map[string]*node.Node) error {
-		wwlog.Debug("api.getNodes()")
+	type getNodesInput struct {
+		Select string `query:"select" description:"Selector expression, e.g., tag.gpu=a100 && cluster=hpc2"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input getNodesInput, output *map[string]*node.Node) error {
+		wwlog.Debug("api.getNodes(Select:%v)", input.Select)
+		var selector *node.Selector
+		if input.Select != "" {
+			var err error
+			if selector, err = node.ParseSelector(input.Select); err != nil {
+				return status.Wrap(err, status.InvalidArgument)
+			}
+		}
 		if registry, err := node.New(); err != nil {
 			return err
 		} else {
@@ -26,7 +39,11 @@ func getNodes() usecase.Interactor {
 			if nodeList, err := registry.FindAllNodes(); err != nil {
 				return err
 			} else {
+				if selector != nil {
+					nodeList = registry.SelectNodes(nodeList, selector, warewulfd.GetNodeState)
+				}
 				for i := range nodeList {
+					redactProfile(ctx, &nodeList[i].Profile)
 					nodeMap[nodeList[i].Id()] = &nodeList[i]
 				}
 				*output = nodeMap
@@ -35,8 +52,9 @@ func getNodes() usecase.Interactor {
 		}
 	})
 	u.SetTitle("Get nodes")
-	u.SetDescription("Get all nodes, including field values from associated profiles.")
+	u.SetDescription("Get all nodes, or the nodes matching a selector expression, including field values from associated profiles.")
 	u.SetTags("Node")
+	u.SetExpectedErrors(status.InvalidArgument)
 	return u
 }
 
@@ -53,6 +71,7 @@ func getNodeByID() usecase.Interactor {
 			if node_, err := registry.GetNode(input.ID); err != nil {
 				return status.Wrap(fmt.Errorf("node not found: %v (%v)", input.ID, err), status.NotFound)
 			} else {
+				redactProfile(ctx, &node_.Profile)
 				*output = node_
 				return nil
 			}
@@ -100,16 +119,40 @@ func getNodeFields() usecase.Interactor {
 		if registry, err := node.New(); err != nil {
 			return err
 		} else {
-			if n, fields, err := registry.MergeNode(input.ID); err != nil {
+			if fields, err := registry.ExplainNode(input.ID); err != nil {
 				return status.Wrap(fmt.Errorf("node not found: %v (%v)", input.ID, err), status.NotFound)
 			} else {
-				*output = fields.List(n)
+				redactFields(ctx, fields)
+				*output = fields
 				return nil
 			}
 		}
 	})
 	u.SetTitle("Get node fields")
-	u.SetDescription("Get the fields and values of a node, indicating which profiles each field originates from.")
+	u.SetDescription("Get the fields and values of a node, indicating which profiles each field originates from. The chain of each field lists the value set by each profile, the node, and its networks, in merge order, with overridden values and negated list entries.")
+	u.SetTags("Node")
+	u.SetExpectedErrors(status.NotFound)
+	return u
+}
+
+func getNodeInventory() usecase.Interactor {
+	type getNodeInventoryInput struct {
+		ID string `path:"id" required:"true" description:"ID of node from which to retrieve the hardware inventory"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input getNodeInventoryInput, output *inventory.Record) error {
+		wwlog.Debug("api.getNodeInventory(ID:%v)", input.ID)
+		if record, err := inventory.Read(input.ID); errors.Is(err, inventory.ErrNotFound) {
+			return status.Wrap(fmt.Errorf("no inventory for node: %v", input.ID), status.NotFound)
+		} else if err != nil {
+			return err
+		} else {
+			*output = *record
+			return nil
+		}
+	})
+	u.SetTitle("Get node hardware inventory")
+	u.SetDescription("Get the hardware inventory most recently reported by a node, and the previous inventory if its hardware has changed.")
 	u.SetTags("Node")
 	u.SetExpectedErrors(status.NotFound)
 	return u
@@ -149,7 +192,10 @@ func addNode() usecase.Interactor {
 				}
 			}
 			registry.Nodes[input.ID] = &input.Node
-			if err := registry.Persist(); err != nil {
+			if err := registry.AllocateIPs(input.ID); err != nil {
+				return status.Wrap(err, status.InvalidArgument)
+			}
+			if err := persist(ctx, &registry); err != nil {
 				return err
 			}
 			warewulfd.Reload()
@@ -180,7 +226,7 @@ func deleteNode() usecase.Interactor {
 			if err := registry.DelNode(input.ID); err != nil {
 				return err
 			}
-			if err := registry.Persist(); err != nil {
+			if err := persist(ctx, &registry); err != nil {
 				return err
 			}
 			warewulfd.Reload()
@@ -229,7 +275,7 @@ func updateNode() usecase.Interactor {
 				if err := mergo.MergeWithOverwrite(nodePtr, &input.Node); err != nil {
 					return err
 				}
-				if err := registry.Persist(); err != nil {
+				if err := persist(ctx, &registry); err != nil {
 					return err
 				}
 				warewulfd.Reload()
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/inventory"
 	"github.com/warewulf/warewulf/internal/pkg/testenv"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
 )
@@ -67,6 +68,30 @@ func TestNodeAPI(t *testing.T) {
 		assert.JSONEq(t, `{"node1": {}, "test": {"kernel": {"version": "v1.0.0", "args": ["kernel-args"]}}}`, string(body))
 	})
 
+	t.Run("read nodes matching a selector", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/nodes?select=name%3Dtest", nil)
+		assert.NoError(t, err)
+
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		assert.JSONEq(t, `{"test": {"kernel": {"version": "v1.0.0", "args": ["kernel-args"]}}}`, string(body))
+	})
+
+	t.Run("read nodes with an invalid selector", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/nodes?select=color%3Dred", nil)
+		assert.NoError(t, err)
+
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
 	t.Run("get one specific node", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/nodes/test", nil)
 		assert.NoError(t, err)
@@ -132,6 +157,46 @@ func TestNodeAPI(t *testing.T) {
 		assert.JSONEq(t, `{"kernel": {"version": "v1.0.1-newversion", "args": ["kernel-args"]}}`, string(body))
 	})
 
+	t.Run("get the fields of a node", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/nodes/test/fields", nil)
+		assert.NoError(t, err)
+
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		assert.JSONEq(t, `[
+  {"Field": "Kernel.Version", "Source": "", "Value": "v1.0.1-newversion", "Chain": [{"Source": "test", "Value": "v1.0.1-newversion"}]},
+  {"Field": "Kernel.Args", "Source": "", "Value": "kernel-args", "Chain": [{"Source": "test", "Value": "kernel-args"}]}
+]`, string(body))
+	})
+
+	t.Run("get the inventory of a node", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/nodes/test/inventory", nil)
+		assert.NoError(t, err)
+
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		_, err = inventory.Update("test", &inventory.Inventory{CPU: inventory.CPU{Model: "Xeon", Sockets: 2}})
+		assert.NoError(t, err)
+
+		resp, err = http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.JSONEq(t, `{"node": "test", "current": {"collected": "0001-01-01T00:00:00Z", "system": {}, "firmware": {}, "cpu": {"model": "Xeon", "sockets": 2}, "memory": {}}}`, string(body))
+	})
+
 	t.Run("test build all nodes overlays", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/nodes/overlays/build", nil)
 		assert.NoError(t, err)
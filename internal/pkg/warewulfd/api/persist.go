@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/swaggest/usecase/status"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+// auditActor returns the API user which made the request, to be
+// recorded in the audit log.
+func auditActor(ctx context.Context) node.AuditActor {
+	actor, _ := ctx.Value(actorContextKey{}).(string)
+	if actor == "" {
+		actor = "anonymous"
+	}
+	return node.AuditActor{Name: actor, Source: node.AuditSourceAPI}
+}
+
+// persist writes registry on behalf of the API user which made the
+// request. A concurrent change of the node configuration is reported
+// as 409 Conflict, and problems which prevent it from being written
+// with strict validation as 400 Bad Request.
+func persist(ctx context.Context, registry *node.NodesYaml) error {
+	if err := registry.PersistAs(auditActor(ctx)); err != nil {
+		var validationErr *node.ValidationError
+		if errors.Is(err, node.ErrConflict) {
+			return status.Wrap(err, status.Aborted)
+		} else if errors.As(err, &validationErr) {
+			var problems []string
+			for _, problem := range validationErr.Problems {
+				problems = append(problems, problem.String())
+			}
+			return status.Wrap(fmt.Errorf("%w: %s", err, strings.Join(problems, "; ")), status.InvalidArgument)
+		}
+		return err
+	}
+	return nil
+}
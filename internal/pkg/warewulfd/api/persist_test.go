@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/swaggest/usecase/status"
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func TestPersistConflict(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles: {}
+nodes:
+  n1: {}`)
+
+	registry, err := node.New()
+	assert.NoError(t, err)
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles: {}
+nodes:
+  n1: {}
+  n2: {}`)
+
+	registry.Nodes["n1"].Comment = "stale"
+	err = persist(context.Background(), &registry)
+	assert.ErrorIs(t, err, node.ErrConflict)
+	assert.ErrorIs(t, err, status.Aborted)
+}
+
+func TestPersistInvalid(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles: {}
+nodes:
+  n1: {}`)
+	conf := warewulfconf.Get()
+	strict := true
+	conf.NodeDB.StrictP = &strict
+
+	registry, err := node.New()
+	assert.NoError(t, err)
+	registry.Nodes["n1"].Profiles = []string{"missing"}
+	err = persist(context.Background(), &registry)
+	var validationErr *node.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.ErrorIs(t, err, status.InvalidArgument)
+	assert.Contains(t, err.Error(), "profile missing does not exist")
+}
@@ -20,7 +20,13 @@ func getProfiles() usecase.Interactor {
 		if registry, err := node.New(); err != nil {
 			return err
 		} else {
-			*output = registry.NodeProfiles
+			profiles := make(map[string]*node.Profile)
+			for id, profile := range registry.NodeProfiles {
+				profile := *profile
+				redactProfile(ctx, &profile)
+				profiles[id] = &profile
+			}
+			*output = profiles
 			return nil
 		}
 	})
@@ -43,6 +49,7 @@ func getProfileByID() usecase.Interactor {
 			if profile, err := registry.GetProfile(input.ID); err != nil {
 				return status.Wrap(fmt.Errorf("profile not found: %v (%v)", input.ID, err), status.NotFound)
 			} else {
+				redactProfile(ctx, &profile)
 				*output = profile
 				return nil
 			}
@@ -85,7 +92,7 @@ func addProfile() usecase.Interactor {
 				}
 			}
 			registry.NodeProfiles[input.ID] = &input.Profile
-			if err := registry.Persist(); err != nil {
+			if err := persist(ctx, &registry); err != nil {
 				return err
 			}
 			warewulfd.Reload()
@@ -135,7 +142,7 @@ func updateProfile() usecase.Interactor {
 				if err := mergo.MergeWithOverwrite(profilePtr, &input.Profile); err != nil {
 					return err
 				}
-				if err := registry.Persist(); err != nil {
+				if err := persist(ctx, &registry); err != nil {
 					return err
 				}
 				warewulfd.Reload()
@@ -177,7 +184,7 @@ func deleteProfile() usecase.Interactor {
 				return err
 			}
 
-			if err := registry.Persist(); err != nil {
+			if err := persist(ctx, &registry); err != nil {
 				return err
 			}
 
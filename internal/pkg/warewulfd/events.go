@@ -0,0 +1,111 @@
+package warewulfd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+const (
+	StatusEventType    = "status"
+	DiscoveryEventType = "discovery"
+)
+
+// StatusEvent is sent to event stream subscribers every time the status
+// of a node is updated or a node is discovered.
+type StatusEvent struct {
+	Type   string `json:"type"`
+	Hwaddr string `json:"hwaddr,omitempty"`
+	NodeStatus
+}
+
+// eventBufferLength is the number of events buffered for each
+// subscriber. Events for slow subscribers are dropped rather than
+// blocking provisioning.
+const eventBufferLength = 256
+
+// eventKeepalive is how often a comment is sent to idle subscribers.
+var eventKeepalive = 15 * time.Second
+
+var (
+	eventLock        sync.Mutex
+	eventSubscribers = make(map[chan StatusEvent]struct{})
+)
+
+func subscribeStatusEvents() chan StatusEvent {
+	eventLock.Lock()
+	defer eventLock.Unlock()
+	ch := make(chan StatusEvent, eventBufferLength)
+	eventSubscribers[ch] = struct{}{}
+	return ch
+}
+
+func unsubscribeStatusEvents(ch chan StatusEvent) {
+	eventLock.Lock()
+	defer eventLock.Unlock()
+	delete(eventSubscribers, ch)
+}
+
+func publishStatusEvent(event StatusEvent) {
+	eventLock.Lock()
+	defer eventLock.Unlock()
+	for ch := range eventSubscribers {
+		select {
+		case ch <- event:
+		default:
+			wwlog.Debug("Dropping status event for slow subscriber: %s", event.NodeName)
+		}
+	}
+}
+
+// StatusEventsSend streams status events to the client as server-sent
+// events until the client disconnects.
+func StatusEventsSend(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		wwlog.Error("Streaming is not supported by the response writer")
+		return
+	}
+
+	events := subscribeStatusEvents()
+	defer unsubscribeStatusEvents(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	wwlog.Verbose("Status event subscriber connected: %s", req.RemoteAddr)
+	defer wwlog.Verbose("Status event subscriber disconnected: %s", req.RemoteAddr)
+
+	keepalive := time.NewTicker(eventKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				wwlog.Warn("Could not marshal status event: %s", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package warewulfd
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_StatusEventsSend(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	resetStatus()
+	defer resetStatus()
+	env.WriteFile("etc/warewulf/nodes.conf", `nodes:
+  n1:
+    discoverable: true
+    network devices:
+      default: {}
+`)
+	assert.NoError(t, LoadNodeDB())
+	assert.NoError(t, LoadNodeStatus())
+
+	server := httptest.NewServer(http.HandlerFunc(StatusEventsSend))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	_, err = GetNodeOrSetDiscoverable(node.Machine{Hwaddr: "00:00:00:00:00:01"}, "")
+	assert.NoError(t, err)
+	updateStatus("n1", "IPXE", "BAD_ASSET", "10.0.0.1")
+
+	var events []StatusEvent
+	var eventTypes []string
+	scanner := bufio.NewScanner(resp.Body)
+	for len(events) < 2 && scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "event: ") {
+			eventTypes = append(eventTypes, strings.TrimPrefix(line, "event: "))
+		} else if strings.HasPrefix(line, "data: ") {
+			var event StatusEvent
+			assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+			events = append(events, event)
+		}
+	}
+
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, []string{DiscoveryEventType, StatusEventType}, eventTypes)
+		assert.Equal(t, DiscoveryEventType, events[0].Type)
+		assert.Equal(t, "n1", events[0].NodeName)
+		assert.Equal(t, "00:00:00:00:00:01", events[0].Hwaddr)
+		assert.Equal(t, StatusEventType, events[1].Type)
+		assert.Equal(t, "n1", events[1].NodeName)
+		assert.Equal(t, "IPXE", events[1].Stage)
+		assert.Equal(t, "BAD_ASSET", events[1].Sent)
+	}
+}
@@ -0,0 +1,74 @@
+package warewulfd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/inventory"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// maxInventorySize limits the size of an inventory posted by a node.
+const maxInventorySize = 1 << 20
+
+/*
+InventoryReceive stores the hardware inventory which wwclient posts to
+/inventory/HWADDR. The node is authorized like a request for its runtime
+overlay: with its asset key and node token, from a privileged port if
+warewulf is secure.
+*/
+func InventoryReceive(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	rinfo, err := parseReq(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		wwlog.ErrorExc(err, "Bad status")
+		return
+	}
+	if warewulfconf.Get().Warewulf.Secure() && rinfo.remoteport >= 1024 {
+		wwlog.Denied("Non-privileged port: %s", req.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	remoteNode, err := GetNodeByHwaddr(rinfo.hwaddr)
+	if errors.Is(err, node.ErrNotFound) {
+		wwlog.Error("%s (unknown node sent inventory)", rinfo.hwaddr)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if remoteNode.AssetKey != "" && remoteNode.AssetKey != rinfo.assetkey {
+		wwlog.Denied("incorrect asset key: node %s: %s", remoteNode.Id(), rinfo.assetkey)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if !node.VerifyToken(remoteNode.Id(), rinfo.token) {
+		wwlog.Denied("incorrect token: node %s", remoteNode.Id())
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	inv := new(inventory.Inventory)
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxInventorySize)).Decode(inv); err != nil {
+		wwlog.Error("invalid inventory from node %s: %s", remoteNode.Id(), err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	changes, err := inventory.Update(remoteNode.Id(), inv)
+	if err != nil {
+		wwlog.Error("could not store the inventory of node %s: %s", remoteNode.Id(), err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if len(changes) > 0 {
+		wwlog.Warn("hardware of node %s changed: %d values differ from its previous inventory", remoteNode.Id(), len(changes))
+	}
+	wwlog.Info("received inventory from node %s", remoteNode.Id())
+	w.WriteHeader(http.StatusNoContent)
+}
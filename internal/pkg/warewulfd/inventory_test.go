@@ -0,0 +1,67 @@
+package warewulfd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/inventory"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_InventoryReceive(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	env.WriteFile("etc/warewulf/nodes.conf", `nodes:
+  n1:
+    network devices:
+      default:
+        hwaddr: 00:00:00:ff:ff:ff`)
+	assert.NoError(t, LoadNodeDB())
+
+	conf := warewulfconf.Get()
+	secureFalse := false
+	conf.Warewulf.SecureP = &secureFalse
+
+	token, err := node.Token("n1")
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		method string
+		url    string
+		auth   string
+		body   string
+		status int
+	}{
+		"wrong method": {method: http.MethodGet, url: "/inventory/00:00:00:ff:ff:ff", auth: "Bearer " + token, status: http.StatusMethodNotAllowed},
+		"unknown node": {method: http.MethodPost, url: "/inventory/00:00:00:00:00:01", auth: "Bearer " + token, body: `{}`, status: http.StatusNotFound},
+		"no token":     {method: http.MethodPost, url: "/inventory/00:00:00:ff:ff:ff", body: `{}`, status: http.StatusUnauthorized},
+		"invalid body": {method: http.MethodPost, url: "/inventory/00:00:00:ff:ff:ff", auth: "Bearer " + token, body: `{`, status: http.StatusBadRequest},
+		"inventory":    {method: http.MethodPost, url: "/inventory/00:00:00:ff:ff:ff", auth: "Bearer " + token, body: `{"cpu": {"model": "Xeon", "sockets": 2}}`, status: http.StatusNoContent},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.RemoteAddr = "10.10.10.10:987"
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+			InventoryReceive(w, req)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+
+	record, err := inventory.Read("n1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Xeon", record.Current.CPU.Model)
+	assert.Equal(t, 2, record.Current.CPU.Sockets)
+}
@@ -1,10 +1,15 @@
 package warewulfd
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/warewulf/warewulf/internal/pkg/discovery"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
@@ -13,6 +18,26 @@ type nodeDB struct {
 	lock     sync.RWMutex
 	NodeInfo map[string]string
 	yml      node.NodesYaml
+	// nodes holds the merged nodes, hashes the hash of each node as
+	// of its last merge, and changed when its configuration last
+	// changed. anyChanged is when any node was last added, updated,
+	// or deleted.
+	nodes      map[string]node.Node
+	hashes     map[string]string
+	changed    map[string]time.Time
+	anyChanged time.Time
+}
+
+// nodeDBChanges lists the nodes which were added, updated, and deleted
+// when the node DB was loaded.
+type nodeDBChanges struct {
+	added   []string
+	updated []string
+	deleted []string
+}
+
+func (changes nodeDBChanges) empty() bool {
+	return len(changes.added) == 0 && len(changes.updated) == 0 && len(changes.deleted) == 0
 }
 
 var (
@@ -23,96 +48,302 @@ func LoadNodeDB() error {
 
 	db.lock.Lock()
 	defer db.lock.Unlock()
-	return loadNodeDB()
+	_, err := loadNodeDB()
+	return err
 }
 
-func loadNodeDB() (err error) {
-	TmpMap := make(map[string]string)
-
-	db.yml, err = node.New()
+/*
+loadNodeDB reads the node configuration and merges only the nodes which
+were added or changed since it was last loaded, as detected by their
+hashes. The hardware address map is only updated for the hardware
+addresses of nodes which were added, changed, or deleted.
+*/
+func loadNodeDB() (changes nodeDBChanges, err error) {
+	registry, err := node.New()
 	if err != nil {
 		return
 	}
+	modTime := time.Now()
+	if store, err := node.OpenStore(); err == nil {
+		if storeModTime, err := store.ModTime(); err == nil {
+			modTime = storeModTime
+		}
+	}
 
-	nodes, err := db.yml.FindAllNodes()
-	if err != nil {
-		return err
+	hashes := registry.NodeHashes()
+	nodes := make(map[string]node.Node, len(hashes))
+	changed := make(map[string]time.Time, len(hashes))
+	for id, hash := range hashes {
+		if prevHash, ok := db.hashes[id]; ok && prevHash == hash {
+			nodes[id] = db.nodes[id]
+			changed[id] = db.changed[id]
+			continue
+		}
+		if nodes[id], err = registry.GetNode(id); err != nil {
+			return changes, err
+		}
+		changed[id] = modTime
+		if _, ok := db.hashes[id]; ok {
+			changes.updated = append(changes.updated, id)
+		} else {
+			changes.added = append(changes.added, id)
+		}
+	}
+	for id := range db.hashes {
+		if _, ok := hashes[id]; !ok {
+			changes.deleted = append(changes.deleted, id)
+		}
+	}
+	sort.Strings(changes.added)
+	sort.Strings(changes.updated)
+	sort.Strings(changes.deleted)
+
+	if db.NodeInfo == nil {
+		db.NodeInfo = make(map[string]string)
+	}
+	affected := make(map[string]bool)
+	for _, ids := range [][]string{changes.updated, changes.deleted} {
+		for _, id := range ids {
+			for _, netdev := range db.nodes[id].NetDevs {
+				affected[strings.ToLower(netdev.Hwaddr)] = true
+			}
+		}
+	}
+	for _, ids := range [][]string{changes.added, changes.updated} {
+		for _, id := range ids {
+			for _, netdev := range nodes[id].NetDevs {
+				affected[strings.ToLower(netdev.Hwaddr)] = true
+			}
+		}
+	}
+	if len(affected) > 0 {
+		rebuildNodeInfo(nodes, affected)
 	}
 
+	db.yml = registry
+	db.nodes = nodes
+	db.hashes = hashes
+	db.changed = changed
+	if !changes.empty() {
+		db.anyChanged = modTime
+		wwlog.Verbose("node DB loaded: added %v, updated %v, deleted %v", changes.added, changes.updated, changes.deleted)
+	}
+	return changes, nil
+}
+
+/*
+rebuildNodeInfo maps each of the affected hardware addresses to the
+node which has it, as if the map were built from all nodes. A hardware
+address shared by several nodes maps to the last of them in the order
+of FindAllNodes, and discoverable nodes are not mapped.
+*/
+func rebuildNodeInfo(nodes map[string]node.Node, affected map[string]bool) {
+	for hwaddr := range affected {
+		delete(db.NodeInfo, hwaddr)
+	}
+	ordered := make([]node.Node, 0, len(nodes))
 	for _, n := range nodes {
+		ordered = append(ordered, n)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].ClusterName != ordered[j].ClusterName {
+			return ordered[i].ClusterName < ordered[j].ClusterName
+		}
+		return ordered[i].Id() < ordered[j].Id()
+	})
+	for _, n := range ordered {
 		if n.Discoverable.Bool() {
 			continue
 		}
 		for _, netdev := range n.NetDevs {
 			hwaddr := strings.ToLower(netdev.Hwaddr)
-			TmpMap[hwaddr] = n.Id()
+			if affected[hwaddr] {
+				db.NodeInfo[hwaddr] = n.Id()
+			}
 		}
 	}
+}
+
+// nodeChanged returns when the configuration of the node id last
+// changed, if it is loaded.
+func nodeChanged(id string) (changed time.Time, ok bool) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	changed, ok = db.changed[id]
+	return
+}
 
-	db.NodeInfo = TmpMap
-	return nil
+// anyNodeChanged returns when any node was last added, updated, or
+// deleted.
+func anyNodeChanged() time.Time {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	return db.anyChanged
 }
 
-func GetNodeOrSetDiscoverable(hwaddr string) (node.Node, error) {
+/*
+GetNodeByHwaddr returns the node with the hardware address, without
+discovering a node if there is none, in which case it returns
+node.ErrNotFound.
+*/
+func GetNodeByHwaddr(hwaddr string) (node.Node, error) {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
+	if nId, ok := db.NodeInfo[hwaddr]; ok {
+		if n, ok := db.nodes[nId]; ok {
+			return n, nil
+		}
+	}
+	return node.Node{}, node.ErrNotFound
+}
+
+/*
+GetNodeOrSetDiscoverable returns the node with the hardware address of
+the machine. If there is none, the machine is discovered as the
+discoverable node whose rules it matches, or else parked in the
+discovery queue, in which case node.ErrNoUnconfigured is returned.
+Machines without a valid hardware address are neither discovered nor
+queued.
+
+If the machine would match a node with a switch port rule once it
+reports its switch port, that node is returned, without discovering
+it, with node.ErrDiscoveryDeferred.
+*/
+func GetNodeOrSetDiscoverable(machine node.Machine, ipaddr string) (node.Node, error) {
+	if _, err := net.ParseMAC(machine.Hwaddr); err != nil {
+		wwlog.Denied("%q (invalid hardware address)", machine.Hwaddr)
+		return node.EmptyNode(), node.ErrNoUnconfigured
+	}
+	n, queue, err := discoverNode(machine)
+	if queue {
+		// the queue is written without holding the node DB lock
+		queueMachine(machine, ipaddr)
+	}
+	return n, err
+}
+
+// discoverNode returns the node with the hardware address of the
+// machine, or discovers it. If no discoverable node matches the machine,
+// queue is set.
+func discoverNode(machine node.Machine) (n node.Node, queue bool, err error) {
+	hwaddr := machine.Hwaddr
+	db.lock.RLock()
+	if nId, ok := db.NodeInfo[hwaddr]; ok {
+		n, ok := db.nodes[nId]
+		db.lock.RUnlock()
+		if ok {
+			return n, false, nil
+		}
+	} else {
+		db.lock.RUnlock()
+	}
+
 	// NOTE: since discoverable nodes will write an updated DB to file and then
-	// reload, it is not enough to lock individual reads from the DB
-	// to ensure the condition on which the node is updated is still satisfied
+	// reload, the DB is locked for writing until the node is discovered, to
+	// ensure the condition on which the node is updated is still satisfied
 	// after the DB is read back in.
-
-	nId, ok := db.NodeInfo[hwaddr]
-	if ok {
-		return db.yml.GetNode(nId)
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	if nId, ok := db.NodeInfo[hwaddr]; ok {
+		if n, ok := db.nodes[nId]; ok {
+			return n, false, nil
+		}
+		n, err := db.yml.GetNode(nId)
+		return n, false, err
 	}
 
 	// If we failed to find a node, let's see if we can add one...
 	wwlog.Warn("node not configured: %s", hwaddr)
 
-	nodeFound, netdev, err := db.yml.FindDiscoverableNode()
-	if err != nil {
-		// NOTE: this is taken as there is no discoverable node, so return the
-		// empty one
-		return nodeFound, err
+	if pending, err := discovery.Get(hwaddr); err == nil && pending.Rejected {
+		wwlog.Denied("%s (machine rejected for discovery)", hwaddr)
+		return node.EmptyNode(), false, node.ErrNoUnconfigured
+	}
+	nodeFound, netdev, err := db.yml.FindDiscoverableNode(machine)
+	if errors.Is(err, node.ErrNoUnconfigured) {
+		return nodeFound, true, err
+	} else if errors.Is(err, node.ErrDiscoveryDeferred) {
+		return nodeFound, false, err
+	} else if err != nil {
+		return nodeFound, false, err
 	}
 	// update node
 	wwlog.Debug("discovered node: %s netdev: %s", nodeFound.Id(), netdev)
-	nodeChanges, _ := db.yml.GetNodeOnly(nodeFound.Id()) // ignore error as nodeId is in db
-	if _, ok := nodeChanges.NetDevs[netdev]; !ok {
-		nodeChanges.NetDevs = make(map[string]*node.NetDev)
-		nodeChanges.NetDevs[netdev] = new(node.NetDev)
-	}
-	wwlog.Debug("node: %v", nodeChanges)
-	nodeChanges.NetDevs[netdev].Hwaddr = hwaddr
-	nodeChanges.Discoverable = "UNDEF"
-	err = db.yml.SetNode(nodeFound.Id(), nodeChanges)
-	if err != nil {
-		return nodeFound, err
+	if _, err = db.yml.DiscoverNode(nodeFound.Id(), netdev, hwaddr); err != nil {
+		return nodeFound, false, fmt.Errorf("%s (failed to discover node %s) %w", hwaddr, nodeFound.Id(), err)
 	}
-	err = db.yml.Persist()
-	if err != nil {
-		return nodeFound, fmt.Errorf("%s (failed to persist node configuration) %w", hwaddr, err)
+	err = db.yml.PersistAs(node.AuditActor{Name: hwaddr, Source: node.AuditSourceDiscovery})
+	if errors.Is(err, node.ErrConflict) {
+		// nodes.conf was changed since it was loaded: reload it, so that
+		// the node's next request is discovered against the current
+		// configuration.
+		if _, reloadErr := loadNodeDB(); reloadErr != nil {
+			wwlog.Error("Could not load node DB: %s", reloadErr)
+		}
+		return nodeFound, false, fmt.Errorf("%s (node configuration changed during discovery) %w", hwaddr, err)
+	} else if err != nil {
+		return nodeFound, false, fmt.Errorf("%s (failed to persist node configuration) %w", hwaddr, err)
 	}
-	err = loadNodeDB()
+	_, err = loadNodeDB()
 	if err != nil {
-		return nodeFound, fmt.Errorf("%s (failed to reload configuration) %w", hwaddr, err)
+		return nodeFound, false, fmt.Errorf("%s (failed to reload configuration) %w", hwaddr, err)
+	}
+	if err := discovery.Remove(hwaddr); err != nil && !errors.Is(err, discovery.ErrNotFound) {
+		wwlog.Warn("could not remove %s from the discovery queue: %s", hwaddr, err)
 	}
 	// NOTE: previously all overlays were built here, but that will also
 	// be done automatically when attempting to serve an overlay that
 	// hasn't been built (without blocking the database).
 
 	wwlog.Serv("%s (node %s automatically configured)", hwaddr, nodeFound.Id())
+	publishStatusEvent(StatusEvent{
+		Type:   DiscoveryEventType,
+		Hwaddr: hwaddr,
+		NodeStatus: NodeStatus{
+			NodeName: nodeFound.Id(),
+			Stage:    "DISCOVERED",
+			Lastseen: time.Now().Unix(),
+		},
+	})
 
 	// return the discovered node
-	return db.yml.GetNode(nodeFound.Id())
+	return db.nodes[nodeFound.Id()], false, nil
 }
 
-func Reload() {
-	if err := LoadNodeDB(); err != nil {
-		wwlog.Error("Could not load node DB: %s", err)
+// queueMachine adds a machine which matched no discoverable node to the
+// discovery queue.
+func queueMachine(machine node.Machine, ipaddr string) {
+	pending, added, err := discovery.Record(machine, ipaddr)
+	if err != nil {
+		wwlog.Error("could not add %s to the discovery queue: %s", machine.Hwaddr, err)
+		return
+	}
+	if added {
+		wwlog.Serv("%s (machine pending discovery)", machine.Hwaddr)
+		publishStatusEvent(StatusEvent{
+			Type:   DiscoveryEventType,
+			Hwaddr: machine.Hwaddr,
+			NodeStatus: NodeStatus{
+				Stage:    "PENDING",
+				Ipaddr:   pending.Ipaddr,
+				Lastseen: pending.LastSeen.Unix(),
+			},
+		})
 	}
+}
 
-	if err := LoadNodeStatus(); err != nil {
-		wwlog.Error("Could not prepopulate node status DB: %s", err)
+/*
+Reload loads the changes of the node configuration into the node DB
+and the node status. Overlays of the changed nodes are rebuilt when
+they are next requested, if overlays are built automatically.
+*/
+func Reload() {
+	db.lock.Lock()
+	changes, err := loadNodeDB()
+	db.lock.Unlock()
+	if err != nil {
+		wwlog.Error("Could not load node DB: %s", err)
+		return
 	}
+	updateNodeStatus(changes)
 }
@@ -1,26 +1,32 @@
 package warewulfd
 
 import (
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/warewulf/warewulf/internal/pkg/discovery"
+	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/testenv"
 )
 
 func Test_GetNodeOrSetDiscoverable(t *testing.T) {
 	var tests = map[string]struct {
 		nodesConf string
-		hwaddr    string
+		machine   node.Machine
 		node      string
 		err       bool
+		pending   bool
 	}{
 		"empty": {
 			nodesConf: `
 nodes: {}
 `,
-			hwaddr: "00:00:00:00:00:00",
-			err:    true,
+			machine: node.Machine{Hwaddr: "00:00:00:00:00:00"},
+			err:     true,
+			pending: true,
 		},
 		"configured": {
 			nodesConf: `
@@ -30,8 +36,8 @@ nodes:
       default:
         hwaddr: 00:00:00:00:00:01
 `,
-			hwaddr: "00:00:00:00:00:01",
-			node:   "n1",
+			machine: node.Machine{Hwaddr: "00:00:00:00:00:01"},
+			node:    "n1",
 		},
 		"discoverable": {
 			nodesConf: `
@@ -41,8 +47,8 @@ nodes:
     network devices:
       default: {}
 `,
-			hwaddr: "00:00:00:00:00:01",
-			node:   "n1",
+			machine: node.Machine{Hwaddr: "00:00:00:00:00:01"},
+			node:    "n1",
 		},
 		"discoverable with primary": {
 			nodesConf: `
@@ -53,8 +59,39 @@ nodes:
     network devices:
       default: {}
 `,
-			hwaddr: "00:00:00:00:00:01",
-			node:   "n1",
+			machine: node.Machine{Hwaddr: "00:00:00:00:00:01"},
+			node:    "n1",
+		},
+		"discovery rules": {
+			nodesConf: `
+nodes:
+  n1:
+    discoverable: true
+    network devices:
+      default: {}
+  n2:
+    discoverable: true
+    discover:
+      serial: S2
+    network devices:
+      default: {}
+`,
+			machine: node.Machine{Hwaddr: "00:00:00:00:00:02", Serial: "s2"},
+			node:    "n2",
+		},
+		"no matching rules": {
+			nodesConf: `
+nodes:
+  n2:
+    discoverable: true
+    discover:
+      serial: S2
+    network devices:
+      default: {}
+`,
+			machine: node.Machine{Hwaddr: "00:00:00:00:00:03", Serial: "S3"},
+			err:     true,
+			pending: true,
 		},
 		"discoverable without network": {
 			nodesConf: `
@@ -69,8 +106,8 @@ nodes:
     - default
     discoverable: true
 `,
-			hwaddr: "00:00:00:00:00:01",
-			node:   "n1",
+			machine: node.Machine{Hwaddr: "00:00:00:00:00:01"},
+			node:    "n1",
 		},
 	}
 
@@ -83,14 +120,292 @@ nodes:
 			err := LoadNodeDB()
 			assert.NoError(t, err)
 
-			node, err := GetNodeOrSetDiscoverable(tt.hwaddr)
+			n, err := GetNodeOrSetDiscoverable(tt.machine, "10.0.0.1")
 			if tt.err {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.node, node.Id())
+				assert.Equal(t, tt.node, n.Id())
+			}
+			pending, err := discovery.Get(tt.machine.Hwaddr)
+			if tt.pending {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.machine, pending.Machine)
+				assert.Equal(t, "10.0.0.1", pending.Ipaddr)
+			} else {
+				assert.ErrorIs(t, err, discovery.ErrNotFound)
 			}
 		})
 	}
+}
+
+func Test_GetNodeOrSetDiscoverableRejected(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n1:
+    discoverable: true
+    network devices:
+      default: {}
+`)
+	assert.NoError(t, LoadNodeDB())
+
+	machine := node.Machine{Hwaddr: "00:00:00:00:00:01"}
+	_, _, err := discovery.Record(machine, "")
+	assert.NoError(t, err)
+	assert.NoError(t, discovery.Reject(machine.Hwaddr))
+
+	_, err = GetNodeOrSetDiscoverable(machine, "")
+	assert.ErrorIs(t, err, node.ErrNoUnconfigured)
+
+	assert.NoError(t, discovery.Remove(machine.Hwaddr))
+	n, err := GetNodeOrSetDiscoverable(machine, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "n1", n.Id())
+}
+
+func Test_GetNodeOrSetDiscoverableSwitchPort(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n1:
+    discoverable: true
+    network devices:
+      default: {}
+  n2:
+    discoverable: true
+    discover:
+      switch port: sw1:Ethernet1/2
+    network devices:
+      default: {}
+`)
+	assert.NoError(t, LoadNodeDB())
+
+	// iPXE doesn't report the switch port
+	machine := node.Machine{Hwaddr: "00:00:00:00:00:02"}
+	n, err := GetNodeOrSetDiscoverable(machine, "")
+	assert.ErrorIs(t, err, node.ErrDiscoveryDeferred)
+	assert.Equal(t, "n2", n.Id())
+	_, err = GetNodeByHwaddr(machine.Hwaddr)
+	assert.ErrorIs(t, err, node.ErrNotFound)
+	_, err = discovery.Get(machine.Hwaddr)
+	assert.ErrorIs(t, err, discovery.ErrNotFound)
+
+	// the initramfs does
+	machine.SwitchPort = "sw1:Ethernet1/2"
+	n, err = GetNodeOrSetDiscoverable(machine, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "n2", n.Id())
+	n, err = GetNodeByHwaddr(machine.Hwaddr)
+	assert.NoError(t, err)
+	assert.Equal(t, "n2", n.Id())
+}
+
+func Test_GetNodeOrSetDiscoverableInvalidHwaddr(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n1:
+    discoverable: true
+    network devices:
+      default: {}
+`)
+	assert.NoError(t, LoadNodeDB())
+
+	_, err := GetNodeOrSetDiscoverable(node.Machine{Hwaddr: "not-a-mac"}, "")
+	assert.ErrorIs(t, err, node.ErrNoUnconfigured)
+	pending, err := discovery.List()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	// the discoverable node was not used up
+	n, err := GetNodeOrSetDiscoverable(node.Machine{Hwaddr: "00:00:00:00:00:01"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "n1", n.Id())
+}
+
+func resetNodeDB() {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	db.NodeInfo = nil
+	db.yml = node.NodesYaml{}
+	db.nodes = nil
+	db.hashes = nil
+	db.changed = nil
+}
+
+func Test_loadNodeDB(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	resetNodeDB()
+	defer resetNodeDB()
+	resetStatus()
+	defer resetStatus()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default:
+    comment: default profile
+  other:
+    comment: other profile
+nodes:
+  n1:
+    profiles:
+    - default
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01
+  n2:
+    profiles:
+    - other
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:02
+  n3:
+    profiles:
+    - other`)
 
+	db.lock.Lock()
+	changes, err := loadNodeDB()
+	db.lock.Unlock()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"n1", "n2", "n3"}, changes.added)
+	updateNodeStatus(changes)
+	n2Changed, _ := nodeChanged("n2")
+
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default:
+    comment: default profile
+  other:
+    comment: changed profile
+nodes:
+  n1:
+    profiles:
+    - default
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01
+  n2:
+    profiles:
+    - other
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:12
+  n4: {}`)
+	assert.NoError(t, os.Chtimes(env.GetPath("etc/warewulf/nodes.conf"), time.Now(), time.Now().Add(time.Hour)))
+
+	db.lock.Lock()
+	changes, err = loadNodeDB()
+	db.lock.Unlock()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"n4"}, changes.added)
+	assert.Equal(t, []string{"n2"}, changes.updated)
+	assert.Equal(t, []string{"n3"}, changes.deleted)
+	updateNodeStatus(changes)
+
+	assert.Equal(t, map[string]string{
+		"00:00:00:00:00:01": "n1",
+		"00:00:00:00:00:12": "n2",
+	}, db.NodeInfo)
+	n2, err := GetNodeOrSetDiscoverable(node.Machine{Hwaddr: "00:00:00:00:00:12"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "changed profile", n2.Comment)
+
+	changed, ok := nodeChanged("n2")
+	assert.True(t, ok)
+	assert.True(t, changed.After(n2Changed))
+	n1Changed, _ := nodeChanged("n1")
+	assert.True(t, n1Changed.Before(changed))
+	assert.Equal(t, changed, anyNodeChanged())
+
+	assert.Contains(t, statusDB.Nodes, "n4")
+	assert.NotContains(t, statusDB.Nodes, "n3")
+}
+
+func Test_loadNodeDBSharedHwaddr(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	resetNodeDB()
+	defer resetNodeDB()
+
+	tests := []struct {
+		nodesConf string
+		nodeInfo  map[string]string
+	}{
+		{
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01
+  n2:
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01`,
+			nodeInfo: map[string]string{"00:00:00:00:00:01": "n2"},
+		},
+		{
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01`,
+			nodeInfo: map[string]string{"00:00:00:00:00:01": "n1"},
+		},
+		{
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01
+  n3:
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01`,
+			nodeInfo: map[string]string{"00:00:00:00:00:01": "n3"},
+		},
+		{
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01
+  n3:
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:03`,
+			nodeInfo: map[string]string{"00:00:00:00:00:01": "n1", "00:00:00:00:00:03": "n3"},
+		},
+		{
+			nodesConf: `
+nodes:
+  n1:
+    discoverable: true
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01
+  n3:
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01`,
+			nodeInfo: map[string]string{"00:00:00:00:00:01": "n3"},
+		},
+	}
+
+	for i, tt := range tests {
+		env.WriteFile("etc/warewulf/nodes.conf", tt.nodesConf)
+		assert.NoError(t, os.Chtimes(env.GetPath("etc/warewulf/nodes.conf"), time.Now(), time.Now().Add(time.Duration(i)*time.Hour)))
+		db.lock.Lock()
+		_, err := loadNodeDB()
+		db.lock.Unlock()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.nodeInfo, db.NodeInfo, "step %d", i)
+	}
 }
@@ -62,6 +62,14 @@ func OverlaySend(w http.ResponseWriter, req *http.Request) {
 	}
 
 	if strings.HasSuffix(overlayFile, ".ww") && rinfo.node != "" {
+		// templates may render secrets of the node, such as its token
+		if !node.VerifyToken(rinfo.node, rinfo.token) {
+			message := "incorrect token: node %s"
+			wwlog.Denied(message, rinfo.node)
+			http.Error(w, fmt.Sprintf(message, rinfo.node), http.StatusUnauthorized)
+			return
+		}
+
 		nodeDB, err := node.New()
 		if err != nil {
 			message := "error opening node database: %s"
@@ -132,6 +140,7 @@ type parserInfoRender struct {
 	overlay    string
 	path       string
 	node       string
+	token      string
 	remoteport int
 }
 
@@ -148,6 +157,9 @@ func parseReqRender(req *http.Request) (ret parserInfoRender, err error) {
 	if len(req.URL.Query()["render"]) > 0 {
 		ret.node = req.URL.Query()["render"][0]
 	}
+	if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		ret.token = strings.TrimPrefix(auth, "Bearer ")
+	}
 	if _, remoteport, err := net.SplitHostPort(req.RemoteAddr); err != nil {
 		return ret, fmt.Errorf("could not obtain remote port from HTTP request: %w", err)
 	} else if ret.remoteport, err = strconv.Atoi(remoteport); err != nil {
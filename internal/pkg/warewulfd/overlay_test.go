@@ -8,11 +8,13 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/testenv"
 )
 
 var overlaySendTests = map[string]struct {
 	url    string
+	token  string
 	body   string
 	status int
 }{
@@ -33,14 +35,27 @@ var overlaySendTests = map[string]struct {
 	},
 	"get rendered template": {
 		url:    "/overlay-file/pub/template.ww?render=n1",
+		token:  "n1",
 		body:   "Template: n1",
 		status: 200,
 	},
 	"get rendered template without explicit suffix": {
 		url:    "/overlay-file/pub/template?render=n1",
+		token:  "n1",
 		body:   "Template: n1",
 		status: 200,
 	},
+	"rendering a template requires a token": {
+		url:    "/overlay-file/pub/template.ww?render=n1",
+		body:   "",
+		status: 401,
+	},
+	"rendering a template requires the token of the node": {
+		url:    "/overlay-file/pub/template.ww?render=n1",
+		token:  "n2",
+		body:   "",
+		status: 401,
+	},
 	"explicit suffix required when no node specified": {
 		url:    "/overlay-file/pub/template",
 		body:   "",
@@ -68,6 +83,7 @@ var overlaySendTests = map[string]struct {
 	},
 	"render a template from a subdir": {
 		url:    "/overlay-file/pub/subdir/template.ww?render=n1",
+		token:  "n1",
 		body:   "Template (subdir): n1",
 		status: 200,
 	},
@@ -84,8 +100,15 @@ nodeprofiles:
   default: {}
 nodes:
   n1: {}
+  n2: {}
 `)
 	_ = env.Configure()
+	tokens := make(map[string]string)
+	for _, id := range []string{"n1", "n2"} {
+		token, err := node.Token(id)
+		assert.NoError(t, err)
+		tokens[id] = token
+	}
 	env.WriteFile("var/lib/warewulf/overlays/pub/rootfs/non-template", "Non-template: {{.Id}}")
 	env.WriteFile("var/lib/warewulf/overlays/pub/rootfs/template.ww", "Template: {{.Id}}")
 	env.WriteFile("var/lib/warewulf/overlays/pub/rootfs/subdir/non-template", "Non-template (subdir): {{.Id}}")
@@ -94,6 +117,9 @@ nodes:
 	for description, tt := range overlaySendTests {
 		t.Run(description, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tokens[tt.token])
+			}
 			w := httptest.NewRecorder()
 			OverlaySend(w, req)
 			res := w.Result()
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
 
@@ -15,10 +16,13 @@ type parserInfo struct {
 	remoteport int
 	assetkey   string
 	uuid       string
+	serial     string
+	switchport string
 	stage      string
 	overlay    string
 	efifile    string
 	compress   string
+	token      string
 }
 
 func parseReq(req *http.Request) (parserInfo, error) {
@@ -47,6 +51,10 @@ func parseReq(req *http.Request) (parserInfo, error) {
 	ret.ipaddr = strings.Split(req.RemoteAddr, ":")[0]
 	ret.remoteport, _ = strconv.Atoi(strings.Split(req.RemoteAddr, ":")[1])
 
+	if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		ret.token = strings.TrimPrefix(auth, "Bearer ")
+	}
+
 	if len(req.URL.Query()["assetkey"]) > 0 {
 		ret.assetkey = req.URL.Query()["assetkey"][0]
 	}
@@ -55,6 +63,14 @@ func parseReq(req *http.Request) (parserInfo, error) {
 		ret.uuid = req.URL.Query()["uuid"][0]
 	}
 
+	if len(req.URL.Query()["serial"]) > 0 {
+		ret.serial = req.URL.Query()["serial"][0]
+	}
+
+	if len(req.URL.Query()["switchport"]) > 0 {
+		ret.switchport = req.URL.Query()["switchport"][0]
+	}
+
 	if len(req.URL.Query()["stage"]) > 0 {
 		ret.stage = req.URL.Query()["stage"][0]
 	} else {
@@ -73,6 +89,8 @@ func parseReq(req *http.Request) (parserInfo, error) {
 			ret.stage = "efiboot"
 		} else if stage == "initramfs" {
 			ret.stage = "initramfs"
+		} else if stage == "inventory" {
+			ret.stage = "inventory"
 		}
 	}
 
@@ -101,3 +119,14 @@ func parseReq(req *http.Request) (parserInfo, error) {
 
 	return ret, nil
 }
+
+// machine returns the identity which the requesting machine reported.
+func (rinfo parserInfo) machine() node.Machine {
+	return node.Machine{
+		Hwaddr:     rinfo.hwaddr,
+		UUID:       rinfo.uuid,
+		Serial:     rinfo.serial,
+		AssetTag:   rinfo.assetkey,
+		SwitchPort: rinfo.switchport,
+	}
+}
@@ -33,6 +33,8 @@ type templateVars struct {
 	Hwaddr        string
 	Ipaddr        string
 	Port          string
+	Https         bool
+	TLSPort       string
 	KernelArgs    string
 	KernelVersion string
 	Root          string
@@ -40,6 +42,17 @@ type templateVars struct {
 	NetDevs       map[string]*node.NetDev
 }
 
+// bootStages are served to a machine whose discovery is deferred until
+// the initramfs reports its switch port.
+var bootStages = map[string]bool{
+	"ipxe":      true,
+	"efiboot":   true,
+	"shim":      true,
+	"grub":      true,
+	"kernel":    true,
+	"initramfs": true,
+}
+
 func ProvisionSend(w http.ResponseWriter, req *http.Request) {
 	wwlog.Debug("Requested URL: %s", req.URL.String())
 	conf := warewulfconf.Get()
@@ -62,6 +75,14 @@ func ProvisionSend(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	if rinfo.stage == "system" && conf.Warewulf.SecureSystemOverlay() {
+		if rinfo.remoteport >= 1024 {
+			wwlog.Denied("Non-privileged port for system overlay: %s", req.RemoteAddr)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+
 	status_stages := map[string]string{
 		"efiboot":   "EFI",
 		"ipxe":      "IPXE",
@@ -76,7 +97,17 @@ func ProvisionSend(w http.ResponseWriter, req *http.Request) {
 	// TODO: when module version is upgraded to go1.18, should be 'any' type
 	var tmpl_data *templateVars
 
-	remoteNode, err := GetNodeOrSetDiscoverable(rinfo.hwaddr)
+	remoteNode, err := GetNodeOrSetDiscoverable(rinfo.machine(), rinfo.ipaddr)
+	if errors.Is(err, node.ErrDiscoveryDeferred) {
+		if bootStages[rinfo.stage] {
+			wwlog.Serv("%s (discovery as node %s deferred until the switch port is reported)", rinfo.hwaddr, remoteNode.Id())
+			err = nil
+		} else {
+			wwlog.Denied("%s (no switch port reported for node %s)", rinfo.hwaddr, remoteNode.Id())
+			queueMachine(rinfo.machine(), rinfo.ipaddr)
+			remoteNode, err = node.EmptyNode(), node.ErrNoUnconfigured
+		}
+	}
 	if err != nil && err != node.ErrNoUnconfigured {
 		wwlog.ErrorExc(err, "")
 		w.WriteHeader(http.StatusServiceUnavailable)
@@ -90,6 +121,13 @@ func ProvisionSend(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if (rinfo.stage == "runtime" || len(rinfo.overlay) > 0) && remoteNode.Valid() && !node.VerifyToken(remoteNode.Id(), rinfo.token) {
+		w.WriteHeader(http.StatusUnauthorized)
+		wwlog.Denied("incorrect token: node %s", remoteNode.Id())
+		updateStatus(remoteNode.Id(), status_stage, "BAD_TOKEN", rinfo.ipaddr)
+		return
+	}
+
 	if !remoteNode.Valid() {
 		wwlog.Error("%s (unknown/unconfigured node)", rinfo.hwaddr)
 		if rinfo.stage == "ipxe" {
@@ -121,6 +159,8 @@ func ProvisionSend(w http.ResponseWriter, req *http.Request) {
 			Fqdn:          remoteNode.Id(),
 			Ipaddr:        conf.Ipaddr,
 			Port:          strconv.Itoa(conf.Warewulf.Port),
+			Https:         conf.Warewulf.IPXEHttps(),
+			TLSPort:       strconv.Itoa(conf.Warewulf.TLSPort),
 			Hostname:      remoteNode.Id(),
 			Hwaddr:        rinfo.hwaddr,
 			ImageName:     remoteNode.ImageName,
@@ -209,6 +249,8 @@ func ProvisionSend(w http.ResponseWriter, req *http.Request) {
 				Fqdn:          remoteNode.Id(),
 				Ipaddr:        conf.Ipaddr,
 				Port:          strconv.Itoa(conf.Warewulf.Port),
+				Https:         conf.Warewulf.IPXEHttps(),
+				TLSPort:       strconv.Itoa(conf.Warewulf.TLSPort),
 				Hostname:      remoteNode.Id(),
 				Hwaddr:        rinfo.hwaddr,
 				ImageName:     remoteNode.ImageName,
@@ -11,27 +11,33 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/discovery"
+	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/testenv"
 )
 
 var provisionSendTests = []struct {
 	description string
 	url         string
+	token       string
 	body        string
 	status      int
 	ip          string
 }{
-	{"system overlay", "/overlay-system/00:00:00:ff:ff:ff", "system overlay", 200, "10.10.10.10:9873"},
-	{"runtime overlay", "/overlay-runtime/00:00:00:ff:ff:ff", "runtime overlay", 200, "10.10.10.10:9873"},
-	{"fake overlay", "/overlay-system/00:00:00:ff:ff:ff?overlay=fake", "", 404, "10.10.10.10:9873:9873"},
-	{"specific overlay", "/overlay-system/00:00:00:ff:ff:ff?overlay=o1", "specific overlay", 200, "10.10.10.10:9873"},
-	{"find shim", "/efiboot/shim.efi", "", 200, "10.10.10.10:9873"},
-	{"find shim", "/efiboot/shim.efi", "", 404, "10.10.10.11:9873"},
-	{"find grub", "/efiboot/grub.efi", "", 200, "10.10.10.10:9873"},
-	{"find grub", "/efiboot/grub.efi", "", 404, "10.10.10.11:9873"},
-	{"find initramfs", "/provision/00:00:00:ff:ff:ff?stage=initramfs", "", 200, "10.10.10.10:9873"},
-	{"ipxe test with NetDevs and KernelVersion", "/provision/00:00:00:00:00:ff?stage=ipxe", "1.1.1 ifname=net:00:00:00:00:00:ff ", 200, "10.10.10.12:9873"},
-	{"find grub.cfg", "/efiboot/grub.cfg", "dracut", 200, "10.10.10.11:9873"},
+	{"system overlay", "/overlay-system/00:00:00:ff:ff:ff", "", "system overlay", 200, "10.10.10.10:9873"},
+	{"runtime overlay without token", "/overlay-runtime/00:00:00:ff:ff:ff", "", "", 401, "10.10.10.10:9873"},
+	{"runtime overlay with wrong token", "/overlay-runtime/00:00:00:ff:ff:ff", "wrong", "", 401, "10.10.10.10:9873"},
+	{"runtime overlay", "/overlay-runtime/00:00:00:ff:ff:ff", "n1", "runtime overlay", 200, "10.10.10.10:9873"},
+	{"fake overlay", "/overlay-system/00:00:00:ff:ff:ff?overlay=fake", "n1", "", 404, "10.10.10.10:9873:9873"},
+	{"specific overlay without token", "/overlay-system/00:00:00:ff:ff:ff?overlay=o1", "", "", 401, "10.10.10.10:9873"},
+	{"specific overlay", "/overlay-system/00:00:00:ff:ff:ff?overlay=o1", "n1", "specific overlay", 200, "10.10.10.10:9873"},
+	{"find shim", "/efiboot/shim.efi", "", "", 200, "10.10.10.10:9873"},
+	{"find shim", "/efiboot/shim.efi", "", "", 404, "10.10.10.11:9873"},
+	{"find grub", "/efiboot/grub.efi", "", "", 200, "10.10.10.10:9873"},
+	{"find grub", "/efiboot/grub.efi", "", "", 404, "10.10.10.11:9873"},
+	{"find initramfs", "/provision/00:00:00:ff:ff:ff?stage=initramfs", "", "", 200, "10.10.10.10:9873"},
+	{"ipxe test with NetDevs and KernelVersion", "/provision/00:00:00:00:00:ff?stage=ipxe", "", "1.1.1 ifname=net:00:00:00:00:00:ff ", 200, "10.10.10.12:9873"},
+	{"find grub.cfg", "/efiboot/grub.cfg", "", "dracut", 200, "10.10.10.11:9873"},
 }
 
 func Test_ProvisionSend(t *testing.T) {
@@ -91,11 +97,18 @@ nodes:
 	assert.NoError(t, os.WriteFile(path.Join(conf.Paths.OverlayProvisiondir(), "n1", "__SYSTEM__.img"), []byte("system overlay"), 0600))
 	assert.NoError(t, os.WriteFile(path.Join(conf.Paths.OverlayProvisiondir(), "n1", "__RUNTIME__.img"), []byte("runtime overlay"), 0600))
 	assert.NoError(t, os.WriteFile(path.Join(conf.Paths.OverlayProvisiondir(), "n1", "o1.img"), []byte("specific overlay"), 0600))
+	tokens := map[string]string{"wrong": "wrong"}
+	token, err := node.Token("n1")
+	assert.NoError(t, err)
+	tokens["n1"] = token
 
 	for _, tt := range provisionSendTests {
 		t.Run(tt.description, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
 			req.RemoteAddr = tt.ip
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tokens[tt.token])
+			}
 			w := httptest.NewRecorder()
 			ProvisionSend(w, req)
 			res := w.Result()
@@ -110,3 +123,184 @@ nodes:
 		})
 	}
 }
+
+func Test_ProvisionSendTLS(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	env.WriteFile("etc/warewulf/nodes.conf", `nodes:
+  n1:
+    network devices:
+      default:
+        hwaddr: 00:00:00:ff:ff:ff
+    ipxe template: test`)
+	env.WriteFile("/etc/warewulf/ipxe/test.ipxe", "{{ if .Https }}https://{{.Ipaddr}}:{{.TLSPort}}{{ else }}http://{{.Ipaddr}}:{{.Port}}{{ end }}")
+	assert.NoError(t, LoadNodeDB())
+
+	conf := warewulfconf.Get()
+	conf.Ipaddr = "10.10.10.1"
+
+	tests := map[string]struct {
+		tls       bool
+		ipxeHttps bool
+		body      string
+	}{
+		"http":                   {tls: false, body: "http://10.10.10.1:9873"},
+		"tls without ipxe https": {tls: true, body: "http://10.10.10.1:9873"},
+		"ipxe https without tls": {ipxeHttps: true, body: "http://10.10.10.1:9873"},
+		"https":                  {tls: true, ipxeHttps: true, body: "https://10.10.10.1:9874"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf.Warewulf.TLSP = &tt.tls
+			conf.Warewulf.IPXEHttpsP = &tt.ipxeHttps
+			req := httptest.NewRequest(http.MethodGet, "/provision/00:00:00:ff:ff:ff?stage=ipxe", nil)
+			req.RemoteAddr = "10.10.10.10:9873"
+			w := httptest.NewRecorder()
+			ProvisionSend(w, req)
+			res := w.Result()
+			defer res.Body.Close()
+
+			data, err := io.ReadAll(res.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Equal(t, tt.body, string(data))
+		})
+	}
+}
+
+func Test_ProvisionSendToken(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	env.WriteFile("etc/warewulf/nodes.conf", `nodes:
+  n1:
+    network devices:
+      default:
+        hwaddr: 00:00:00:ff:ff:ff`)
+	assert.NoError(t, LoadNodeDB())
+
+	conf := warewulfconf.Get()
+	secureFalse := false
+	conf.Warewulf.SecureP = &secureFalse
+	autobuildFalse := false
+	conf.Warewulf.AutobuildOverlaysP = &autobuildFalse
+	assert.NoError(t, os.MkdirAll(path.Join(conf.Paths.OverlayProvisiondir(), "n1"), 0700))
+	assert.NoError(t, os.WriteFile(path.Join(conf.Paths.OverlayProvisiondir(), "n1", "__RUNTIME__.img"), []byte("runtime overlay"), 0600))
+
+	token, err := node.Token("n1")
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		auth   string
+		status int
+	}{
+		"no token":    {auth: "", status: http.StatusUnauthorized},
+		"wrong token": {auth: "Bearer wrong", status: http.StatusUnauthorized},
+		"token":       {auth: "Bearer " + token, status: http.StatusOK},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/provision/00:00:00:ff:ff:ff?stage=runtime", nil)
+			req.RemoteAddr = "10.10.10.10:987"
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+			ProvisionSend(w, req)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+}
+
+func Test_ProvisionSendSecureSystemOverlay(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	env.WriteFile("etc/warewulf/nodes.conf", `nodes:
+  n1:
+    network devices:
+      default:
+        hwaddr: 00:00:00:ff:ff:ff`)
+	assert.NoError(t, LoadNodeDB())
+
+	conf := warewulfconf.Get()
+	autobuildFalse := false
+	conf.Warewulf.AutobuildOverlaysP = &autobuildFalse
+	env.WriteFile("srv/warewulf/overlays/n1/__SYSTEM__.img", "system overlay")
+
+	tests := map[string]struct {
+		secure     bool
+		remoteAddr string
+		status     int
+	}{
+		"default":              {secure: false, remoteAddr: "10.10.10.10:2345", status: http.StatusOK},
+		"secure, privileged":   {secure: true, remoteAddr: "10.10.10.10:987", status: http.StatusOK},
+		"secure, unprivileged": {secure: true, remoteAddr: "10.10.10.10:2345", status: http.StatusUnauthorized},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf.Warewulf.SecureSystemP = &tt.secure
+			req := httptest.NewRequest(http.MethodGet, "/provision/00:00:00:ff:ff:ff?stage=system", nil)
+			req.RemoteAddr = tt.remoteAddr
+			w := httptest.NewRecorder()
+			ProvisionSend(w, req)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+}
+
+func Test_ProvisionSendDeferredDiscovery(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	env.WriteFile("etc/warewulf/nodes.conf", `nodes:
+  n1:
+    discoverable: true
+    ipxe template: test
+    discover:
+      switch port: sw1:Ethernet1/1
+    network devices:
+      default: {}`)
+	env.WriteFile("etc/warewulf/ipxe/test.ipxe", "{{.Id}}")
+	assert.NoError(t, LoadNodeDB())
+
+	conf := warewulfconf.Get()
+	autobuildFalse := false
+	conf.Warewulf.AutobuildOverlaysP = &autobuildFalse
+
+	send := func(url string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.RemoteAddr = "10.10.10.10:987"
+		w := httptest.NewRecorder()
+		ProvisionSend(w, req)
+		return w.Result()
+	}
+
+	// iPXE is served the node's boot script without discovering it
+	res := send("/provision/00:00:00:00:00:01?stage=ipxe")
+	data, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "n1", string(data))
+	_, err = GetNodeByHwaddr("00:00:00:00:00:01")
+	assert.ErrorIs(t, err, node.ErrNotFound)
+
+	// the system overlay requires the switch port
+	res = send("/provision/00:00:00:00:00:01?stage=system")
+	assert.NotEqual(t, http.StatusOK, res.StatusCode)
+	_, err = GetNodeByHwaddr("00:00:00:00:00:01")
+	assert.ErrorIs(t, err, node.ErrNotFound)
+	_, err = discovery.Get("00:00:00:00:00:01")
+	assert.NoError(t, err)
+
+	res = send("/provision/00:00:00:00:00:01?stage=system&switchport=sw1:Ethernet1/1")
+	n, err := GetNodeByHwaddr("00:00:00:00:00:01")
+	assert.NoError(t, err)
+	assert.Equal(t, "n1", n.Id())
+	assert.NoError(t, res.Body.Close())
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"os"
@@ -10,6 +12,7 @@ import (
 	"syscall"
 
 	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd/api"
@@ -46,7 +49,10 @@ func defaultHandler() *slashFix {
 	wwHandler.HandleFunc("/overlay-system/", warewulfd.ProvisionSend)
 	wwHandler.HandleFunc("/overlay-runtime/", warewulfd.ProvisionSend)
 	wwHandler.HandleFunc("/overlay-file/", warewulfd.OverlaySend)
+	wwHandler.HandleFunc("/inventory/", warewulfd.InventoryReceive)
 	wwHandler.HandleFunc("/status", warewulfd.StatusSend)
+	wwHandler.HandleFunc("/status/events", warewulfd.StatusEventsSend)
+	wwHandler.HandleFunc("/status/{node}/history", warewulfd.StatusHistorySend)
 	return &slashFix{&wwHandler}
 }
 
@@ -61,8 +67,36 @@ func RunServer() error {
 		}
 	}()
 
+	// The node status is written in the background, so write it once
+	// more before exiting.
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-stop
+		wwlog.Info("Received %s, exiting...", sig)
+		warewulfd.FlushNodeStatus()
+		os.Exit(0)
+	}()
+
 	warewulfd.Reload()
 
+	// Changes not made through an authenticated API request are made
+	// by the daemon itself.
+	node.SetAuditActor(node.AuditActor{Name: "warewulfd", Source: node.AuditSourceAPI})
+
+	if store, err := node.OpenStore(); err != nil {
+		wwlog.Error("Could not open node database: %s", err)
+	} else if changes, err := store.Watch(context.Background()); err != nil {
+		wwlog.Error("Could not watch node database: %s", err)
+	} else {
+		go func() {
+			for range changes {
+				wwlog.Info("Node database changed, reloading...")
+				warewulfd.Reload()
+			}
+		}()
+	}
+
 	conf := warewulfconf.Get()
 	daemonPort := conf.Warewulf.Port
 
@@ -72,6 +106,7 @@ func RunServer() error {
 			wwlog.Warn("%w\n", err)
 		}
 	}
+	auth.SetTokenFile(conf.Paths.APITokensConf())
 
 	apiHandler := api.Handler(auth, conf.API.AllowedIPNets())
 	defaultHandler := defaultHandler()
@@ -82,6 +117,27 @@ func RunServer() error {
 			defaultHandler.ServeHTTP(w, r)
 		}
 	})
+
+	if conf.Warewulf.TLS() {
+		// The plain http listener is kept for bootloaders without https
+		// support.
+		cert, err := tls.LoadX509KeyPair(conf.Warewulf.TLSCert, conf.Warewulf.TLSKey)
+		if err != nil {
+			return fmt.Errorf("could not load TLS certificate: %w", err)
+		}
+		tlsServer := &http.Server{
+			Addr:      ":" + strconv.Itoa(conf.Warewulf.TLSPort),
+			Handler:   dispatchHandler,
+			TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12},
+		}
+		go func() {
+			wwlog.Info("Listening for https on port %d", conf.Warewulf.TLSPort)
+			if err := tlsServer.ListenAndServeTLS("", ""); err != nil {
+				wwlog.Error("could not start https listening service: %s", err)
+			}
+		}()
+	}
+
 	if err := http.ListenAndServe(":"+strconv.Itoa(daemonPort), dispatchHandler); err != nil {
 		return fmt.Errorf("could not start listening service: %w", err)
 	}
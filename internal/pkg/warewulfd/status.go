@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"path"
 	"sync"
 	"time"
 
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/inventory"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
@@ -23,13 +27,47 @@ type NodeStatus struct {
 	Lastseen int64  `json:"last seen"`
 }
 
+// NodeStatusHistory is the list of stage transitions recorded for a
+// single node, oldest first.
+type NodeStatusHistory struct {
+	NodeName string        `json:"node name"`
+	History  []*NodeStatus `json:"history"`
+}
+
+// statusStore is the on-disk representation of the status database.
+type statusStore struct {
+	Nodes   map[string]*NodeStatus   `json:"nodes"`
+	History map[string][]*NodeStatus `json:"history"`
+}
+
+// statusHistoryLength is the maximum number of transitions kept per
+// node.
+const statusHistoryLength = 64
+
+// statusPersistInterval limits how often repeated check-ins that do not
+// change a node's stage are written to disk.
+var statusPersistInterval = 30 * time.Second
+
+// statusPersistDelay is how long the background writer waits before
+// writing the status file, so that the transitions of many nodes booting
+// at once are written together.
+var statusPersistDelay = time.Second
+
 var (
-	statusDB allStatus
-	dbLock   = sync.RWMutex{}
+	statusDB        allStatus
+	statusHistory   map[string][]*NodeStatus
+	statusLoaded    bool
+	statusPersisted time.Time
+	dbLock          = sync.RWMutex{}
+
+	statusWriterOnce sync.Once
+	statusPersistCh  = make(chan struct{}, 1)
+	statusFileLock   sync.Mutex
 )
 
 func init() {
 	statusDB.Nodes = make(map[string]*NodeStatus)
+	statusHistory = make(map[string][]*NodeStatus)
 }
 
 func LoadNodeStatus() error {
@@ -37,6 +75,14 @@ func LoadNodeStatus() error {
 	defer dbLock.Unlock()
 	var newDB allStatus
 	newDB.Nodes = make(map[string]*NodeStatus)
+	newHistory := make(map[string][]*NodeStatus)
+
+	if !statusLoaded {
+		if err := readStatusFile(); err != nil {
+			wwlog.Warn("Could not read persisted node status: %s", err)
+		}
+		statusLoaded = true
+	}
 
 	DB, err := node.New()
 	if err != nil {
@@ -55,9 +101,14 @@ func LoadNodeStatus() error {
 		} else {
 			newDB.Nodes[n.Id()] = statusDB.Nodes[n.Id()]
 		}
+		if history, ok := statusHistory[n.Id()]; ok {
+			newHistory[n.Id()] = history
+		}
 	}
 
 	statusDB = newDB
+	statusHistory = newHistory
+	persistStatus()
 	return nil
 }
 
@@ -76,6 +127,161 @@ func updateStatus(nodeID, stage, sent, ipaddr string) {
 		Ipaddr:   ipaddr,
 	}
 	statusDB.Nodes[nodeID] = &n
+	publishStatusEvent(StatusEvent{Type: StatusEventType, NodeStatus: n})
+
+	history := statusHistory[nodeID]
+	if len(history) == 0 || history[len(history)-1].Stage != stage || history[len(history)-1].Sent != sent {
+		entry := n
+		history = append(history, &entry)
+		if len(history) > statusHistoryLength {
+			history = history[len(history)-statusHistoryLength:]
+		}
+		statusHistory[nodeID] = history
+		persistStatus()
+	} else if time.Since(statusPersisted) >= statusPersistInterval {
+		persistStatus()
+	}
+}
+
+// readStatusFile replaces the in-memory status database with the
+// contents of the status file, if it exists. Must be called with dbLock
+// held.
+func readStatusFile() error {
+	statusFile := warewulfconf.Get().Paths.NodeStatusFile()
+	data, err := os.ReadFile(statusFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var store statusStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return fmt.Errorf("could not parse %s: %w", statusFile, err)
+	}
+	if store.Nodes != nil {
+		statusDB.Nodes = store.Nodes
+	}
+	if store.History != nil {
+		statusHistory = store.History
+	}
+	wwlog.Verbose("Loaded node status from %s", statusFile)
+	return nil
+}
+
+// persistStatus schedules a write of the status database to the status
+// file. Writes are made by a background writer, so that a stage
+// transition does not wait for the status of all nodes to be written.
+// Must be called with dbLock held.
+func persistStatus() {
+	statusWriterOnce.Do(func() { go statusWriter() })
+	statusPersisted = time.Now()
+	select {
+	case statusPersistCh <- struct{}{}:
+	default:
+		// a write is already pending
+	}
+}
+
+// updateNodeStatus adds the status of added nodes and removes the status
+// of deleted nodes. The status of all nodes is loaded if it wasn't yet.
+func updateNodeStatus(changes nodeDBChanges) {
+	dbLock.RLock()
+	loaded := statusLoaded
+	dbLock.RUnlock()
+	if !loaded {
+		if err := LoadNodeStatus(); err != nil {
+			wwlog.Error("Could not prepopulate node status DB: %s", err)
+		}
+		return
+	}
+	if len(changes.added) == 0 && len(changes.deleted) == 0 {
+		return
+	}
+	dbLock.Lock()
+	defer dbLock.Unlock()
+	for _, id := range changes.added {
+		if _, ok := statusDB.Nodes[id]; !ok {
+			statusDB.Nodes[id] = &NodeStatus{NodeName: id}
+		}
+	}
+	for _, id := range changes.deleted {
+		delete(statusDB.Nodes, id)
+		delete(statusHistory, id)
+		if err := inventory.Delete(id); err != nil {
+			wwlog.Warn("Could not delete the inventory of %s: %s", id, err)
+		}
+	}
+	persistStatus()
+}
+
+// statusWriter writes the status database after each request from
+// persistStatus. Requests made while a write is pending are merged.
+func statusWriter() {
+	for range statusPersistCh {
+		time.Sleep(statusPersistDelay)
+		FlushNodeStatus()
+	}
+}
+
+// FlushNodeStatus writes the status database to the status file. Errors
+// are logged rather than returned, as a failure to persist status must
+// not interrupt provisioning.
+func FlushNodeStatus() {
+	statusFileLock.Lock()
+	defer statusFileLock.Unlock()
+
+	// Nodes and history entries are replaced rather than modified, so
+	// a copy of the maps can be marshaled without holding dbLock.
+	dbLock.RLock()
+	store := statusStore{
+		Nodes:   make(map[string]*NodeStatus, len(statusDB.Nodes)),
+		History: make(map[string][]*NodeStatus, len(statusHistory)),
+	}
+	for id, status := range statusDB.Nodes {
+		store.Nodes[id] = status
+	}
+	for id, history := range statusHistory {
+		store.History[id] = history
+	}
+	dbLock.RUnlock()
+
+	statusFile := warewulfconf.Get().Paths.NodeStatusFile()
+	data, err := json.Marshal(store)
+	if err != nil {
+		wwlog.Warn("Could not marshal node status: %s", err)
+		return
+	}
+	if err := os.MkdirAll(path.Dir(statusFile), 0o755); err != nil {
+		wwlog.Warn("Could not create status directory: %s", err)
+		return
+	}
+	tmpFile := statusFile + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o644); err != nil {
+		wwlog.Warn("Could not write node status: %s", err)
+		return
+	}
+	if err := os.Rename(tmpFile, statusFile); err != nil {
+		wwlog.Warn("Could not write node status: %s", err)
+		return
+	}
+	wwlog.Debug("persisted node status: %s", statusFile)
+}
+
+// GetNodeState returns the stage and last check-in of the node id, as
+// used by node selectors.
+func GetNodeState(id string) (node.NodeState, bool) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+	status, ok := statusDB.Nodes[id]
+	if !ok {
+		return node.NodeState{}, false
+	}
+	state := node.NodeState{Stage: status.Stage}
+	if status.Lastseen > 0 {
+		state.LastSeen = time.Unix(status.Lastseen, 0)
+	}
+	return state, true
 }
 
 func statusJSON() ([]byte, error) {
@@ -105,3 +311,35 @@ func StatusSend(w http.ResponseWriter, req *http.Request) {
 		wwlog.Warn("Could not send status JSON: %s", err)
 	}
 }
+
+// StatusHistorySend sends the recorded stage transitions for the node
+// named in the request path.
+func StatusHistorySend(w http.ResponseWriter, req *http.Request) {
+	nodeID := req.PathValue("node")
+
+	dbLock.RLock()
+	_, ok := statusDB.Nodes[nodeID]
+	history := NodeStatusHistory{
+		NodeName: nodeID,
+		History:  append([]*NodeStatus{}, statusHistory[nodeID]...),
+	}
+	dbLock.RUnlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	wwlog.Debug("Request for node status history: %s", nodeID)
+
+	ret, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		wwlog.Warn("Could not marshal status history for %s: %s", nodeID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(ret); err != nil {
+		wwlog.Warn("Could not send status history JSON: %s", err)
+	}
+}
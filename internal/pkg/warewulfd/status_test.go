@@ -0,0 +1,132 @@
+package warewulfd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+)
+
+func resetStatus() {
+	dbLock.Lock()
+	defer dbLock.Unlock()
+	statusDB.Nodes = make(map[string]*NodeStatus)
+	statusHistory = make(map[string][]*NodeStatus)
+	statusLoaded = false
+}
+
+func Test_updateStatusHistory(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	resetStatus()
+	defer resetStatus()
+
+	assert.NoError(t, LoadNodeStatus())
+	updateStatus("node1", "IPXE", "default.ipxe", "10.0.0.1")
+	updateStatus("node1", "KERNEL", "vmlinuz", "10.0.0.1")
+	updateStatus("node1", "RUNTIME_OVERLAY", "__RUNTIME__.img.gz", "10.0.0.1")
+	updateStatus("node1", "RUNTIME_OVERLAY", "__RUNTIME__.img.gz", "10.0.0.1")
+
+	history := statusHistory["node1"]
+	assert.Len(t, history, 3)
+	assert.Equal(t, "IPXE", history[0].Stage)
+	assert.Equal(t, "KERNEL", history[1].Stage)
+	assert.Equal(t, "RUNTIME_OVERLAY", history[2].Stage)
+	assert.Equal(t, "RUNTIME_OVERLAY", statusDB.Nodes["node1"].Stage)
+
+	for i := 0; i < statusHistoryLength+10; i++ {
+		updateStatus("node1", "KERNEL", string(rune('a'+i%26))+"vmlinuz", "10.0.0.1")
+	}
+	assert.Len(t, statusHistory["node1"], statusHistoryLength)
+}
+
+func Test_persistStatus(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	resetStatus()
+	defer resetStatus()
+
+	assert.NoError(t, LoadNodeStatus())
+	updateStatus("node1", "IPXE", "default.ipxe", "10.0.0.1")
+	updateStatus("node1", "KERNEL", "vmlinuz", "10.0.0.1")
+	FlushNodeStatus()
+	assert.True(t, util.IsFile(warewulfconf.Get().Paths.NodeStatusFile()))
+
+	resetStatus()
+	assert.NoError(t, LoadNodeStatus())
+	assert.Equal(t, "KERNEL", statusDB.Nodes["node1"].Stage)
+	assert.Len(t, statusHistory["node1"], 2)
+
+	env.WriteFile("etc/warewulf/nodes.conf", `nodes:
+  node2: {}
+`)
+	assert.NoError(t, LoadNodeStatus())
+	assert.NotContains(t, statusDB.Nodes, "node1")
+	assert.NotContains(t, statusHistory, "node1")
+	assert.Contains(t, statusDB.Nodes, "node2")
+}
+
+func Test_persistStatusBackground(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	resetStatus()
+	defer resetStatus()
+
+	assert.NoError(t, LoadNodeStatus())
+	updateStatus("node1", "IPXE", "default.ipxe", "10.0.0.1")
+	assert.Eventually(t, func() bool {
+		data, err := os.ReadFile(warewulfconf.Get().Paths.NodeStatusFile())
+		return err == nil && strings.Contains(string(data), "IPXE")
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func Test_StatusHistorySend(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	resetStatus()
+	defer resetStatus()
+
+	assert.NoError(t, LoadNodeStatus())
+	updateStatus("node1", "IPXE", "default.ipxe", "10.0.0.1")
+	updateStatus("node1", "KERNEL", "vmlinuz", "10.0.0.1")
+
+	t.Run("known node", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/status/node1/history", nil)
+		req.SetPathValue("node", "node1")
+		w := httptest.NewRecorder()
+		StatusHistorySend(w, req)
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		data, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		var history NodeStatusHistory
+		assert.NoError(t, json.Unmarshal(data, &history))
+		assert.Equal(t, "node1", history.NodeName)
+		if assert.Len(t, history.History, 2) {
+			assert.Equal(t, "IPXE", history.History[0].Stage)
+			assert.Equal(t, "KERNEL", history.History[1].Stage)
+		}
+	})
+
+	t.Run("unknown node", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/status/node9/history", nil)
+		req.SetPathValue("node", "node9")
+		w := httptest.NewRecorder()
+		StatusHistorySend(w, req)
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
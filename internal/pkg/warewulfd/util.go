@@ -6,7 +6,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/warewulf/warewulf/internal/pkg/config"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
 	"github.com/warewulf/warewulf/internal/pkg/util"
@@ -49,7 +48,18 @@ func getOverlayFile(n node.Node, context string, stage_overlays []string, autobu
 	build := !util.IsFile(stage_file)
 	wwlog.Verbose("stage file: %s", stage_file)
 	if !build && autobuild {
-		build = util.PathIsNewer(stage_file, config.Get().Paths.NodesConf())
+		if changed, ok := nodeChanged(n.Id()); ok {
+			if info, err := os.Stat(stage_file); err == nil {
+				build = info.ModTime().Before(changed)
+				// overlays which render all nodes also change with
+				// the other nodes
+				if !build && info.ModTime().Before(anyNodeChanged()) {
+					build = usesAllNodes(n, context, stage_overlays)
+				}
+			}
+		} else if store, err := node.OpenStore(); err == nil {
+			build = util.PathIsNewer(stage_file, store.File())
+		}
 
 		for _, overlayname := range stage_overlays {
 			overlayDir := overlay.GetOverlay(overlayname).Rootfs()
@@ -85,6 +95,23 @@ func getOverlayFile(n node.Node, context string, stage_overlays []string, autobu
 	return
 }
 
+// usesAllNodes reports whether any of the overlays of an overlay image
+// renders all nodes.
+func usesAllNodes(n node.Node, context string, stage_overlays []string) bool {
+	overlays := stage_overlays
+	if context == "system" {
+		overlays = n.SystemOverlay
+	} else if context == "runtime" {
+		overlays = n.RuntimeOverlay
+	}
+	for _, overlayname := range overlays {
+		if overlay.GetOverlay(overlayname).UsesAllNodes() {
+			return true
+		}
+	}
+	return false
+}
+
 var arpFile string
 
 func init() {
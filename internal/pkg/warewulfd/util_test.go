@@ -102,3 +102,18 @@ nodes:
 		})
 	}
 }
+
+func Test_usesAllNodes(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/var/lib/warewulf/overlays/hosts/rootfs/etc/hosts.ww", "{{ range $node := $.AllNodes }}{{ $node.Id }}{{ end }}")
+	env.WriteFile("/var/lib/warewulf/overlays/plain/rootfs/etc/hostname.ww", "{{ .Id }}")
+
+	n := node.NewNode("n1")
+	n.SystemOverlay = []string{"plain", "hosts"}
+	n.RuntimeOverlay = []string{"plain"}
+	assert.True(t, usesAllNodes(n, "system", nil))
+	assert.False(t, usesAllNodes(n, "runtime", nil))
+	assert.True(t, usesAllNodes(n, "", []string{"hosts"}))
+	assert.False(t, usesAllNodes(n, "", []string{"plain"}))
+}
@@ -150,7 +150,7 @@ option PXE.mtftp-delay code 5 = unsigned integer 8;
 
 option architecture-type   code 93  = unsigned integer 16;
 if exists user-class and option user-class = "iPXE" {
-    filename "http://192.168.0.1:9873/ipxe/${mac:hexhyp}?assetkey=${asset}&uuid=${uuid}";
+    filename "http://192.168.0.1:9873/ipxe/${mac:hexhyp}?assetkey=${asset}&uuid=${uuid}&serial=${serial}";
 } else {
     if option architecture-type = 00:00 {
         filename "/warewulf/undionly.kpxe";
@@ -197,7 +197,7 @@ option PXE.mtftp-delay code 5 = unsigned integer 8;
 
 option architecture-type   code 93  = unsigned integer 16;
 if exists user-class and option user-class = "iPXE" {
-    filename "http://192.168.0.1:9873/ipxe/${mac:hexhyp}?assetkey=${asset}&uuid=${uuid}";
+    filename "http://192.168.0.1:9873/ipxe/${mac:hexhyp}?assetkey=${asset}&uuid=${uuid}&serial=${serial}";
 } else {
     if option architecture-type = 00:00 {
         filename "/warewulf/undionly.kpxe";
@@ -262,7 +262,7 @@ dhcp-boot=tag:efi-http,"http://192.168.0.1:9873/efiboot/shim.efi"
 dhcp-boot=tag:x86PC,"/warewulf/ipxe-snponly-x86_64.efi"
 dhcp-boot=tag:aarch64,"/warewulf/arm64-efi/snponly.efi"
 # iPXE binary will get the following configuration file
-dhcp-boot=tag:iPXE,"http://192.168.0.1:9873/ipxe/${mac:hexhyp}?assetkey=${asset}&uuid=${uuid}"
+dhcp-boot=tag:iPXE,"http://192.168.0.1:9873/ipxe/${mac:hexhyp}?assetkey=${asset}&uuid=${uuid}&serial=${serial}"
 dhcp-no-override
 # define the the range
 dhcp-range=192.168.0.100,192.168.0.199,255.255.255.0,6h
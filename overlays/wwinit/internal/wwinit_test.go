@@ -17,6 +17,7 @@ func Test_wwinitOverlay(t *testing.T) {
 	env.ImportFile("etc/warewulf/nodes.conf", "nodes.conf")
 	env.ImportFile("var/lib/warewulf/overlays/wwinit/rootfs/etc/warewulf/warewulf.conf.ww", "../rootfs/etc/warewulf/warewulf.conf.ww")
 	env.ImportFile("var/lib/warewulf/overlays/wwinit/rootfs/warewulf/config.ww", "../rootfs/warewulf/config.ww")
+	env.ImportFile("var/lib/warewulf/overlays/wwinit/rootfs/etc/warewulf/tls-ca.pem.ww", "../rootfs/etc/warewulf/tls-ca.pem.ww")
 
 	tests := []struct {
 		name string
@@ -33,6 +34,11 @@ func Test_wwinitOverlay(t *testing.T) {
 			args: []string{"--render", "node1", "wwinit", "warewulf/config.ww"},
 			log:  wwinit_config,
 		},
+		{
+			name: "wwinit:tls-ca.pem.ww",
+			args: []string{"--render", "node1", "wwinit", "etc/warewulf/tls-ca.pem.ww"},
+			log:  wwinit_tls_ca,
+		},
 	}
 
 	for _, tt := range tests {
@@ -95,3 +101,9 @@ WWIPMI_USER="user"
 WWIPMI_PASSWORD="password"
 WWIPMI_WRITE="true"
 `
+
+const wwinit_tls_ca string = `backupFile: true
+writeFile: false
+Filename: etc/warewulf/tls-ca.pem
+
+`